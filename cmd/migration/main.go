@@ -1,9 +1,12 @@
 package main
 
 import (
-	"github.com/joho/godotenv"
+	"log"
 	"server/internal/db/postgres_db"
 	"server/internal/models/orm"
+	"server/internal/repository/postgres"
+
+	"github.com/joho/godotenv"
 )
 
 func main() {
@@ -15,4 +18,7 @@ func main() {
 	postgres_db.GetPostgresClient().AutoMigrate(&orm.Room{})
 	postgres_db.GetPostgresClient().AutoMigrate(&orm.RoomUser{})
 	postgres_db.GetPostgresClient().AutoMigrate(&orm.AuthenticateMessage{})
+	if err := postgres.MigrateMessageArchive(postgres_db.GetPostgresClient()); err != nil {
+		log.Fatal("Failed to migrate message archive: ", err)
+	}
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"server/internal/broadcast"
 	"server/internal/db/postgres_db"
 	"server/internal/handler/chatting"
 	"server/internal/handler/friends"
@@ -14,7 +16,9 @@ import (
 	redisRepo "server/internal/repository/redis"
 	"server/internal/service"
 	"server/pkg/authenticator"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -119,18 +123,47 @@ func main() {
 	userRepo := postgres.NewPostgresUserRepository(postgresDB)
 	friendRepo := postgres.NewPostgresFriendRepository(postgresDB)
 	roomRepo := postgres.NewPostgresRoomRepository(postgresDB)
-	messageRepo := redisRepo.NewRedisMessageRepository(redisClient)
+	messageArchiveRepo := postgres.NewPostgresMessageArchiveRepository(postgresDB)
+	messageRepo := redisRepo.NewTieredMessageRepository(redisClient, messageArchiveRepo)
+	messageDedupRepo := redisRepo.NewRedisMessageDedupRepository(redisClient)
+	mentionRepo := redisRepo.NewRedisMentionRepository(redisClient)
+	roomSummaryRepo := redisRepo.NewRedisRoomSummaryRepository(redisClient)
+	presenceRepo := redisRepo.NewRedisPresenceRepository(redisClient)
+	typingRepo := redisRepo.NewRedisTypingRepository(redisClient)
+	rateLimitRepo := redisRepo.NewRedisRateLimitRepository(redisClient)
+	messageSearchRepo := postgres.NewPostgresMessageSearchRepository(postgresDB)
+
+	// 스트림에서 잘려 나가기 전에 메시지를 Postgres에 보관
+	go redisRepo.NewMessageArchiver(redisClient, messageArchiveRepo, getArchiveInterval()).Run(context.Background())
 
 	userService := service.NewUserService(userRepo)
 	authService := service.NewAuthService(nil)
 	friendService := service.NewFriendService(friendRepo, userRepo)
-	roomService := service.NewRoomService(roomRepo)
-	chatService := service.NewChatService(messageRepo)
+	// 노드 사이의 채팅 이벤트와 멤버 제거 알림을 전달하는 버스
+	bus := broadcast.NewRedisBus(redisClient)
+	roomService := service.NewRoomService(roomRepo, roomSummaryRepo, bus)
+	chatConfig := getChatConfig()
+	chatService := service.NewChatService(service.ChatDeps{
+		MessageRepo:  messageRepo,
+		DedupRepo:    messageDedupRepo,
+		MentionRepo:  mentionRepo,
+		SummaryRepo:  roomSummaryRepo,
+		PresenceRepo: presenceRepo,
+		TypingRepo:   typingRepo,
+		LimitRepo:    rateLimitRepo,
+		RoomRepo:     roomRepo,
+		FriendRepo:   friendRepo,
+		Bus:          bus,
+	}, chatConfig)
+	presenceService := service.NewPresenceService(presenceRepo, friendRepo, roomRepo, chatConfig.Clock)
+	messageSearchService := service.NewMessageSearchService(messageSearchRepo, messageRepo, roomRepo, bus, chatConfig.Clock)
 
 	userHandler := user.NewHandler(userService, authService)
 	friendHandler := friends.NewHandler(friendService)
 	roomHandler := room.NewHandler(roomService)
 	chatHandler := chatting.NewChatHandler(chatService)
+	searchHandler := chatting.NewSearchHandler(messageSearchService)
+	presenceHandler := chatting.NewPresenceHandler(presenceService)
 
 	r := mux.NewRouter()
 
@@ -147,7 +180,6 @@ func main() {
 	r.HandleFunc("/authenticate", userHandler.RequestAuthNumber).Methods("POST", "OPTIONS")
 	r.HandleFunc("/checkauth", userHandler.CheckAuthNumber).Methods("POST", "OPTIONS")
 	r.HandleFunc("/chat", chatHandler.HandleWebSocket).Methods("GET", "OPTIONS")
-	r.HandleFunc("/messages", chatHandler.GetMessages).Methods("GET", "OPTIONS")
 
 	authorizedRouter := r.PathPrefix("/auth").Subrouter()
 	authorizedRouter.Use(authenticator.JWTMiddleware)
@@ -169,6 +201,20 @@ func main() {
 	authorizedRouter.HandleFunc("/rooms/{roomId}/users", roomHandler.AddUser).Methods("POST", "OPTIONS")
 	authorizedRouter.HandleFunc("/rooms/{roomId}/users/{userId}", roomHandler.RemoveUser).Methods("DELETE", "OPTIONS")
 
+	// 채팅 메시지 관련 RESTful API 엔드포인트
+	authorizedRouter.HandleFunc("/messages", chatHandler.GetMessages).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/search", searchHandler.SearchMessages).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/{messageId}", chatHandler.EditMessage).Methods("PATCH", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/{messageId}", chatHandler.DeleteMessage).Methods("DELETE")
+	authorizedRouter.HandleFunc("/messages/{messageId}/revisions", chatHandler.GetMessageRevisions).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/{messageId}/thread", chatHandler.GetThread).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/{messageId}/thread/participants", chatHandler.JoinThread).Methods("PUT", "OPTIONS")
+	authorizedRouter.HandleFunc("/messages/{messageId}/thread/participants", chatHandler.LeaveThread).Methods("DELETE")
+	authorizedRouter.HandleFunc("/mentions", chatHandler.GetMentions).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/mentions/read", chatHandler.ReadMentions).Methods("POST", "OPTIONS")
+	authorizedRouter.HandleFunc("/presence", presenceHandler.GetPresence).Methods("GET", "OPTIONS")
+	authorizedRouter.HandleFunc("/presence/settings", presenceHandler.UpdatePresenceSettings).Methods("PUT", "OPTIONS")
+
 	port := ":18000"
 	log.Println("Server is successfully running on port " + port)
 	log.Fatal(http.ListenAndServe(port, r))
@@ -189,3 +235,111 @@ func getRedisClient() *redis.Client {
 		DB:       redisDB,
 	})
 }
+
+// 환경 변수로 WebSocket 하트비트, 타임아웃과 메시지 전송 설정을 덮어씁니다 (예: CHAT_PING_INTERVAL=30s)
+func getChatConfig() service.ChatConfig {
+	config := service.DefaultChatConfig()
+
+	// min은 허용하는 최솟값입니다. 주기로 쓰이는 값이 0 이하이면 ticker가 패닉을 일으키므로 양수만 받습니다.
+	// 수정, 삭제 가능 기간처럼 0이 제한 없음을 뜻하는 값은 음수만 거부합니다.
+	durations := map[string]struct {
+		target *time.Duration
+		min    time.Duration
+	}{
+		"CHAT_PING_INTERVAL":            {&config.PingInterval, time.Millisecond},
+		"CHAT_PONG_WAIT":                {&config.PongWait, time.Millisecond},
+		"CHAT_READ_TIMEOUT":             {&config.ReadTimeout, time.Millisecond},
+		"CHAT_WRITE_TIMEOUT":            {&config.WriteTimeout, time.Millisecond},
+		"CHAT_DEDUP_WINDOW":             {&config.DedupWindow, time.Millisecond},
+		"CHAT_EDIT_WINDOW":              {&config.EditWindow, 0},
+		"CHAT_DELETE_WINDOW":            {&config.DeleteWindow, 0},
+		"CHAT_PRESENCE_TTL":             {&config.PresenceTTL, time.Second},
+		"CHAT_TYPING_TTL":               {&config.TypingTTL, time.Second},
+		"CHAT_TYPING_THROTTLE":          {&config.TypingThrottle, 0},
+		"CHAT_RATE_LIMIT_STRIKE_WINDOW": {&config.RateLimitStrikeWindow, time.Millisecond},
+		"CHAT_RATE_LIMIT_BAN":           {&config.RateLimitBan, 0},
+	}
+	for key, setting := range durations {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Invalid %s: %v", key, err)
+			continue
+		}
+		if d < setting.min {
+			log.Printf("Invalid %s: %s (must be at least %s)", key, value, setting.min)
+			continue
+		}
+		*setting.target = d
+	}
+
+	ints := map[string]*int{
+		"CHAT_MAX_REACTIONS":      &config.MaxReactionsPerUser,
+		"CHAT_USER_MESSAGE_BURST": &config.UserMessageLimit.Burst,
+		"CHAT_ROOM_MESSAGE_BURST": &config.RoomMessageLimit.Burst,
+		"CHAT_RATE_LIMIT_STRIKES": &config.RateLimitStrikes,
+	}
+	for key, target := range ints {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			log.Printf("Invalid %s: %v", key, err)
+			continue
+		}
+		*target = n
+	}
+
+	// 초당 채워지는 토큰 수 (예: CHAT_USER_MESSAGE_RATE=0.5)
+	rates := map[string]*float64{
+		"CHAT_USER_MESSAGE_RATE": &config.UserMessageLimit.Rate,
+		"CHAT_ROOM_MESSAGE_RATE": &config.RoomMessageLimit.Rate,
+	}
+	for key, target := range rates {
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			log.Printf("Invalid %s: %v", key, err)
+			continue
+		}
+		*target = rate
+	}
+
+	// 0 이하이면 웹소켓의 읽기 제한이 꺼져 프레임 크기에 제한이 없어지므로 양수만 받습니다.
+	if value := os.Getenv("CHAT_MAX_MESSAGE_SIZE"); value != "" {
+		size, err := strconv.ParseInt(value, 10, 64)
+		switch {
+		case err != nil:
+			log.Printf("Invalid CHAT_MAX_MESSAGE_SIZE: %v", err)
+		case size <= 0:
+			log.Printf("Invalid CHAT_MAX_MESSAGE_SIZE: %s (must be positive)", value)
+		default:
+			config.MaxMessageSize = size
+		}
+	}
+
+	return config
+}
+
+// MESSAGE_ARCHIVE_INTERVAL로 메시지 보관 주기를 덮어씁니다 (예: MESSAGE_ARCHIVE_INTERVAL=30s)
+func getArchiveInterval() time.Duration {
+	value := os.Getenv("MESSAGE_ARCHIVE_INTERVAL")
+	if value == "" {
+		return redisRepo.DefaultArchiveInterval
+	}
+
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		log.Printf("Invalid MESSAGE_ARCHIVE_INTERVAL: %s", value)
+		return redisRepo.DefaultArchiveInterval
+	}
+	return interval
+}
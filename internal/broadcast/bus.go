@@ -0,0 +1,33 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Envelope는 서버 인스턴스 사이에 전달되는 채팅방 이벤트입니다.
+type Envelope struct {
+	// Origin은 이벤트를 발행한 노드의 ID입니다. 수신한 노드는 자신이 발행한 이벤트를 다시 전달하지 않습니다.
+	Origin       string `json:"origin"`
+	RoomID       string `json:"roomId"`
+	ExceptUserID string `json:"exceptUserId,omitempty"`
+	// UserIDs가 있으면 방 구독자 대신 이 사용자들의 세션 중 방을 구독하지 않은 세션에 전달합니다.
+	// 방 밖의 스레드 참여자처럼 구독과 관계없이 이벤트를 받아야 하는 사용자에게 사용하며,
+	// RoomID가 비어 있으면 멘션처럼 사용자의 모든 세션에 전달합니다.
+	UserIDs []string `json:"userIds,omitempty"`
+	// 새 메시지 이벤트면 메시지 ID와 작성자. 받은 노드는 소켓에 쓴 뒤 받은 사용자의 전달 커서를 옮깁니다.
+	MessageID string          `json:"messageId,omitempty"`
+	AuthorID  string          `json:"authorId,omitempty"`
+	Payload   json.RawMessage `json:"payload"`
+	// RemovedUserID가 있으면 그 사용자가 방에서 제거되었음을 알리는 이벤트입니다. Payload는 없으며,
+	// 받은 노드는 멤버십 캐시를 지우고 그 사용자의 세션을 방 구독에서 뺍니다.
+	RemovedUserID string `json:"removedUserId,omitempty"`
+}
+
+// Bus는 여러 talk-server 인스턴스가 채팅방 이벤트를 주고받는 통로입니다.
+// 발행된 이벤트는 발행한 노드를 포함한 모든 구독자에게 전달됩니다.
+type Bus interface {
+	Publish(ctx context.Context, env Envelope) error
+	Subscribe(handler func(env Envelope))
+	Close() error
+}
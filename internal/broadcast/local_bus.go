@@ -0,0 +1,42 @@
+package broadcast
+
+import (
+	"context"
+	"sync"
+)
+
+// LocalBus는 한 프로세스 안에서만 이벤트를 전달하는 Bus 구현입니다.
+// 단일 노드로 운영하거나, 여러 ChatService가 같은 LocalBus를 공유해 여러 노드를 흉내 내는 테스트에 사용합니다.
+type LocalBus struct {
+	mutex    sync.RWMutex
+	handlers []func(env Envelope)
+}
+
+func NewLocalBus() *LocalBus {
+	return &LocalBus{}
+}
+
+func (b *LocalBus) Publish(ctx context.Context, env Envelope) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for _, handler := range b.handlers {
+		handler(env)
+	}
+	return nil
+}
+
+func (b *LocalBus) Subscribe(handler func(env Envelope)) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.handlers = append(b.handlers, handler)
+}
+
+func (b *LocalBus) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.handlers = nil
+	return nil
+}
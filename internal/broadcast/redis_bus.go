@@ -0,0 +1,77 @@
+package broadcast
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const defaultChannel = "pubsub:chat:events"
+
+// RedisBus는 Redis Pub/Sub으로 여러 노드에 이벤트를 전달하는 Bus 구현입니다.
+// 모든 노드가 하나의 채널을 구독하며, 연결이 끊기면 go-redis가 자동으로 다시 구독합니다.
+type RedisBus struct {
+	client  *redis.Client
+	channel string
+
+	mutex   sync.Mutex
+	pubsubs []*redis.PubSub
+}
+
+func NewRedisBus(client *redis.Client) *RedisBus {
+	return &RedisBus{
+		client:  client,
+		channel: defaultChannel,
+	}
+}
+
+func (b *RedisBus) Publish(ctx context.Context, env Envelope) error {
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe는 채널 구독을 시작합니다. 구독이 확인된 뒤 반환되므로 이후 발행된 이벤트는 놓치지 않습니다.
+func (b *RedisBus) Subscribe(handler func(env Envelope)) {
+	ctx := context.Background()
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		log.Println("Error subscribing to broadcast channel:", err)
+	}
+
+	b.mutex.Lock()
+	b.pubsubs = append(b.pubsubs, pubsub)
+	b.mutex.Unlock()
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var env Envelope
+			if err := json.Unmarshal([]byte(msg.Payload), &env); err != nil {
+				log.Println("Error parsing broadcast envelope:", err)
+				continue
+			}
+			handler(env)
+		}
+	}()
+}
+
+func (b *RedisBus) Close() error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	var firstErr error
+	for _, pubsub := range b.pubsubs {
+		if err := pubsub.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	b.pubsubs = nil
+	return firstErr
+}
@@ -1,14 +1,20 @@
 package chatting
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"server/internal/models/message"
+	"server/internal/repository"
 	"server/internal/service"
+	"server/pkg/authenticator"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -87,40 +93,121 @@ var WebSocketUpgrader = websocket.Upgrader{
 	},
 }
 
+const (
+	// 토큰 검증 실패 시 사용하는 애플리케이션 정의 종료 코드 (4000-4999)
+	CloseInvalidToken  = 4001
+	CloseTokenExpired  = 4002
+	CloseNotRoomMember = 4003
+	// 메시지 전송량 제한을 반복해서 넘겨 일시적으로 차단됨
+	CloseRateLimited = 4029
+
+	// 토큰을 Sec-WebSocket-Protocol 헤더로 전달할 때 사용하는 서브프로토콜 이름
+	// 클라이언트는 "bearer, {token}" 형태로 전송합니다.
+	bearerSubprotocol = "bearer"
+
+	handshakeTimeout = 10 * time.Second
+)
+
+// initialMessage는 연결 직후 클라이언트가 보내는 첫 프레임입니다.
+// RoomID는 선택 사항이며, 이후 subscribe 프레임으로 여러 방을 구독할 수 있습니다.
+// 재접속한 클라이언트는 LastMessageID로 마지막으로 받은 메시지를 알려 그 이후 메시지를 다시 받습니다.
 type initialMessage struct {
-	RoomID string `json:"roomId"`
-	UserID string `json:"userId"`
+	RoomID        string `json:"roomId,omitempty"`
+	Token         string `json:"token,omitempty"`
+	LastMessageID string `json:"lastMessageId,omitempty"`
+}
+
+// tokenFromRequest는 업그레이드 요청의 쿼리 파라미터 또는 Sec-WebSocket-Protocol 헤더에서 토큰을 찾습니다.
+func tokenFromRequest(r *http.Request) (token string, viaSubprotocol bool) {
+	if token := r.URL.Query().Get("token"); token != "" {
+		return token, false
+	}
+
+	protocols := websocket.Subprotocols(r)
+	for i, protocol := range protocols {
+		if protocol == bearerSubprotocol && i+1 < len(protocols) {
+			return protocols[i+1], true
+		}
+	}
+
+	return "", false
+}
+
+func closeWithCode(conn *websocket.Conn, code int, reason string) {
+	deadline := time.Now().Add(time.Second)
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), deadline)
+	conn.Close()
 }
 
 func (h *ChatHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
-	conn, err := WebSocketUpgrader.Upgrade(w, r, nil)
+	token, viaSubprotocol := tokenFromRequest(r)
+
+	var responseHeader http.Header
+	if viaSubprotocol {
+		responseHeader = http.Header{"Sec-WebSocket-Protocol": []string{bearerSubprotocol}}
+	}
+
+	conn, err := WebSocketUpgrader.Upgrade(w, r, responseHeader)
 	if err != nil {
 		log.Println("Error upgrading to WebSocket:", err)
 		return
 	}
 
+	conn.SetReadDeadline(time.Now().Add(handshakeTimeout))
 	_, msgBytes, err := conn.ReadMessage()
 	if err != nil {
 		log.Println("Error reading initial message:", err)
 		conn.Close()
 		return
 	}
+	conn.SetReadDeadline(time.Time{})
 
 	var initMsg initialMessage
 	err = json.Unmarshal(msgBytes, &initMsg)
 	if err != nil {
 		log.Println("Error parsing initial message:", err)
-		conn.Close()
+		closeWithCode(conn, websocket.CloseUnsupportedData, "invalid initial message")
 		return
 	}
 
-	if initMsg.RoomID == "" || initMsg.UserID == "" {
-		log.Println("Invalid room ID or user ID")
-		conn.Close()
+	// 쿼리나 헤더로 토큰이 오지 않았다면 첫 메시지(인증 프레임)의 토큰을 사용
+	if token == "" {
+		token = initMsg.Token
+	}
+
+	userID, err := authenticator.ValidateToken(token)
+	if errors.Is(err, authenticator.ErrTokenExpired) {
+		closeWithCode(conn, CloseTokenExpired, "token expired")
+		return
+	}
+	if err != nil {
+		closeWithCode(conn, CloseInvalidToken, "invalid token")
 		return
 	}
 
-	err = h.chatService.HandleWebSocketConnection(r.Context(), initMsg.RoomID, initMsg.UserID, conn)
+	var lastMessageID uuid.UUID
+	if initMsg.LastMessageID != "" {
+		lastMessageID, err = uuid.Parse(initMsg.LastMessageID)
+		if err != nil {
+			closeWithCode(conn, websocket.CloseUnsupportedData, "invalid lastMessageId")
+			return
+		}
+	}
+
+	// 업그레이드된 연결은 핸들러가 반환된 뒤에도 유지되므로 요청 컨텍스트의 취소를 따르지 않음
+	ctx := context.WithoutCancel(r.Context())
+
+	err = h.chatService.HandleWebSocketConnection(ctx, initMsg.RoomID, userID, lastMessageID, conn)
+	var notMemberErr *service.NotRoomMemberError
+	if errors.As(err, &notMemberErr) {
+		closeWithCode(conn, CloseNotRoomMember, "not a member of the room")
+		return
+	}
+	var rateLimitedErr *service.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		closeWithCode(conn, CloseRateLimited, "rate limited")
+		return
+	}
 	if err != nil {
 		log.Println("Error handling WebSocket connection:", err)
 		conn.Close()
@@ -131,21 +218,67 @@ func (h *ChatHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 type MessageResponse struct {
 	Success  bool              `json:"success"`
 	Messages []message.Message `json:"messages"`
+	// before/after/limit으로 조회한 경우 같은 방향으로 더 조회할 메시지가 있는지와 다음 커서
+	HasMore    bool   `json:"hasMore"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// parsePageQuery는 before, after, limit 쿼리 파라미터를 읽습니다.
+func parsePageQuery(r *http.Request) (message.PageQuery, error) {
+	var query message.PageQuery
+	params := r.URL.Query()
+
+	if before := params.Get("before"); before != "" {
+		id, err := uuid.Parse(before)
+		if err != nil {
+			return query, errors.New("invalid before cursor")
+		}
+		query.Before = id
+	}
+
+	if after := params.Get("after"); after != "" {
+		id, err := uuid.Parse(after)
+		if err != nil {
+			return query, errors.New("invalid after cursor")
+		}
+		query.After = id
+	}
+
+	if query.Before != uuid.Nil && query.After != uuid.Nil {
+		return query, errors.New("only one of before and after can be specified")
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return query, errors.New("invalid limit")
+		}
+		query.Limit = n
+	}
+
+	return query, nil
 }
 
 func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	userID := userUUID.String()
+
 	roomID := r.URL.Query().Get("roomId")
 	if roomID == "" {
 		http.Error(w, "Missing room ID", http.StatusBadRequest)
 		return
 	}
 
-	var messages []message.Message
-	var err error
+	response := MessageResponse{Success: true}
 
+	var page message.Page
 	lastMessageUUIDStr := r.URL.Query().Get("lastMessageId")
 	if lastMessageUUIDStr != "" {
-		// UUID 기반 조회
+		// 이전 버전 클라이언트용: 커서 이후 메시지를 한도까지 조회
 		lastMessageUUID, uuidErr := uuid.Parse(lastMessageUUIDStr)
 		if uuidErr != nil {
 			// UUID 파싱 실패 시 숫자 ID로 시도
@@ -155,20 +288,43 @@ func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
 				http.Error(w, "Invalid last message ID", http.StatusBadRequest)
 				return
 			}
-			messages, err = h.chatService.GetMessages(r.Context(), roomID, lastMessageID)
+			page, err = h.chatService.GetMessages(r.Context(), roomID, userID, lastMessageID)
 		} else {
-			messages, err = h.chatService.GetMessagesByUUID(r.Context(), roomID, lastMessageUUID)
+			page, err = h.chatService.GetMessagesByUUID(r.Context(), roomID, userID, lastMessageUUID)
 		}
 	} else {
-		// 모든 메시지 조회
-		messages, err = h.chatService.GetMessages(r.Context(), roomID, 0)
+		query, queryErr := parsePageQuery(r)
+		if queryErr != nil {
+			http.Error(w, queryErr.Error(), http.StatusBadRequest)
+			return
+		}
+
+		page, err = h.chatService.ListMessages(r.Context(), roomID, userID, query)
 	}
 
+	var notMemberErr *service.NotRoomMemberError
+	if errors.As(err, &notMemberErr) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, repository.ErrInvalidMessageID) {
+		http.Error(w, "Invalid message ID cursor", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	response.Messages = page.Messages
+	response.HasMore = page.HasMore
+	if page.NextCursor != uuid.Nil {
+		response.NextCursor = page.NextCursor.String()
+	}
+	if response.Messages == nil {
+		response.Messages = []message.Message{}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(MessageResponse{Success: true, Messages: messages})
+	json.NewEncoder(w).Encode(response)
 }
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"server/internal/models/message"
+	"server/pkg/authenticator"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,13 +22,71 @@ func (m *MockChatService) SaveMessage(ctx context.Context, roomID string, msg me
 	return args.Error(0)
 }
 
-func (m *MockChatService) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *MockChatService) GetMessages(ctx context.Context, roomID, userID string, lastMessageID int64) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageID)
+	return args.Get(0).(message.Page), args.Error(1)
 }
 
-func (m *MockChatService) HandleWebSocketConnection(ctx context.Context, roomID, userID string, conn interface{}) error {
-	args := m.Called(ctx, roomID, userID, conn)
+func (m *MockChatService) GetMessagesByUUID(ctx context.Context, roomID, userID string, lastMessageUUID uuid.UUID) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageUUID)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *MockChatService) ListMessages(ctx context.Context, roomID, userID string, query message.PageQuery) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, query)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *MockChatService) HandleWebSocketConnection(ctx context.Context, roomID, userID string, lastMessageID uuid.UUID, conn interface{}) error {
+	args := m.Called(ctx, roomID, userID, lastMessageID, conn)
+	return args.Error(0)
+}
+
+func (m *MockChatService) EditMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID, content string, mentions []message.Mention) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID, content, mentions)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *MockChatService) GetMessageRevisions(ctx context.Context, roomID, userID string, messageID uuid.UUID) ([]message.Revision, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Get(0).([]message.Revision), args.Error(1)
+}
+
+func (m *MockChatService) DeleteMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *MockChatService) HideMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *MockChatService) GetThread(ctx context.Context, roomID, userID string, messageID uuid.UUID, query message.PageQuery) (message.Message, message.Page, error) {
+	args := m.Called(ctx, roomID, userID, messageID, query)
+	root, _ := args.Get(0).(message.Message)
+	return root, args.Get(1).(message.Page), args.Error(2)
+}
+
+func (m *MockChatService) JoinThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *MockChatService) LeaveThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *MockChatService) ListMentions(ctx context.Context, userID string, query message.MentionQuery) (message.MentionPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(message.MentionPage), args.Error(1)
+}
+
+func (m *MockChatService) ReadMentions(ctx context.Context, roomID, userID string) error {
+	args := m.Called(ctx, roomID, userID)
 	return args.Error(0)
 }
 
@@ -35,12 +95,15 @@ func TestGetMessages(t *testing.T) {
 
 	messages := []message.Message{}
 
-	mockService.On("GetMessages", mock.Anything, "room1", int64(0)).Return(messages, nil)
+	userID := uuid.New()
+
+	mockService.On("ListMessages", mock.Anything, "room1", userID.String(), message.PageQuery{}).Return(message.Page{Messages: messages}, nil)
 
 	handler := NewChatHandler(mockService)
 
-	req, err := http.NewRequest("GET", "/messages?room_id=room1", nil)
+	req, err := http.NewRequest("GET", "/messages?roomId=room1", nil)
 	assert.NoError(t, err)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
 
 	rr := httptest.NewRecorder()
 
@@ -0,0 +1,80 @@
+package chatting
+
+import (
+	"encoding/json"
+	"net/http"
+	"server/internal/models/message"
+	"server/pkg/authenticator"
+
+	"github.com/google/uuid"
+)
+
+type MentionListResponse struct {
+	Success bool `json:"success"`
+	// 멘션된 메시지는 최신 순서
+	Messages []message.Message `json:"messages"`
+	// 방 ID -> 읽지 않은 멘션 수
+	UnreadCounts map[string]int `json:"unreadCounts"`
+	HasMore      bool           `json:"hasMore"`
+	NextCursor   string         `json:"nextCursor,omitempty"`
+}
+
+// GetMentions는 요청한 사용자가 멘션된 메시지를 모든 채팅방에 걸쳐 최신 순으로 반환합니다.
+func (h *ChatHandler) GetMentions(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	pageQuery, err := parsePageQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if pageQuery.After != uuid.Nil {
+		http.Error(w, "after cursor is not supported", http.StatusBadRequest)
+		return
+	}
+
+	query := message.MentionQuery{Before: pageQuery.Before, Limit: pageQuery.Limit}
+	page, err := h.chatService.ListMentions(r.Context(), userUUID.String(), query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := MentionListResponse{Success: true, Messages: page.Messages, UnreadCounts: page.UnreadCounts, HasMore: page.HasMore}
+	if response.UnreadCounts == nil {
+		response.UnreadCounts = map[string]int{}
+	}
+	if page.NextCursor != uuid.Nil {
+		response.NextCursor = page.NextCursor.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ReadMentions는 채팅방의 읽지 않은 멘션 수를 0으로 만듭니다.
+func (h *ChatHandler) ReadMentions(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	roomID := r.URL.Query().Get("roomId")
+	if roomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.chatService.ReadMentions(r.Context(), roomID, userUUID.String()); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SuccessResponse{Success: true})
+}
@@ -0,0 +1,157 @@
+package chatting
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"server/internal/service"
+	"server/pkg/authenticator"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type EditMessageRequest struct {
+	RoomID   string            `json:"roomId"`
+	Content  string            `json:"content"`
+	Mentions []message.Mention `json:"mentions"`
+}
+
+type EditMessageResponse struct {
+	Success bool            `json:"success"`
+	Message message.Message `json:"message"`
+}
+
+type SuccessResponse struct {
+	Success bool `json:"success"`
+}
+
+type RevisionListResponse struct {
+	Success   bool               `json:"success"`
+	Revisions []message.Revision `json:"revisions"`
+}
+
+// writeChangeError는 이미 보낸 메시지를 바꾸는 요청의 오류를 HTTP 상태 코드로 응답합니다.
+func writeChangeError(w http.ResponseWriter, err error) {
+	var notMemberErr *service.NotRoomMemberError
+	switch {
+	case errors.As(err, &notMemberErr), errors.Is(err, service.ErrNotMessageAuthor):
+		http.Error(w, "Forbidden", http.StatusForbidden)
+	case errors.Is(err, repository.ErrMessageNotFound), errors.Is(err, repository.ErrInvalidMessageID):
+		http.Error(w, "Message not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrEditWindowExpired), errors.Is(err, service.ErrDeleteWindowExpired):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrMessageNotEditable), errors.Is(err, message.ErrInvalidMention):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// messageIDFromPath는 경로의 messageId 파라미터를 읽습니다.
+func messageIDFromPath(r *http.Request) (uuid.UUID, error) {
+	return uuid.Parse(mux.Vars(r)["messageId"])
+}
+
+func (h *ChatHandler) EditMessage(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := messageIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	var req EditMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.RoomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+	if req.Content == "" {
+		http.Error(w, "Missing content", http.StatusBadRequest)
+		return
+	}
+
+	edited, err := h.chatService.EditMessage(r.Context(), req.RoomID, userUUID.String(), messageID, req.Content, req.Mentions)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(EditMessageResponse{Success: true, Message: edited})
+}
+
+func (h *ChatHandler) GetMessageRevisions(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := messageIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	roomID := r.URL.Query().Get("roomId")
+	if roomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.chatService.GetMessageRevisions(r.Context(), roomID, userUUID.String(), messageID)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(RevisionListResponse{Success: true, Revisions: revisions})
+}
+
+// DeleteMessage는 forEveryone=true이면 메시지를 모두에게서 삭제하고, 아니면 요청한 사용자에게만 숨깁니다.
+func (h *ChatHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := messageIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	roomID := query.Get("roomId")
+	if roomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+
+	if query.Get("forEveryone") == "true" {
+		_, err = h.chatService.DeleteMessage(r.Context(), roomID, userUUID.String(), messageID)
+	} else {
+		err = h.chatService.HideMessage(r.Context(), roomID, userUUID.String(), messageID)
+	}
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SuccessResponse{Success: true})
+}
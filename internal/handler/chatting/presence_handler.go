@@ -0,0 +1,100 @@
+package chatting
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/models/presence"
+	"server/internal/service"
+	"server/pkg/authenticator"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// 한 번에 조회할 수 있는 사용자 수
+const maxPresenceUsers = 100
+
+type PresenceHandler struct {
+	presenceService service.PresenceService
+}
+
+func NewPresenceHandler(presenceService service.PresenceService) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presenceService,
+	}
+}
+
+type PresenceResponse struct {
+	Success bool `json:"success"`
+	// 요청한 사용자 순서
+	Presence []presence.Presence `json:"presence"`
+}
+
+type PresenceSettingsRequest struct {
+	HideLastSeen bool `json:"hideLastSeen"`
+}
+
+// GetPresence는 userIds 쿼리 파라미터(쉼표로 구분)로 지정한 사용자의 접속 상태를 반환합니다.
+func (h *PresenceHandler) GetPresence(w http.ResponseWriter, r *http.Request) {
+	viewerID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	param := r.URL.Query().Get("userIds")
+	if param == "" {
+		http.Error(w, "Missing user IDs", http.StatusBadRequest)
+		return
+	}
+	userIDs := strings.Split(param, ",")
+	if len(userIDs) > maxPresenceUsers {
+		http.Error(w, "too many user IDs", http.StatusBadRequest)
+		return
+	}
+	for i, userID := range userIDs {
+		id, err := uuid.Parse(strings.TrimSpace(userID))
+		if err != nil {
+			http.Error(w, "invalid user ID", http.StatusBadRequest)
+			return
+		}
+		userIDs[i] = id.String()
+	}
+
+	statuses, err := h.presenceService.GetPresence(r.Context(), viewerID.String(), userIDs)
+	if errors.Is(err, service.ErrPresenceNotVisible) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PresenceResponse{Success: true, Presence: statuses})
+}
+
+// UpdatePresenceSettings는 다른 사용자에게 마지막 접속 시각을 숨길지 설정합니다.
+func (h *PresenceHandler) UpdatePresenceSettings(w http.ResponseWriter, r *http.Request) {
+	userID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req PresenceSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.presenceService.SetHideLastSeen(r.Context(), userID.String(), req.HideLastSeen); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SuccessResponse{Success: true})
+}
@@ -0,0 +1,123 @@
+package chatting
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"server/internal/models/message"
+	"server/internal/service"
+	"server/pkg/authenticator"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SearchHandler struct {
+	searchService service.MessageSearchService
+}
+
+func NewSearchHandler(searchService service.MessageSearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+type SearchResponse struct {
+	Success    bool                   `json:"success"`
+	Results    []message.SearchResult `json:"results"`
+	HasMore    bool                   `json:"hasMore"`
+	NextCursor string                 `json:"nextCursor,omitempty"`
+}
+
+// parseSearchQuery는 q, roomId, senderId, from, to, before, limit 쿼리 파라미터를 읽습니다.
+func parseSearchQuery(r *http.Request) (message.SearchQuery, error) {
+	params := r.URL.Query()
+	query := message.SearchQuery{
+		Text:     params.Get("q"),
+		RoomID:   params.Get("roomId"),
+		AuthorID: params.Get("senderId"),
+	}
+
+	if query.Text == "" {
+		return query, errors.New("missing search text")
+	}
+
+	if query.RoomID != "" {
+		if _, err := uuid.Parse(query.RoomID); err != nil {
+			return query, errors.New("invalid room ID")
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return query, errors.New("invalid " + name + " time")
+			}
+			*target = t
+		}
+	}
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return query, errors.New("from must be before to")
+	}
+
+	if before := params.Get("before"); before != "" {
+		id, err := uuid.Parse(before)
+		if err != nil {
+			return query, errors.New("invalid before cursor")
+		}
+		query.Before = id
+	}
+
+	if limit := params.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			return query, errors.New("invalid limit")
+		}
+		query.Limit = n
+	}
+
+	return query, nil
+}
+
+func (h *SearchHandler) SearchMessages(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query, err := parseSearchQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	page, err := h.searchService.SearchMessages(r.Context(), userUUID.String(), query)
+
+	var notMemberErr *service.NotRoomMemberError
+	if errors.As(err, &notMemberErr) {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, service.ErrEmptySearchQuery) {
+		http.Error(w, "missing search text", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response := SearchResponse{Success: true, Results: page.Results, HasMore: page.HasMore}
+	if response.Results == nil {
+		response.Results = []message.SearchResult{}
+	}
+	if page.NextCursor != uuid.Nil {
+		response.NextCursor = page.NextCursor.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
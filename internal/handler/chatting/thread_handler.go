@@ -0,0 +1,102 @@
+package chatting
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"server/internal/models/message"
+	"server/pkg/authenticator"
+
+	"github.com/google/uuid"
+)
+
+type ThreadResponse struct {
+	Success bool            `json:"success"`
+	Root    message.Message `json:"root"`
+	// 답장은 오래된 순서. before/after/limit은 메시지 기록 조회와 같음
+	Messages   []message.Message `json:"messages"`
+	HasMore    bool              `json:"hasMore"`
+	NextCursor string            `json:"nextCursor,omitempty"`
+}
+
+// GetThread는 메시지가 속한 스레드의 루트 메시지와 답장 한 페이지를 반환합니다.
+func (h *ChatHandler) GetThread(w http.ResponseWriter, r *http.Request) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := messageIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	roomID := r.URL.Query().Get("roomId")
+	if roomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+
+	query, err := parsePageQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	root, page, err := h.chatService.GetThread(r.Context(), roomID, userUUID.String(), messageID, query)
+	if err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	response := ThreadResponse{Success: true, Root: root, Messages: page.Messages, HasMore: page.HasMore}
+	if response.Messages == nil {
+		response.Messages = []message.Message{}
+	}
+	if page.NextCursor != uuid.Nil {
+		response.NextCursor = page.NextCursor.String()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// JoinThread는 요청한 사용자를 메시지가 속한 스레드의 참여자로 등록합니다.
+func (h *ChatHandler) JoinThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThreadParticipation(w, r, h.chatService.JoinThread)
+}
+
+// LeaveThread는 요청한 사용자를 메시지가 속한 스레드의 참여자에서 뺍니다.
+func (h *ChatHandler) LeaveThread(w http.ResponseWriter, r *http.Request) {
+	h.changeThreadParticipation(w, r, h.chatService.LeaveThread)
+}
+
+func (h *ChatHandler) changeThreadParticipation(w http.ResponseWriter, r *http.Request, change func(ctx context.Context, roomID, userID string, messageID uuid.UUID) error) {
+	userUUID, err := authenticator.GetUserID(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	messageID, err := messageIDFromPath(r)
+	if err != nil {
+		http.Error(w, "Invalid message ID", http.StatusBadRequest)
+		return
+	}
+
+	roomID := r.URL.Query().Get("roomId")
+	if roomID == "" {
+		http.Error(w, "Missing room ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := change(r.Context(), roomID, userUUID.String(), messageID); err != nil {
+		writeChangeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(SuccessResponse{Success: true})
+}
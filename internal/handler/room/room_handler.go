@@ -2,10 +2,11 @@ package room
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"server/internal/models/orm"
 	"server/internal/service"
 	"server/pkg/authenticator"
+	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
@@ -22,10 +23,14 @@ func NewHandler(roomService service.RoomService) *Handler {
 }
 
 type RoomListResponse struct {
-	Success bool       `json:"success"`
-	Rooms   []orm.Room `json:"rooms"`
+	Success bool `json:"success"`
+	// 최근 활동 순서
+	Rooms      []service.RoomListEntry `json:"rooms"`
+	HasMore    bool                    `json:"hasMore"`
+	NextCursor string                  `json:"nextCursor,omitempty"`
 }
 
+// GetRoomList는 요청한 사용자가 속한 채팅방을 마지막 메시지, 읽지 않은 메시지 수와 함께 최근 활동 순서로 반환합니다.
 func (h *Handler) GetRoomList(w http.ResponseWriter, r *http.Request) {
 	userID, err := authenticator.GetUserID(r)
 	if err != nil {
@@ -33,14 +38,27 @@ func (h *Handler) GetRoomList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rooms, err := h.roomService.GetUserRooms(r.Context(), userID)
+	query := service.RoomListQuery{Cursor: r.URL.Query().Get("cursor")}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+		if err != nil || query.Limit <= 0 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	page, err := h.roomService.ListRooms(r.Context(), userID, query)
+	if errors.Is(err, service.ErrInvalidRoomCursor) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(RoomListResponse{Success: true, Rooms: rooms})
+	json.NewEncoder(w).Encode(RoomListResponse{Success: true, Rooms: page.Rooms, HasMore: page.HasMore, NextCursor: page.NextCursor})
 }
 
 type CreateRoomRequest struct {
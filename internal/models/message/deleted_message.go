@@ -0,0 +1,35 @@
+package message
+
+import (
+	"encoding/json"
+)
+
+// DeletedMessage는 모두에게서 삭제된 메시지 자리에 남는 툼스톤입니다. 원래 본문은 담지 않습니다.
+type DeletedMessage struct {
+	BaseMessage
+	DeletedAt string `json:"deletedAt"`
+}
+
+// NewTombstone은 msg의 ID, 방, 작성자, 보낸 시각과 속한 스레드만 남긴 툼스톤을 만듭니다.
+func NewTombstone(msg Message, deletedAt string) *DeletedMessage {
+	base := *msg.Base()
+	base.Type = "deleted"
+	base.EditedAt = ""
+	base.ReplyTo = nil
+	base.ReadCount = 0
+
+	return &DeletedMessage{BaseMessage: base, DeletedAt: deletedAt}
+}
+
+func (d *DeletedMessage) GetMessageType() string {
+	return "deleted"
+}
+
+func (d *DeletedMessage) ToJson() string {
+	jsonString, _ := json.Marshal(d)
+	return string(jsonString)
+}
+
+func (d *DeletedMessage) FromJson(data json.RawMessage) error {
+	return json.Unmarshal([]byte(data), &d)
+}
@@ -23,6 +23,6 @@ func (i *ImageMessage) ToJson() string {
 	return string(jsonString)
 }
 
-func (i *ImageMessage) FromJson(data json.RawMessage) {
-	json.Unmarshal([]byte(data), &i)
+func (i *ImageMessage) FromJson(data json.RawMessage) error {
+	return json.Unmarshal([]byte(data), &i)
 }
@@ -0,0 +1,92 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidMention은 멘션 구간이 본문과 맞지 않거나 멘션한 사용자가 채팅방 멤버가 아닐 때 반환됩니다.
+var ErrInvalidMention = errors.New("invalid mention")
+
+// 메시지 하나에 넣을 수 있는 멘션 수
+const MaxMentions = 50
+
+const (
+	// 특정 사용자를 멘션함
+	MentionTypeUser = "user"
+	// 채팅방의 모든 멤버를 멘션함 (@all)
+	MentionTypeAll = "all"
+)
+
+// Mention은 텍스트 메시지 본문에서 멘션이 차지하는 구간입니다.
+// Offset과 Length는 JavaScript 문자열과 같은 UTF-16 코드 단위이며, 구간은 "@"로 시작해야 합니다.
+type Mention struct {
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+	Type   string `json:"type"`
+	// Type이 "user"일 때 멘션한 사용자 ID
+	UserId string `json:"userId,omitempty"`
+}
+
+// ValidateMentions는 멘션 구간이 본문 안에 겹치지 않고 순서대로 있는지 확인합니다. 멤버십은 확인하지 않습니다.
+func ValidateMentions(content string, mentions []Mention) error {
+	if len(mentions) > MaxMentions {
+		return fmt.Errorf("%w: at most %d mentions are allowed", ErrInvalidMention, MaxMentions)
+	}
+
+	text := utf16.Encode([]rune(content))
+
+	end := 0
+	for _, mention := range mentions {
+		if mention.Offset < end || mention.Length <= 0 || mention.Offset+mention.Length > len(text) {
+			return fmt.Errorf("%w: span %d+%d is out of order or out of range", ErrInvalidMention, mention.Offset, mention.Length)
+		}
+		end = mention.Offset + mention.Length
+
+		span := string(utf16.Decode(text[mention.Offset:end]))
+		if !strings.HasPrefix(span, "@") {
+			return fmt.Errorf("%w: span %q does not start with @", ErrInvalidMention, span)
+		}
+
+		switch mention.Type {
+		case MentionTypeUser:
+			if _, err := uuid.Parse(mention.UserId); err != nil {
+				return fmt.Errorf("%w: invalid user ID %q", ErrInvalidMention, mention.UserId)
+			}
+		case MentionTypeAll:
+			if mention.UserId != "" {
+				return fmt.Errorf("%w: @all cannot have a user ID", ErrInvalidMention)
+			}
+		default:
+			return fmt.Errorf("%w: unknown type %q", ErrInvalidMention, mention.Type)
+		}
+	}
+	return nil
+}
+
+// MentionRef는 사용자의 멘션 목록의 한 항목입니다.
+type MentionRef struct {
+	RoomId    string    `json:"roomId"`
+	MessageId uuid.UUID `json:"messageId"`
+}
+
+// MentionQuery는 멘션 목록의 한 페이지를 지정합니다. Before가 없으면 가장 최근 멘션부터 조회합니다.
+type MentionQuery struct {
+	// 이 메시지보다 이전 멘션을 조회
+	Before uuid.UUID
+	Limit  int
+}
+
+// MentionPage는 조회한 멘션과 다음 페이지 정보입니다. Messages는 최신 순서입니다.
+type MentionPage struct {
+	Messages []Message
+	// 방 ID -> 읽지 않은 멘션 수. 읽지 않은 멘션이 없는 방은 없음
+	UnreadCounts map[string]int
+	HasMore      bool
+	// 다음 페이지를 조회할 때 Before로 사용할 메시지 ID. HasMore가 false이면 uuid.Nil
+	NextCursor uuid.UUID
+}
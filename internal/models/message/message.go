@@ -18,7 +18,10 @@ type Message interface {
 	GetTimestamp() string
 	GetMessageType() string
 	ToJson() string
-	FromJson(data json.RawMessage)
+	// FromJson은 JSON을 메시지로 읽습니다. 필드의 형식이 맞지 않으면 오류를 반환합니다.
+	FromJson(data json.RawMessage) error
+	// Base는 공통 필드를 채울 수 있도록 메시지에 포함된 BaseMessage를 반환합니다.
+	Base() *BaseMessage
 }
 
 type BaseMessage struct {
@@ -27,6 +30,18 @@ type BaseMessage struct {
 	Type      string    `json:"type"`
 	Author    User      `json:"author"`
 	Timestamp string    `json:"timestamp,omitempty"`
+	// 마지막으로 수정된 시각. 수정되지 않았으면 비어 있음
+	EditedAt string `json:"editedAt,omitempty"`
+	// 메시지에 달린 반응 집계. 기록을 조회할 때 채워지며 저장되는 본문에는 담기지 않음
+	Reactions []Reaction `json:"reactions,omitempty"`
+	// 답장한 메시지의 인용. 기록을 조회할 때 인용한 메시지의 최신 판으로 다시 채워짐
+	ReplyTo *Quote `json:"replyTo,omitempty"`
+	// 답장이 속한 스레드의 루트 메시지 ID. 답장이 아니면 비어 있음
+	ThreadId *uuid.UUID `json:"threadId,omitempty"`
+	// 답장이 달린 메시지의 스레드 요약. 기록을 조회할 때 채워지며 저장되는 본문에는 담기지 않음
+	Thread *ThreadSummary `json:"thread,omitempty"`
+	// 작성자를 뺀 멤버 중 이 메시지까지 읽은 멤버 수. 기록을 조회할 때 읽음 커서로 계산됨
+	ReadCount int `json:"readCount,omitempty"`
 }
 
 func (m *BaseMessage) GenerateID() {
@@ -34,6 +49,10 @@ func (m *BaseMessage) GenerateID() {
 	m.Id = id
 }
 
+func (m *BaseMessage) Base() *BaseMessage {
+	return m
+}
+
 func (m *BaseMessage) GetID() uuid.UUID {
 	return m.Id
 }
@@ -63,11 +82,33 @@ func (m *BaseMessage) ToJson() string {
 	return string(jsonData)
 }
 
-func (m *BaseMessage) FromJson(data json.RawMessage) {
-	json.Unmarshal(data, m)
+func (m *BaseMessage) FromJson(data json.RawMessage) error {
+	return json.Unmarshal(data, m)
 }
 
 type MessageResponse struct {
 	Success  bool      `json:"success"`
 	Messages []Message `json:"messages"`
 }
+
+// Receipt는 클라이언트가 보낸 메시지를 서버가 저장한 결과입니다.
+// 같은 클라이언트 메시지 ID로 재시도하면 처음 저장된 Receipt를 다시 돌려줍니다.
+type Receipt struct {
+	MessageId uuid.UUID `json:"messageId"`
+	Timestamp string    `json:"timestamp"`
+}
+
+// Revision은 메시지 본문의 한 판입니다.
+type Revision struct {
+	Content string `json:"content"`
+	// 이 판이 작성된 시각. 원본은 메시지의 timestamp, 수정본은 editedAt
+	Timestamp string `json:"timestamp"`
+}
+
+// Reaction은 메시지에 달린 이모지 반응 하나의 집계입니다.
+type Reaction struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+	// 반응한 사용자 ID. 먼저 반응한 순서
+	UserIds []string `json:"userIds"`
+}
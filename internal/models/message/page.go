@@ -0,0 +1,22 @@
+package message
+
+import "github.com/google/uuid"
+
+// PageQuery는 메시지 기록의 한 페이지를 지정합니다.
+// Before와 After는 동시에 지정할 수 없으며, 둘 다 없으면 가장 최근 메시지를 조회합니다.
+type PageQuery struct {
+	// 이 메시지보다 이전 메시지를 조회 (위로 스크롤)
+	Before uuid.UUID
+	// 이 메시지보다 이후 메시지를 조회
+	After uuid.UUID
+	Limit int
+}
+
+// Page는 조회한 메시지와 다음 페이지 정보입니다. Messages는 항상 오래된 순서입니다.
+type Page struct {
+	Messages []Message
+	// 같은 방향으로 더 조회할 메시지가 있는지 여부
+	HasMore bool
+	// 다음 페이지를 조회할 때 같은 방향의 커서로 사용할 메시지 ID. HasMore가 false이면 uuid.Nil
+	NextCursor uuid.UUID
+}
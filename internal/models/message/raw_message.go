@@ -0,0 +1,62 @@
+package message
+
+import (
+	"encoding/json"
+)
+
+// RawMessage는 등록되지 않은 타입의 메시지입니다.
+// 공통 필드만 읽고, 저장하거나 전달할 때는 받은 JSON에 현재 공통 필드를 덮어써 알 수 없는 필드도 보존합니다.
+type RawMessage struct {
+	BaseMessage
+	Raw json.RawMessage `json:"-"`
+}
+
+// baseMessageFields는 BaseMessage의 JSON 필드입니다. 비어 있어 생략된 공통 필드가 받은 JSON에서 되살아나지 않도록
+// 덮어쓰기 전에 모두 지웁니다. BaseMessage에 필드를 추가하면 여기에도 추가해야 합니다.
+var baseMessageFields = []string{
+	"id", "roomId", "type", "author", "timestamp", "editedAt",
+	"reactions", "replyTo", "threadId", "thread", "readCount",
+}
+
+func (r *RawMessage) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.BaseMessage); err != nil {
+		return err
+	}
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON은 받은 JSON에 반응, 읽음 수, 스레드 요약, 인용처럼 기록을 조회할 때 채운 공통 필드를 합칩니다.
+func (r *RawMessage) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(r.Raw) > 0 {
+		if err := json.Unmarshal(r.Raw, &fields); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range baseMessageFields {
+		delete(fields, name)
+	}
+
+	base, err := json.Marshal(&r.BaseMessage)
+	if err != nil {
+		return nil, err
+	}
+	var baseFields map[string]json.RawMessage
+	if err := json.Unmarshal(base, &baseFields); err != nil {
+		return nil, err
+	}
+	for name, value := range baseFields {
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+func (r *RawMessage) ToJson() string {
+	data, _ := r.MarshalJSON()
+	return string(data)
+}
+
+func (r *RawMessage) FromJson(data json.RawMessage) error {
+	return r.UnmarshalJSON(data)
+}
@@ -0,0 +1,74 @@
+package message
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// Constructor는 메시지 타입에 해당하는 빈 메시지를 만듭니다.
+type Constructor func() Message
+
+var (
+	registryMutex sync.RWMutex
+	// type 필드 값 -> 생성자
+	registry = map[string]Constructor{
+		"message": func() Message { return &TextMessage{} },
+		"image":   func() Message { return &ImageMessage{} },
+		"deleted": func() Message { return &DeletedMessage{} },
+	}
+)
+
+// Register는 메시지 타입을 등록합니다. 이미 등록된 타입이면 생성자를 바꿉니다.
+func Register(messageType string, constructor Constructor) {
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	registry[messageType] = constructor
+}
+
+// New는 등록된 타입의 빈 메시지를 만듭니다. 등록되지 않은 타입이면 false를 반환합니다.
+func New(messageType string) (Message, bool) {
+	registryMutex.RLock()
+	constructor, ok := registry[messageType]
+	registryMutex.RUnlock()
+
+	if !ok {
+		return nil, false
+	}
+	return constructor(), true
+}
+
+// Types는 등록된 메시지 타입을 정렬해 반환합니다.
+func Types() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for messageType := range registry {
+		types = append(types, messageType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Decode는 type 필드로 메시지 타입을 찾아 JSON을 해당 타입으로 읽습니다.
+// 등록되지 않은 타입은 버리지 않고 원본 JSON을 그대로 가진 RawMessage로 반환합니다.
+func Decode(data []byte) (Message, error) {
+	var header struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	msg, ok := New(header.Type)
+	if !ok {
+		msg = &RawMessage{}
+	}
+
+	if err := json.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
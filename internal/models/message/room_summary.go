@@ -0,0 +1,20 @@
+package message
+
+import "time"
+
+// RoomSummary는 채팅방 목록에 보여 줄 방의 마지막 메시지와 읽지 않은 메시지 수입니다.
+type RoomSummary struct {
+	// 가장 최근 메시지의 요약. 수정되거나 삭제되면 바뀐 내용으로 갱신됨
+	LastMessage *Quote
+	// 가장 최근 메시지가 저장된 시각
+	LastActivity time.Time
+	// 읽음 커서 이후의 메시지 중 삭제되거나 사용자가 숨긴 메시지를 뺀 수. 방마다 기록하는 최근 메시지 수를 넘지 않음
+	UnreadCount int
+}
+
+// RoomActivity는 사용자의 채팅방 목록에서 방의 위치입니다. 목록은 활동 시각의 역순, 같으면 방 ID의 역순입니다.
+type RoomActivity struct {
+	RoomID string
+	// 가장 최근 메시지가 저장된 시각. 메시지가 없는 방이면 방을 만든 시각
+	LastActivity time.Time
+}
@@ -0,0 +1,35 @@
+package message
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SearchQuery는 사용자가 속한 방의 메시지 검색 조건입니다. Text 외의 조건은 비어 있으면 적용하지 않습니다.
+type SearchQuery struct {
+	Text     string
+	RoomID   string
+	AuthorID string
+	// From 이상, To 미만의 시각에 보낸 메시지
+	From time.Time
+	To   time.Time
+	// 이 메시지보다 이전 결과를 조회
+	Before uuid.UUID
+	Limit  int
+}
+
+// SearchResult는 검색된 메시지와 일치한 부분을 표시한 본문 일부입니다.
+type SearchResult struct {
+	Message Message `json:"message"`
+	// HTML 이스케이프된 본문 일부. 일치한 부분은 <mark>로 감쌈
+	Snippet string `json:"snippet"`
+}
+
+// SearchPage는 최신 순으로 정렬된 검색 결과 한 페이지입니다.
+type SearchPage struct {
+	Results []SearchResult
+	HasMore bool
+	// 다음 페이지를 조회할 때 Before로 사용할 메시지 ID. HasMore가 false이면 uuid.Nil
+	NextCursor uuid.UUID
+}
@@ -8,6 +8,8 @@ import (
 type TextMessage struct {
 	BaseMessage
 	Content string `json:"content"`
+	// 본문의 멘션 구간. 서버가 채팅방 멤버십을 확인한 뒤 저장함
+	Mentions []Mention `json:"mentions,omitempty"`
 }
 
 func (t *TextMessage) GetMessageType() string {
@@ -22,6 +24,6 @@ func (t *TextMessage) ToJson() string {
 	return string(jsonString)
 }
 
-func (t *TextMessage) FromJson(data json.RawMessage) {
-	json.Unmarshal([]byte(data), &t)
+func (t *TextMessage) FromJson(data json.RawMessage) error {
+	return json.Unmarshal([]byte(data), &t)
 }
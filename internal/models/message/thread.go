@@ -0,0 +1,53 @@
+package message
+
+import (
+	"github.com/google/uuid"
+)
+
+// 인용에 담는 텍스트 본문의 최대 길이(문자 수)
+const quotePreviewLength = 100
+
+// Quote는 답장에 인용되거나 스레드의 마지막 답장으로 보여 줄 메시지의 요약입니다.
+// 답장에는 인용한 메시지의 ID만 저장하고 나머지는 읽을 때 인용한 메시지의 최신 판으로 채웁니다.
+type Quote struct {
+	Id uuid.UUID `json:"id"`
+	// 인용한 메시지가 기록에서 사라졌으면 비어 있음
+	Author *User  `json:"author,omitempty"`
+	Type   string `json:"type,omitempty"`
+	// 텍스트 메시지 본문의 앞부분. 텍스트가 아니거나 삭제된 메시지면 비어 있음
+	Preview   string `json:"preview,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// NewQuote는 msg의 요약을 만듭니다. 텍스트 본문은 quotePreviewLength자까지만 담습니다.
+func NewQuote(msg Message) *Quote {
+	author := msg.GetAuthor()
+	quote := &Quote{
+		Id:        msg.GetID(),
+		Author:    &author,
+		Type:      msg.GetType(),
+		Timestamp: msg.GetTimestamp(),
+	}
+
+	if text, ok := msg.(*TextMessage); ok {
+		preview := []rune(text.Content)
+		if len(preview) > quotePreviewLength {
+			preview = preview[:quotePreviewLength]
+		}
+		quote.Preview = string(preview)
+	}
+	return quote
+}
+
+// QuoteOf는 id 메시지를 가리키기만 하는 인용을 만듭니다. 답장을 저장할 때 사용합니다.
+func QuoteOf(id uuid.UUID) *Quote {
+	return &Quote{Id: id}
+}
+
+// ThreadSummary는 루트 메시지에 달린 스레드의 요약입니다.
+type ThreadSummary struct {
+	// 삭제된 답장을 포함한 답장 수
+	ReplyCount int `json:"replyCount"`
+	// 가장 최근 답장. 답장이 보관 기간이 지나 사라졌으면 비어 있음
+	LastReply *Quote `json:"lastReply,omitempty"`
+}
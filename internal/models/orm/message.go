@@ -0,0 +1,52 @@
+package orm
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Message는 Redis 스트림에서 보관소로 옮긴 메시지입니다.
+// messages 테이블은 created_at 기준으로 월별 파티션을 나누므로 AutoMigrate 대신 postgres.MigrateMessageArchive로 만듭니다.
+// CreatedAt과 Seq는 스트림 항목 ID "<ms>-<seq>"이며 메시지 순서를 정합니다.
+type Message struct {
+	ID        uuid.UUID `gorm:"type:uuid;not null"`
+	RoomID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time `gorm:"primaryKey"`
+	Seq       int64     `gorm:"primaryKey"`
+	Type      string    `gorm:"type:varchar(40);not null"`
+	AuthorID  string    `gorm:"type:varchar(64)"`
+	Body      string    `gorm:"type:jsonb;not null"`
+}
+
+// MessageArchiveCheckpoint는 방의 메시지가 어느 스트림 위치까지 보관되었는지 기록합니다.
+type MessageArchiveCheckpoint struct {
+	RoomID     uuid.UUID `gorm:"type:uuid;primaryKey"`
+	LastMillis int64     `gorm:"not null"`
+	LastSeq    int64     `gorm:"not null"`
+	UpdatedAt  time.Time
+}
+
+// MessageRevision은 보관된 메시지의 수정 전 판입니다. Seq는 메시지의 수정 기록에서의 순서입니다.
+// Redis의 수정 기록은 메시지가 스트림에서 잘려 나간 뒤 보관기가 옮깁니다.
+type MessageRevision struct {
+	RoomID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	MessageID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Seq       int       `gorm:"primaryKey"`
+	Content   string    `gorm:"not null"`
+	Timestamp string    `gorm:"type:varchar(40);not null"`
+}
+
+// HiddenMessage는 사용자가 자신에게만 숨긴 보관된 메시지입니다.
+type HiddenMessage struct {
+	RoomID    uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID    string    `gorm:"type:varchar(64);primaryKey"`
+	MessageID uuid.UUID `gorm:"type:uuid;primaryKey"`
+}
+
+// ThreadParticipant는 루트 메시지가 스트림에서 잘려 나간 스레드의 참여자입니다.
+type ThreadParticipant struct {
+	RoomID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	RootID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	UserID string    `gorm:"type:varchar(64);primaryKey"`
+}
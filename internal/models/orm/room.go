@@ -20,4 +20,15 @@ type RoomUser struct {
 	RoomID     uuid.UUID `gorm:"type:uuid; not null;"`
 	UserID     uuid.UUID `gorm:"type:uuid;not null"`
 	StartIndex int       `gorm:"type:integer;not null;default:0"`
+	// 멤버가 마지막으로 읽은 메시지와 마지막으로 소켓에 전달된 메시지의 ID.
+	// UUIDv7이므로 ID 순서가 메시지 순서이며, 두 커서는 앞으로만 움직임
+	LastReadMessageID      *uuid.UUID `gorm:"type:uuid"`
+	LastDeliveredMessageID *uuid.UUID `gorm:"type:uuid"`
+}
+
+// UserRoom은 사용자가 속한 방과 그 방에서 사용자의 읽음 커서, 방의 멤버 수입니다.
+type UserRoom struct {
+	Room
+	LastReadMessageID *uuid.UUID
+	MemberCount       int
 }
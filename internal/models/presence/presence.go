@@ -0,0 +1,37 @@
+package presence
+
+import "time"
+
+// Status는 사용자의 접속 상태입니다.
+type Status string
+
+const (
+	// 자리 비움이 아닌 연결이 하나 이상 있음
+	StatusOnline Status = "online"
+	// 연결이 있지만 모두 자리 비움 상태
+	StatusAway Status = "away"
+	// 살아 있는 연결이 없음
+	StatusOffline Status = "offline"
+)
+
+// Valid는 클라이언트가 연결의 상태로 지정할 수 있는 값인지 확인합니다. offline은 연결을 끊어서만 될 수 있습니다.
+func (s Status) Valid() bool {
+	return s == StatusOnline || s == StatusAway
+}
+
+// Record는 저장소에 기록된 사용자의 접속 상태와 공개 설정입니다.
+type Record struct {
+	Status Status
+	// 마지막으로 연결이 살아 있던 시각. 접속한 적이 없으면 zero
+	LastSeen time.Time
+	// 다른 사용자에게 마지막 접속 시각을 숨길지 여부
+	HideLastSeen bool
+}
+
+// Presence는 다른 사용자에게 보여 줄 접속 상태입니다.
+type Presence struct {
+	UserId string `json:"userId"`
+	Status Status `json:"status"`
+	// 마지막 접속 시각. 접속한 적이 없거나 사용자가 숨겼으면 없음
+	LastSeen *time.Time `json:"lastSeen,omitempty"`
+}
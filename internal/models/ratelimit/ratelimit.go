@@ -0,0 +1,37 @@
+package ratelimit
+
+import "time"
+
+// Limit은 토큰 버킷의 크기와 채워지는 속도입니다. Burst나 Rate가 0 이하이면 제한하지 않습니다.
+type Limit struct {
+	// 버킷에 쌓일 수 있는 최대 토큰 수. 한꺼번에 보낼 수 있는 횟수
+	Burst int
+	// 초당 채워지는 토큰 수. 오래 유지할 수 있는 초당 전송 횟수
+	Rate float64
+}
+
+// Enabled는 제한이 설정되어 있는지 여부입니다.
+func (l Limit) Enabled() bool {
+	return l.Burst > 0 && l.Rate > 0
+}
+
+// Reason은 요청이 제한된 이유입니다.
+type Reason string
+
+const (
+	// 사용자의 버킷이 비어 있음
+	ReasonUser Reason = "user"
+	// 채팅방의 버킷이 비어 있음
+	ReasonRoom Reason = "room"
+	// 제한을 반복해서 넘겨 사용자가 일시적으로 차단됨
+	ReasonBanned Reason = "banned"
+)
+
+// Result는 토큰을 가져간 결과입니다.
+type Result struct {
+	Allowed bool
+	// 제한된 이유. 허용되었으면 비어 있음
+	Reason Reason
+	// 제한되었을 때 다시 보낼 수 있을 때까지 남은 시간
+	RetryAfter time.Duration
+}
@@ -0,0 +1,48 @@
+// Package protocol은 채팅 WebSocket 프로토콜의 스키마와 오류 코드를 정의합니다.
+// 스키마 원본은 chat.v1.schema.json이며, 서버는 받은 프레임을 이 스키마로 검증합니다.
+package protocol
+
+// Version은 서버가 사용하는 프로토콜 스키마의 버전입니다.
+const Version = 1
+
+// error 이벤트의 code 값
+const (
+	// JSON이 아니거나 스키마에 맞지 않는 프레임
+	ErrorCodeBadFrame = "bad_frame"
+	// 스키마에 정의되지 않은 type
+	ErrorCodeUnknownType = "unknown_type"
+	// 채팅방 멤버가 아님
+	ErrorCodeNotMember = "not_member"
+	// 구독하지 않은 채팅방에 보낸 프레임
+	ErrorCodeNotSubscribed = "not_subscribed"
+	// 전송 빈도 제한을 넘음
+	ErrorCodeRateLimited = "rate_limited"
+	// 메시지 저장 실패. 같은 clientMessageId로 재시도할 수 있음
+	ErrorCodeStorageFailure = "storage_failure"
+	// 같은 clientMessageId의 메시지를 아직 저장 중. 잠시 뒤 같은 ID로 다시 보내면 결과를 받을 수 있음
+	ErrorCodeSendPending = "send_pending"
+	// 채팅방에 없는 메시지
+	ErrorCodeMessageNotFound = "message_not_found"
+	// 작성자만 할 수 있는 작업
+	ErrorCodeNotAuthor = "not_author"
+	// 수정할 수 있는 기간이 지남
+	ErrorCodeEditWindowExpired = "edit_window_expired"
+	// 수정할 수 없는 메시지 타입
+	ErrorCodeNotEditable = "not_editable"
+	// 모두에게서 삭제할 수 있는 기간이 지남
+	ErrorCodeDeleteWindowExpired = "delete_window_expired"
+	// 한 메시지에 남길 수 있는 서로 다른 반응 수를 넘음
+	ErrorCodeTooManyReactions = "too_many_reactions"
+	// 멘션 구간이 본문과 맞지 않거나 멘션한 사용자가 채팅방 멤버가 아님
+	ErrorCodeInvalidMention = "invalid_mention"
+)
+
+// Error는 클라이언트에게 error 이벤트로 전달할 수 있는 프로토콜 오류입니다.
+type Error struct {
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
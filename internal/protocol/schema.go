@@ -0,0 +1,204 @@
+package protocol
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+//go:embed chat.v1.schema.json
+var schemaJSON []byte
+
+// schema는 프로토콜 스키마에서 사용하는 JSON Schema 키워드의 부분 집합입니다.
+// 스키마에 새 키워드를 쓰려면 여기와 validate에 함께 추가해야 합니다.
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Required   []string           `json:"required"`
+	Properties map[string]*schema `json:"properties"`
+	OneOf      []*schema          `json:"oneOf"`
+	Defs       map[string]*schema `json:"$defs"`
+	Const      interface{}        `json:"const"`
+	Enum       []interface{}      `json:"enum"`
+	Format     string             `json:"format"`
+	MinLength  *int               `json:"minLength"`
+	MaxLength  *int               `json:"maxLength"`
+	Items      *schema            `json:"items"`
+	MaxItems   *int               `json:"maxItems"`
+	Minimum    *float64           `json:"minimum"`
+
+	Version int `json:"x-protocol-version"`
+}
+
+var (
+	root *schema
+	// 프레임 type -> 해당 프레임의 스키마. 최상위 oneOf에서 만듦
+	frameSchemas map[string]*schema
+)
+
+func init() {
+	if err := json.Unmarshal(schemaJSON, &root); err != nil {
+		panic("protocol: invalid schema: " + err.Error())
+	}
+	if root.Version != Version {
+		panic(fmt.Sprintf("protocol: schema version %d does not match Version %d", root.Version, Version))
+	}
+
+	frameSchemas = make(map[string]*schema, len(root.OneOf))
+	for _, ref := range root.OneOf {
+		frame := resolve(ref)
+		frameType, ok := frame.Properties["type"].Const.(string)
+		if !ok {
+			panic("protocol: frame schema without type const: " + ref.Ref)
+		}
+		frameSchemas[frameType] = frame
+	}
+}
+
+// resolve는 "#/$defs/이름" 형태의 참조를 따라갑니다.
+func resolve(s *schema) *schema {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/$defs/")
+		def, ok := root.Defs[name]
+		if !ok {
+			panic("protocol: unknown schema reference: " + s.Ref)
+		}
+		s = def
+	}
+	return s
+}
+
+// Validate는 클라이언트가 보낸 프레임을 스키마로 검증합니다.
+// 검증에 실패하면 error 이벤트로 그대로 보낼 수 있는 *Error를 반환합니다.
+func Validate(data []byte) error {
+	var frame map[string]interface{}
+	if err := json.Unmarshal(data, &frame); err != nil {
+		return &Error{Code: ErrorCodeBadFrame, Message: "frame is not a JSON object"}
+	}
+
+	frameType, ok := frame["type"].(string)
+	if !ok {
+		return &Error{Code: ErrorCodeBadFrame, Message: "type must be a string"}
+	}
+
+	s, ok := frameSchemas[frameType]
+	if !ok {
+		return &Error{Code: ErrorCodeUnknownType, Message: "unknown frame type: " + frameType}
+	}
+
+	if msg := validate(s, frame, ""); msg != "" {
+		return &Error{Code: ErrorCodeBadFrame, Message: msg}
+	}
+	return nil
+}
+
+// validate는 값이 스키마에 맞으면 빈 문자열을, 아니면 위반 내용을 반환합니다.
+func validate(s *schema, value interface{}, path string) string {
+	s = resolve(s)
+
+	if s.Const != nil && !reflect.DeepEqual(s.Const, value) {
+		return fmt.Sprintf("%s must be %v", fieldName(path), s.Const)
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, allowed := range s.Enum {
+			if reflect.DeepEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%s must be one of %v", fieldName(path), s.Enum)
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fieldName(path) + " must be an object"
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return joinPath(path, name) + " is required"
+			}
+		}
+		for name, property := range s.Properties {
+			if v, ok := object[name]; ok {
+				if msg := validate(property, v, joinPath(path, name)); msg != "" {
+					return msg
+				}
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fieldName(path) + " must be a string"
+		}
+		length := utf8.RuneCountInString(str)
+		if s.MinLength != nil && length < *s.MinLength {
+			return fmt.Sprintf("%s must be at least %d characters", fieldName(path), *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return fmt.Sprintf("%s must be at most %d characters", fieldName(path), *s.MaxLength)
+		}
+		if s.Format == "uuid" {
+			if _, err := uuid.Parse(str); err != nil {
+				return fieldName(path) + " must be a UUID"
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fieldName(path) + " must be an array"
+		}
+		if s.MaxItems != nil && len(array) > *s.MaxItems {
+			return fmt.Sprintf("%s must have at most %d items", fieldName(path), *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range array {
+				if msg := validate(s.Items, item, fmt.Sprintf("%s[%d]", fieldName(path), i)); msg != "" {
+					return msg
+				}
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fieldName(path) + " must be a boolean"
+		}
+	case "number", "integer":
+		number, ok := value.(float64)
+		if !ok {
+			return fieldName(path) + " must be a number"
+		}
+		if s.Type == "integer" && number != math.Trunc(number) {
+			return fieldName(path) + " must be an integer"
+		}
+		if s.Minimum != nil && number < *s.Minimum {
+			return fmt.Sprintf("%s must be at least %v", fieldName(path), *s.Minimum)
+		}
+	}
+
+	return ""
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func fieldName(path string) string {
+	if path == "" {
+		return "frame"
+	}
+	return path
+}
@@ -2,12 +2,31 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"server/internal/models/message"
 	"server/internal/models/orm"
+	"server/internal/models/presence"
+	"server/internal/models/ratelimit"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrInvalidMessageID는 메시지 ID가 UUIDv7이 아니어서 스트림에서의 위치를 정할 수 없을 때 반환됩니다.
+var ErrInvalidMessageID = errors.New("message ID must be a UUIDv7")
+
+// ErrMessageNotFound는 방에 해당 ID의 메시지가 없을 때 반환됩니다.
+var ErrMessageNotFound = errors.New("message not found")
+
+// ErrTooManyReactions는 사용자가 한 메시지에 남길 수 있는 서로 다른 반응 수를 넘을 때 반환됩니다.
+var ErrTooManyReactions = errors.New("too many reactions on the message")
+
+// ErrClaimPending은 선점된 클라이언트 메시지 ID의 메시지가 아직 저장 중일 때 반환됩니다.
+var ErrClaimPending = errors.New("message with this client message ID is still being saved")
+
+// ErrRoomListNotFound는 사용자의 채팅방 목록이 아직 만들어지지 않았을 때 반환됩니다.
+var ErrRoomListNotFound = errors.New("room list not found")
+
 type UserRepository interface {
 	FindByID(ctx context.Context, id uuid.UUID) (orm.User, error)
 	FindByPhoneNumber(ctx context.Context, phoneNumber string) (orm.User, error)
@@ -22,6 +41,12 @@ type FriendRepository interface {
 	BlockFriend(ctx context.Context, userID, friendID uuid.UUID) error
 	UnblockFriend(ctx context.Context, userID, friendID uuid.UUID) error
 	CheckIfFriendExists(ctx context.Context, userID, friendID uuid.UUID) (bool, error)
+	// GetAddedByUserIDs는 friendID 사용자를 친구로 추가한 사용자 ID를 반환합니다.
+	// 어느 한쪽이라도 상대를 차단했으면 빠집니다.
+	GetAddedByUserIDs(ctx context.Context, friendID uuid.UUID) ([]uuid.UUID, error)
+	// GetAddedFriendIDs는 friendIDs 중 userID 사용자가 친구로 추가한 사용자 ID를 반환합니다.
+	// 어느 한쪽이라도 상대를 차단했으면 빠집니다.
+	GetAddedFriendIDs(ctx context.Context, userID uuid.UUID, friendIDs []uuid.UUID) ([]uuid.UUID, error)
 }
 
 type RoomRepository interface {
@@ -31,12 +56,204 @@ type RoomRepository interface {
 	AddUserToRoom(ctx context.Context, roomID, userID uuid.UUID) error
 	RemoveUserFromRoom(ctx context.Context, roomID, userID uuid.UUID) error
 	CreateRoomWithUsers(ctx context.Context, roomName string, userIDs []uuid.UUID) (uuid.UUID, error)
+	IsUserInRoom(ctx context.Context, roomID, userID uuid.UUID) (bool, error)
+	GetRoomUserIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error)
+	// GetRoommateIDs는 userIDs 중 userID 사용자와 같은 방에 속한 사용자 ID를 반환합니다.
+	GetRoommateIDs(ctx context.Context, userID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error)
+	// AdvanceReadCursor와 AdvanceDeliveryCursor는 멤버의 읽음/전달 커서를 messageID로 옮깁니다.
+	// 커서는 앞으로만 움직이며, 옮겼으면 true를 반환합니다.
+	AdvanceReadCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error)
+	AdvanceDeliveryCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error)
+	// GetReadCursors는 사용자 ID -> 마지막으로 읽은 메시지 ID를 반환합니다. 읽은 메시지가 없는 멤버는 빠집니다.
+	GetReadCursors(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]uuid.UUID, error)
+	// GetUserRoomMemberships는 사용자가 속한 방을 사용자의 읽음 커서, 방의 멤버 수와 함께 반환합니다.
+	GetUserRoomMemberships(ctx context.Context, userID uuid.UUID) ([]orm.UserRoom, error)
+	// GetUserRoomMembershipsByID는 roomIDs 중 사용자가 속한 방만 GetUserRoomMemberships와 같은 형태로 반환합니다.
+	GetUserRoomMembershipsByID(ctx context.Context, userID uuid.UUID, roomIDs []uuid.UUID) ([]orm.UserRoom, error)
 }
 
 type MessageRepository interface {
 	SaveMessage(ctx context.Context, roomID string, msg message.Message) error
-	GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error)
-	GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error)
+	// GetMessages는 lastMessageID(밀리초 타임스탬프) 이후의 메시지를 오래된 순으로 최대 limit개 반환합니다.
+	GetMessages(ctx context.Context, roomID string, lastMessageID int64, limit int) (message.Page, error)
+	// GetMessagesByUUID는 lastMessageUUID 이후의 메시지를 오래된 순으로 최대 limit개 반환합니다.
+	GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID, limit int) (message.Page, error)
+	ListMessages(ctx context.Context, roomID string, query message.PageQuery) (message.Page, error)
+	// GetMessage는 메시지의 최신 판을 반환합니다. 없으면 ErrMessageNotFound를 반환합니다.
+	GetMessage(ctx context.Context, roomID string, id uuid.UUID) (message.Message, error)
+	// GetMessagesByID는 ids 메시지의 최신 판을 ID별로 한꺼번에 읽습니다. 없는 메시지는 결과에 없습니다.
+	GetMessagesByID(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.Message, error)
+	// EditMessage는 수정된 메시지를 최신 판으로 저장하고 수정 전 판을 기록에 추가합니다.
+	// 이후 메시지 조회는 모두 최신 판을 반환합니다. 그 사이 모두에게서 삭제된 메시지면 ErrMessageNotFound를 반환합니다.
+	EditMessage(ctx context.Context, roomID string, edited message.Message, previous message.Revision) error
+	// GetRevisions는 메시지의 수정 전 판을 오래된 순으로 반환합니다.
+	GetRevisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error)
+	// DeleteMessage는 메시지를 툼스톤으로 바꾸고 원래 본문과 수정 전 판을 지웁니다.
+	// 이후 메시지 조회는 원래 자리에 툼스톤을 반환합니다.
+	DeleteMessage(ctx context.Context, roomID string, tombstone *message.DeletedMessage) error
+	// HideMessage는 사용자에게만 메시지를 숨깁니다.
+	HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error
+	// HiddenMessages는 ids 중 사용자가 숨긴 메시지 ID를 반환합니다.
+	HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+	// AddReaction은 사용자의 반응을 추가하고 메시지의 반응 집계를 반환합니다. 이미 남긴 반응이면 changed가 false입니다.
+	// 사용자가 이미 서로 다른 반응을 limit개 남겼으면 ErrTooManyReactions를 반환합니다.
+	AddReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string, limit int) (reactions []message.Reaction, changed bool, err error)
+	// RemoveReaction은 사용자의 반응을 지우고 메시지의 반응 집계를 반환합니다. 남기지 않은 반응이면 changed가 false입니다.
+	RemoveReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string) (reactions []message.Reaction, changed bool, err error)
+	// GetReactions는 메시지별 반응 집계를 반환합니다. 반응이 없는 메시지는 결과에 없습니다.
+	GetReactions(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID][]message.Reaction, error)
+	// GetThread는 루트 메시지에 달린 답장을 커서 기준으로 최대 query.Limit개, 오래된 순서로 반환합니다.
+	// 삭제된 답장은 툼스톤으로 반환하고, 보관 기간이 지나 사라진 답장은 건너뜁니다.
+	GetThread(ctx context.Context, roomID string, rootID uuid.UUID, query message.PageQuery) (message.Page, error)
+	// GetThreadSummaries는 ids 중 답장이 달린 메시지의 스레드 요약을 반환합니다.
+	GetThreadSummaries(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.ThreadSummary, error)
+	// JoinThread는 사용자를 스레드 참여자로 등록합니다. 답장을 저장하면 작성자는 자동으로 참여자가 됩니다.
+	JoinThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error
+	// LeaveThread는 사용자를 스레드 참여자에서 뺍니다.
+	LeaveThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error
+	// ThreadParticipants는 스레드 참여자 ID를 반환합니다. 스레드가 없으면 빈 목록입니다.
+	ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error)
+}
+
+// MessageArchiveRepository는 Redis 스트림에서 잘려 나가는 메시지를 영구 보관합니다.
+type MessageArchiveRepository interface {
+	// Archive는 메시지를 보관하고, 같은 트랜잭션에서 방의 보관 체크포인트를 checkpoint로 옮깁니다.
+	Archive(ctx context.Context, roomID string, messages []StreamMessage, checkpoint StreamPosition) error
+	// Checkpoint는 보관이 확인된 마지막 위치를 반환합니다. 보관한 적이 없으면 0 위치입니다.
+	Checkpoint(ctx context.Context, roomID string) (StreamPosition, error)
+	// Position은 보관된 메시지의 위치를 찾습니다. 보관되지 않은 메시지면 found가 false입니다.
+	Position(ctx context.Context, roomID string, id uuid.UUID) (position StreamPosition, found bool, err error)
+	// Find는 보관된 메시지 중 ids에 해당하는 메시지를 반환합니다. 보관되지 않은 메시지는 결과에 없습니다.
+	Find(ctx context.Context, roomID string, ids []uuid.UUID) ([]StreamMessage, error)
+	// ListBefore는 before보다 앞선 메시지를 최신 순으로 최대 limit개 반환합니다.
+	ListBefore(ctx context.Context, roomID string, before StreamPosition, limit int) ([]StreamMessage, error)
+	// ListAfter는 after보다 뒤의 메시지를 오래된 순으로 최대 limit개 반환합니다.
+	ListAfter(ctx context.Context, roomID string, after StreamPosition, limit int) ([]StreamMessage, error)
+	// UpdateMessage는 보관된 메시지를 msg로 바꿉니다. 타입이 바뀌면 타입도 바꾸며, 툼스톤으로 바꾸면 보관된 수정 기록도 지웁니다.
+	// 이미 삭제된 메시지는 바꾸지 않고 ErrMessageNotFound를 반환합니다. 아직 보관되지 않은 메시지면 아무것도 하지 않습니다.
+	UpdateMessage(ctx context.Context, roomID string, msg message.Message) error
+
+	// 아래 메서드는 스트림에서 잘려 나간 메시지의 상태를 Redis 대신 보관합니다.
+
+	// AppendRevisions는 메시지의 보관된 수정 기록 끝에 revisions를 덧붙입니다. 기록이 이미 revisions로 끝나면 덧붙이지 않습니다.
+	AppendRevisions(ctx context.Context, roomID string, id uuid.UUID, revisions []message.Revision) error
+	// Revisions는 메시지의 보관된 수정 기록을 오래된 순으로 반환합니다.
+	Revisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error)
+	// HideMessages는 사용자가 숨긴 메시지를 기록합니다.
+	HideMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) error
+	// HiddenMessages는 ids 중 기록된 숨긴 메시지 ID를 반환합니다.
+	HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error)
+	// ThreadReplies는 before보다 앞선 위치에 보관된 rootID 스레드의 답장 ID를 커서 기준으로 최대 query.Limit개 반환합니다.
+	// query.After가 있으면 오래된 순으로, 없으면 최신 순으로 반환합니다.
+	ThreadReplies(ctx context.Context, roomID string, rootID uuid.UUID, before StreamPosition, query message.PageQuery) ([]uuid.UUID, error)
+	// ThreadCounts는 before보다 앞선 위치에 보관된 답장을 rootIDs 스레드별로 셉니다. 답장이 없는 스레드는 결과에 없습니다.
+	ThreadCounts(ctx context.Context, roomID string, rootIDs []uuid.UUID, before StreamPosition) (map[uuid.UUID]ThreadCount, error)
+	// AddThreadParticipants는 스레드 참여자를 기록합니다.
+	AddThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID, userIDs []string) error
+	// RemoveThreadParticipant는 기록된 스레드 참여자를 지웁니다.
+	RemoveThreadParticipant(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error
+	// ThreadParticipants는 기록된 스레드 참여자 ID를 반환합니다.
+	ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error)
+}
+
+// ThreadCount는 보관된 스레드 답장의 수와 가장 최근 답장의 ID입니다.
+type ThreadCount struct {
+	Count     int
+	LastReply uuid.UUID
+}
+
+// MessageSearchRepository는 보관된 메시지를 사용자가 속한 방 안에서 검색합니다.
+type MessageSearchRepository interface {
+	// SearchMessages는 조건에 맞는 메시지를 최신 순으로 최대 query.Limit개 반환합니다. Snippet은 채우지 않습니다.
+	SearchMessages(ctx context.Context, userID uuid.UUID, query message.SearchQuery) (message.SearchPage, error)
+}
+
+// MentionRepository는 사용자마다 멘션된 메시지 목록과 방별 읽지 않은 멘션 수를 기록합니다.
+type MentionRepository interface {
+	// AddMention은 userIDs 사용자의 멘션 목록에 메시지를 추가하고 방의 읽지 않은 멘션 수를 늘립니다.
+	AddMention(ctx context.Context, roomID string, messageID uuid.UUID, userIDs []string) error
+	// ListMentions는 before보다 이전 멘션을 최신 순으로 최대 limit개 반환합니다. before가 uuid.Nil이면 가장 최근부터 반환합니다.
+	ListMentions(ctx context.Context, userID string, before uuid.UUID, limit int) ([]message.MentionRef, error)
+	// UnreadCounts는 방 ID별 읽지 않은 멘션 수를 반환합니다. 읽지 않은 멘션이 없는 방은 결과에 없습니다.
+	UnreadCounts(ctx context.Context, userID string) (map[string]int, error)
+	// ClearUnread는 방의 읽지 않은 멘션 수를 0으로 만듭니다.
+	ClearUnread(ctx context.Context, userID, roomID string) error
+}
+
+// RoomSummaryRepository는 채팅방 목록을 위해 방마다 마지막 메시지 요약과 최근 메시지 ID를 비정규화해 두고,
+// 사용자마다 속한 방을 최근 활동 순서로 정렬해 둡니다.
+type RoomSummaryRepository interface {
+	// RecordMessage는 저장된 메시지를 방의 요약과 멤버들의 채팅방 목록에 반영합니다. 메시지 ID는 UUIDv7이어야 합니다.
+	RecordMessage(ctx context.Context, roomID string, msg message.Message) error
+	// RefreshLastMessage는 msg가 방의 마지막 메시지일 때만 요약을 msg로 바꿉니다. 수정되거나 삭제된 메시지에 사용합니다.
+	RefreshLastMessage(ctx context.Context, roomID string, msg message.Message) error
+	// RemoveMessage는 모두에게서 삭제된 메시지를 읽지 않은 메시지 수에서 뺍니다.
+	RemoveMessage(ctx context.Context, roomID string, id uuid.UUID) error
+	// HideMessage는 사용자가 숨긴 메시지를 그 사용자의 읽지 않은 메시지 수에서 뺍니다.
+	HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error
+	// GetSummaries는 방 ID -> 사용자의 읽음 커서를 받아 방 ID -> 요약을 반환합니다.
+	// 커서가 uuid.Nil이면 아무 메시지도 읽지 않은 것으로 보며, 메시지가 없는 방은 결과에 없습니다.
+	GetSummaries(ctx context.Context, userID string, readCursors map[string]uuid.UUID) (map[string]message.RoomSummary, error)
+	// BuildRoomList는 사용자의 채팅방 목록을 방 ID -> 방을 만든 시각으로 새로 채웁니다.
+	// 이후 AddMember, RemoveMember와 RecordMessage가 목록을 최신으로 유지합니다.
+	BuildRoomList(ctx context.Context, userID string, rooms map[string]time.Time) error
+	// AddMember는 사용자의 채팅방 목록에 방을 넣습니다. 목록이 아직 없으면 BuildRoomList에 맡기고 아무것도 하지 않습니다.
+	AddMember(ctx context.Context, roomID, userID string, createdAt time.Time) error
+	// RemoveMember는 사용자의 채팅방 목록에서 방을 뺍니다.
+	RemoveMember(ctx context.Context, roomID, userID string) error
+	// ListRooms는 사용자의 채팅방 목록에서 after 뒤의 방을 최대 limit개 반환합니다. after가 nil이면 처음부터 조회합니다.
+	// 목록이 없으면 ErrRoomListNotFound를 반환합니다.
+	ListRooms(ctx context.Context, userID string, after *message.RoomActivity, limit int) ([]message.RoomActivity, error)
+}
+
+// PresenceRepository는 연결(세션)마다 만료 시각이 있는 하트비트로 사용자의 접속 상태를 기록합니다.
+// 하트비트가 ttl 안에 갱신되지 않은 세션은 끊긴 것으로 봅니다.
+type PresenceRepository interface {
+	// Heartbeat는 세션이 살아 있음과 세션의 상태(online 또는 away)를 기록하고, 기록 전후 사용자의 상태를 반환합니다.
+	Heartbeat(ctx context.Context, userID, sessionID string, status presence.Status, now time.Time, ttl time.Duration) (before, after presence.Status, err error)
+	// RemoveSession은 끊긴 세션을 지우고, 지우기 전후 사용자의 상태를 반환합니다.
+	RemoveSession(ctx context.Context, userID, sessionID string, now time.Time) (before, after presence.Status, err error)
+	// GetPresence는 사용자 ID -> 접속 상태를 반환합니다. 접속한 적 없는 사용자도 offline으로 포함됩니다.
+	GetPresence(ctx context.Context, userIDs []string, now time.Time) (map[string]presence.Record, error)
+	// SetHideLastSeen은 다른 사용자에게 마지막 접속 시각을 숨길지 설정합니다.
+	SetHideLastSeen(ctx context.Context, userID string, hide bool) error
+}
+
+// TypingRepository는 채팅방마다 타이핑 중인 사용자와 타이핑 상태의 만료 시각을 기록합니다.
+// 모든 메서드는 변경 뒤 만료되지 않은 타이핑 중인 사용자 ID와, 사용자의 타이핑 여부가 바뀌었는지를 반환합니다.
+type TypingRepository interface {
+	// StartTyping은 사용자의 타이핑 상태를 expiresAt까지 유지합니다. 이미 타이핑 중이었으면 만료 시각만 늘립니다.
+	StartTyping(ctx context.Context, roomID, userID string, now, expiresAt time.Time) (typing []string, changed bool, err error)
+	// StopTyping은 사용자의 타이핑 상태를 지웁니다.
+	StopTyping(ctx context.Context, roomID, userID string, now time.Time) (typing []string, changed bool, err error)
+	// ExpireTyping은 사용자의 타이핑 상태가 now까지 갱신되지 않았을 때만 지웁니다.
+	// 다른 노드를 거쳐 갱신되었으면 그대로 둡니다.
+	ExpireTyping(ctx context.Context, roomID, userID string, now time.Time) (typing []string, changed bool, err error)
+}
+
+// RateLimitRepository는 사용자와 채팅방마다 메시지 전송량을 토큰 버킷으로 제한하고,
+// 제한을 반복해서 넘긴 사용자를 일시적으로 차단합니다.
+type RateLimitRepository interface {
+	// TakeMessageToken은 사용자와 채팅방의 버킷에서 토큰을 하나씩 가져갑니다.
+	// 사용자가 차단되어 있거나 어느 한쪽 버킷이라도 비어 있으면 어느 버킷에서도 가져가지 않습니다.
+	TakeMessageToken(ctx context.Context, userID, roomID string, userLimit, roomLimit ratelimit.Limit, now time.Time) (ratelimit.Result, error)
+	// RecordViolation은 사용자가 제한을 넘긴 횟수를 window 동안 셉니다.
+	// strikes번에 이르면 횟수를 초기화하고 사용자를 banFor 동안 차단한 뒤 true를 반환합니다.
+	RecordViolation(ctx context.Context, userID string, now time.Time, window time.Duration, strikes int, banFor time.Duration) (banned bool, err error)
+	// BannedUntil은 사용자의 차단이 끝나는 시각을 반환합니다. 차단되어 있지 않으면 zero를 반환합니다.
+	BannedUntil(ctx context.Context, userID string, now time.Time) (time.Time, error)
+}
+
+// MessageDedupRepository는 클라이언트가 재시도한 메시지를 구분하기 위해 클라이언트 메시지 ID를 기록합니다.
+type MessageDedupRepository interface {
+	// Claim은 사용자의 클라이언트 메시지 ID를 저장 중 상태로 ttl 동안 선점합니다.
+	// 이미 저장이 끝난 ID라면 claimed가 false이고 처음 기록된 Receipt를 반환하며,
+	// 처음 메시지가 아직 저장 중이면 ErrClaimPending을 반환합니다.
+	Claim(ctx context.Context, userID, clientMessageID string, receipt message.Receipt, ttl time.Duration) (original message.Receipt, claimed bool, err error)
+	// Commit은 저장이 끝난 선점을 저장 완료 상태로 바꿔 ttl 동안 유지합니다.
+	Commit(ctx context.Context, userID, clientMessageID string, ttl time.Duration) error
+	// Release는 저장에 실패한 메시지를 다시 보낼 수 있도록 선점을 해제합니다.
+	Release(ctx context.Context, userID, clientMessageID string) error
 }
 
 type AuthRepository interface {
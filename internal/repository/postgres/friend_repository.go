@@ -66,3 +66,24 @@ func (r *PostgresFriendRepository) CheckIfFriendExists(ctx context.Context, user
 	}
 	return true, nil
 }
+
+func (r *PostgresFriendRepository) GetAddedByUserIDs(ctx context.Context, friendID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	result := r.db.WithContext(ctx).Model(&orm.Friend{}).
+		Where("friend_id = ? AND is_blocked = ?", friendID, false).
+		Where("NOT EXISTS (SELECT 1 FROM friends AS reverse WHERE reverse.user_id = friends.friend_id AND reverse.friend_id = friends.user_id AND reverse.is_blocked AND reverse.deleted_at IS NULL)").
+		Pluck("user_id", &userIDs)
+	return userIDs, result.Error
+}
+
+func (r *PostgresFriendRepository) GetAddedFriendIDs(ctx context.Context, userID uuid.UUID, friendIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var addedIDs []uuid.UUID
+	if len(friendIDs) == 0 {
+		return addedIDs, nil
+	}
+	result := r.db.WithContext(ctx).Model(&orm.Friend{}).
+		Where("user_id = ? AND friend_id IN ? AND is_blocked = ?", userID, friendIDs, false).
+		Where("NOT EXISTS (SELECT 1 FROM friends AS reverse WHERE reverse.user_id = friends.friend_id AND reverse.friend_id = friends.user_id AND reverse.is_blocked AND reverse.deleted_at IS NULL)").
+		Pluck("friend_id", &addedIDs)
+	return addedIDs, result.Error
+}
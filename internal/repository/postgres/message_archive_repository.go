@@ -0,0 +1,481 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"server/internal/models/message"
+	"server/internal/models/orm"
+	"server/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MigrateMessageArchive는 월별 파티션으로 나뉜 messages 테이블과 검색 인덱스, 보관 체크포인트 테이블,
+// 스트림에서 잘려 나간 메시지의 수정 기록, 숨김, 스레드 참여자 테이블을 만듭니다.
+// 파티션 테이블은 AutoMigrate로 만들 수 없으므로 직접 생성하며, 월별 파티션은 보관할 때 필요에 따라 추가됩니다.
+func MigrateMessageArchive(db *gorm.DB) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS messages (
+			id uuid NOT NULL,
+			room_id uuid NOT NULL,
+			created_at timestamptz NOT NULL,
+			seq bigint NOT NULL,
+			type varchar(40) NOT NULL,
+			author_id varchar(64),
+			body jsonb NOT NULL,
+			PRIMARY KEY (room_id, created_at, seq)
+		) PARTITION BY RANGE (created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_id ON messages (id)`,
+		// 검색용 인덱스. 단어 일치는 tsvector, 한국어 부분 일치는 트라이그램으로 찾음
+		`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+		`ALTER TABLE messages ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', coalesce(body->>'content', ''))) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_search_vector ON messages USING gin (search_vector)`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_content_trgm ON messages USING gin ((body->>'content') gin_trgm_ops)`,
+		// 스트림에서 잘려 나간 스레드의 답장은 thread_id로 찾음
+		`ALTER TABLE messages ADD COLUMN IF NOT EXISTS thread_id uuid
+			GENERATED ALWAYS AS ((body->>'threadId')::uuid) STORED`,
+		`CREATE INDEX IF NOT EXISTS idx_messages_thread ON messages (room_id, thread_id, id) WHERE thread_id IS NOT NULL`,
+	}
+	for _, statement := range statements {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+
+	return db.AutoMigrate(&orm.MessageArchiveCheckpoint{}, &orm.MessageRevision{}, &orm.HiddenMessage{}, &orm.ThreadParticipant{})
+}
+
+type PostgresMessageArchiveRepository struct {
+	db *gorm.DB
+	// partitions는 이미 만든 월별 파티션 이름입니다.
+	partitions sync.Map
+}
+
+func NewPostgresMessageArchiveRepository(db *gorm.DB) repository.MessageArchiveRepository {
+	return &PostgresMessageArchiveRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresMessageArchiveRepository) Archive(ctx context.Context, roomID string, messages []repository.StreamMessage, checkpoint repository.StreamPosition) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]orm.Message, 0, len(messages))
+	for _, entry := range messages {
+		body, err := json.Marshal(entry.Message)
+		if err != nil {
+			return err
+		}
+
+		createdAt := positionTime(entry.Position)
+		if err := r.ensurePartition(ctx, createdAt); err != nil {
+			return err
+		}
+
+		rows = append(rows, orm.Message{
+			ID:        entry.Message.GetID(),
+			RoomID:    roomUUID,
+			CreatedAt: createdAt,
+			Seq:       entry.Position.Seq,
+			Type:      entry.Message.GetType(),
+			AuthorID:  entry.Message.GetAuthor().Id,
+			Body:      string(body),
+		})
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(rows) > 0 {
+			// 체크포인트 커밋 전에 중단되어 다시 보관하는 항목은 건너뜀
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 100)
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "room_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"last_millis", "last_seq", "updated_at"}),
+		}).Create(&orm.MessageArchiveCheckpoint{
+			RoomID:     roomUUID,
+			LastMillis: checkpoint.Millis,
+			LastSeq:    checkpoint.Seq,
+		}).Error
+	})
+}
+
+func (r *PostgresMessageArchiveRepository) Checkpoint(ctx context.Context, roomID string) (repository.StreamPosition, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return repository.StreamPosition{}, err
+	}
+
+	var checkpoints []orm.MessageArchiveCheckpoint
+	result := r.db.WithContext(ctx).Where("room_id = ?", roomUUID).Limit(1).Find(&checkpoints)
+	if result.Error != nil || len(checkpoints) == 0 {
+		return repository.StreamPosition{}, result.Error
+	}
+
+	return repository.StreamPosition{Millis: checkpoints[0].LastMillis, Seq: checkpoints[0].LastSeq}, nil
+}
+
+func (r *PostgresMessageArchiveRepository) Position(ctx context.Context, roomID string, id uuid.UUID) (repository.StreamPosition, bool, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return repository.StreamPosition{}, false, err
+	}
+
+	// 항목의 밀리초는 메시지 타임스탬프 이상이므로 그 이후 파티션만 찾음
+	sec, nsec := id.Time().UnixTime()
+
+	var rows []orm.Message
+	result := r.db.WithContext(ctx).
+		Select("created_at", "seq").
+		Where("room_id = ? AND id = ? AND created_at >= ?", roomUUID, id, time.Unix(sec, nsec).Truncate(time.Millisecond)).
+		Limit(1).
+		Find(&rows)
+	if result.Error != nil || len(rows) == 0 {
+		return repository.StreamPosition{}, false, result.Error
+	}
+
+	return rowPosition(rows[0]), true, nil
+}
+
+func (r *PostgresMessageArchiveRepository) Find(ctx context.Context, roomID string, ids []uuid.UUID) ([]repository.StreamMessage, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []orm.Message
+	result := r.db.WithContext(ctx).Where("room_id = ? AND id IN ?", roomUUID, ids).Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return rowsToStreamMessages(rows)
+}
+
+func (r *PostgresMessageArchiveRepository) ListBefore(ctx context.Context, roomID string, before repository.StreamPosition, limit int) ([]repository.StreamMessage, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := r.db.WithContext(ctx).Where("room_id = ?", roomUUID)
+	if before != repository.MaxStreamPosition {
+		query = query.Where("(created_at, seq) < (?, ?)", positionTime(before), before.Seq)
+	}
+
+	var rows []orm.Message
+	result := query.Order("created_at DESC, seq DESC").Limit(limit).Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return rowsToStreamMessages(rows)
+}
+
+func (r *PostgresMessageArchiveRepository) ListAfter(ctx context.Context, roomID string, after repository.StreamPosition, limit int) ([]repository.StreamMessage, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []orm.Message
+	result := r.db.WithContext(ctx).
+		Where("room_id = ? AND (created_at, seq) > (?, ?)", roomUUID, positionTime(after), after.Seq).
+		Order("created_at ASC, seq ASC").
+		Limit(limit).
+		Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return rowsToStreamMessages(rows)
+}
+
+func (r *PostgresMessageArchiveRepository) UpdateMessage(ctx context.Context, roomID string, msg message.Message) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	sec, nsec := msg.GetID().Time().UnixTime()
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		stored := tx.Model(&orm.Message{}).
+			Where("room_id = ? AND id = ? AND created_at >= ?", roomUUID, msg.GetID(), time.Unix(sec, nsec).Truncate(time.Millisecond)).
+			Session(&gorm.Session{})
+
+		// 삭제된 메시지는 늦게 도착한 수정으로 되살아나지 않음
+		result := stored.Where("type <> ?", "deleted").
+			Updates(map[string]interface{}{"type": msg.GetType(), "body": string(body)})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var deleted int64
+			if err := stored.Where("type = ?", "deleted").Count(&deleted).Error; err != nil {
+				return err
+			}
+			if deleted > 0 {
+				return repository.ErrMessageNotFound
+			}
+			return nil
+		}
+
+		if msg.GetType() == "deleted" {
+			return tx.Where("room_id = ? AND message_id = ?", roomUUID, msg.GetID()).Delete(&orm.MessageRevision{}).Error
+		}
+		return nil
+	})
+}
+
+func (r *PostgresMessageArchiveRepository) AppendRevisions(ctx context.Context, roomID string, id uuid.UUID, revisions []message.Revision) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []orm.MessageRevision
+		if err := tx.Where("room_id = ? AND message_id = ?", roomUUID, id).Order("seq").Find(&existing).Error; err != nil {
+			return err
+		}
+
+		// 덧붙인 뒤 Redis에서 지우지 못해 다시 옮기는 판은 건너뜀
+		if endsWithRevisions(existing, revisions) {
+			return nil
+		}
+
+		rows := make([]orm.MessageRevision, len(revisions))
+		for i, revision := range revisions {
+			rows[i] = orm.MessageRevision{
+				RoomID:    roomUUID,
+				MessageID: id,
+				Seq:       len(existing) + i,
+				Content:   revision.Content,
+				Timestamp: revision.Timestamp,
+			}
+		}
+		return tx.CreateInBatches(&rows, 100).Error
+	})
+}
+
+func (r *PostgresMessageArchiveRepository) Revisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []orm.MessageRevision
+	result := r.db.WithContext(ctx).Where("room_id = ? AND message_id = ?", roomUUID, id).Order("seq").Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	revisions := make([]message.Revision, len(rows))
+	for i, row := range rows {
+		revisions[i] = message.Revision{Content: row.Content, Timestamp: row.Timestamp}
+	}
+	return revisions, nil
+}
+
+func (r *PostgresMessageArchiveRepository) HideMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+
+	rows := make([]orm.HiddenMessage, len(ids))
+	for i, id := range ids {
+		rows[i] = orm.HiddenMessage{RoomID: roomUUID, UserID: userID, MessageID: id}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 100).Error
+}
+
+func (r *PostgresMessageArchiveRepository) HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	hidden := make(map[uuid.UUID]bool)
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil || len(ids) == 0 {
+		return hidden, err
+	}
+
+	var hiddenIDs []uuid.UUID
+	result := r.db.WithContext(ctx).Model(&orm.HiddenMessage{}).
+		Where("room_id = ? AND user_id = ? AND message_id IN ?", roomUUID, userID, ids).
+		Pluck("message_id", &hiddenIDs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, id := range hiddenIDs {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+func (r *PostgresMessageArchiveRepository) ThreadReplies(ctx context.Context, roomID string, rootID uuid.UUID, before repository.StreamPosition, query message.PageQuery) ([]uuid.UUID, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	db := r.db.WithContext(ctx).Model(&orm.Message{}).Where("room_id = ? AND thread_id = ?", roomUUID, rootID)
+	if before != repository.MaxStreamPosition {
+		db = db.Where("(created_at, seq) < (?, ?)", positionTime(before), before.Seq)
+	}
+
+	// UUIDv7은 시간 순서이므로 Redis의 스레드와 같이 ID 순서로 페이지를 나눔
+	switch {
+	case query.After != uuid.Nil:
+		db = db.Where("id > ?", query.After).Order("id ASC")
+	case query.Before != uuid.Nil:
+		db = db.Where("id < ?", query.Before).Order("id DESC")
+	default:
+		db = db.Order("id DESC")
+	}
+
+	var ids []uuid.UUID
+	if err := db.Limit(query.Limit).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *PostgresMessageArchiveRepository) ThreadCounts(ctx context.Context, roomID string, rootIDs []uuid.UUID, before repository.StreamPosition) (map[uuid.UUID]repository.ThreadCount, error) {
+	counts := make(map[uuid.UUID]repository.ThreadCount)
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil || len(rootIDs) == 0 {
+		return counts, err
+	}
+
+	db := r.db.WithContext(ctx).Model(&orm.Message{}).
+		Select("thread_id, count(*) AS count, (array_agg(id ORDER BY id DESC))[1] AS last_reply").
+		Where("room_id = ? AND thread_id IN ?", roomUUID, rootIDs)
+	if before != repository.MaxStreamPosition {
+		db = db.Where("(created_at, seq) < (?, ?)", positionTime(before), before.Seq)
+	}
+
+	var rows []struct {
+		ThreadID  uuid.UUID
+		Count     int
+		LastReply uuid.UUID
+	}
+	if err := db.Group("thread_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		counts[row.ThreadID] = repository.ThreadCount{Count: row.Count, LastReply: row.LastReply}
+	}
+	return counts, nil
+}
+
+func (r *PostgresMessageArchiveRepository) AddThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID, userIDs []string) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil || len(userIDs) == 0 {
+		return err
+	}
+
+	rows := make([]orm.ThreadParticipant, len(userIDs))
+	for i, userID := range userIDs {
+		rows[i] = orm.ThreadParticipant{RoomID: roomUUID, RootID: rootID, UserID: userID}
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(&rows, 100).Error
+}
+
+func (r *PostgresMessageArchiveRepository) RemoveThreadParticipant(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return err
+	}
+
+	return r.db.WithContext(ctx).
+		Where("room_id = ? AND root_id = ? AND user_id = ?", roomUUID, rootID, userID).
+		Delete(&orm.ThreadParticipant{}).Error
+}
+
+func (r *PostgresMessageArchiveRepository) ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error) {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil, err
+	}
+
+	var userIDs []string
+	result := r.db.WithContext(ctx).Model(&orm.ThreadParticipant{}).
+		Where("room_id = ? AND root_id = ?", roomUUID, rootID).
+		Pluck("user_id", &userIDs)
+	return userIDs, result.Error
+}
+
+// ensurePartition은 createdAt이 속한 달의 파티션이 없으면 만듭니다.
+func (r *PostgresMessageArchiveRepository) ensurePartition(ctx context.Context, createdAt time.Time) error {
+	from := time.Date(createdAt.Year(), createdAt.Month(), 1, 0, 0, 0, 0, time.UTC)
+	name := fmt.Sprintf("messages_%04d_%02d", from.Year(), from.Month())
+	if _, ok := r.partitions.Load(name); ok {
+		return nil
+	}
+
+	statement := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s PARTITION OF messages FOR VALUES FROM ('%s') TO ('%s')",
+		name, from.Format(time.RFC3339), from.AddDate(0, 1, 0).Format(time.RFC3339),
+	)
+	if err := r.db.WithContext(ctx).Exec(statement).Error; err != nil {
+		return err
+	}
+
+	r.partitions.Store(name, struct{}{})
+	return nil
+}
+
+func positionTime(position repository.StreamPosition) time.Time {
+	return time.UnixMilli(position.Millis).UTC()
+}
+
+func rowPosition(row orm.Message) repository.StreamPosition {
+	return repository.StreamPosition{Millis: row.CreatedAt.UnixMilli(), Seq: row.Seq}
+}
+
+func rowsToStreamMessages(rows []orm.Message) ([]repository.StreamMessage, error) {
+	entries := make([]repository.StreamMessage, 0, len(rows))
+	for _, row := range rows {
+		msg, err := message.Decode([]byte(row.Body))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, repository.StreamMessage{Position: rowPosition(row), Message: msg})
+	}
+	return entries, nil
+}
+
+// endsWithRevisions는 existing이 revisions로 끝나는지 확인합니다.
+func endsWithRevisions(existing []orm.MessageRevision, revisions []message.Revision) bool {
+	if len(revisions) == 0 {
+		return true
+	}
+	if len(existing) < len(revisions) {
+		return false
+	}
+
+	tail := existing[len(existing)-len(revisions):]
+	for i, revision := range revisions {
+		if tail[i].Content != revision.Content || tail[i].Timestamp != revision.Timestamp {
+			return false
+		}
+	}
+	return true
+}
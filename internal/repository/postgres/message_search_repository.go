@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/models/orm"
+	"server/internal/repository"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// 검색 방식:
+//
+// search_vector는 본문을 'simple' 설정으로 토큰화한 tsvector로, 공백으로 나뉜 단어 단위로 일치시킵니다.
+// 한국어는 조사가 붙어 단어 단위 일치가 잘 되지 않으므로("안녕" ↔ "안녕하세요"),
+// pg_trgm 인덱스를 사용하는 부분 문자열 일치(ILIKE)를 함께 사용합니다.
+// 트라이그램 인덱스는 세 글자보다 짧은 패턴에는 쓰이지 않아 모든 메시지를 훑게 되므로,
+// 짧은 검색어는 방을 지정했을 때만 부분 문자열로 찾고 그렇지 않으면 단어 단위로만 찾습니다.
+
+// minSubstringSearchLength는 방을 지정하지 않고 부분 문자열로 찾을 수 있는 검색어의 최소 글자 수입니다.
+const minSubstringSearchLength = 3
+
+// likeEscaper는 LIKE 패턴의 특수 문자를 이스케이프합니다.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type PostgresMessageSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewPostgresMessageSearchRepository(db *gorm.DB) repository.MessageSearchRepository {
+	return &PostgresMessageSearchRepository{
+		db: db,
+	}
+}
+
+func (r *PostgresMessageSearchRepository) SearchMessages(ctx context.Context, userID uuid.UUID, query message.SearchQuery) (message.SearchPage, error) {
+	db := r.db.WithContext(ctx).
+		Table("messages").
+		Select("messages.*").
+		Joins("JOIN room_users ON room_users.room_id = messages.room_id AND room_users.user_id = ? AND room_users.deleted_at IS NULL", userID)
+
+	if query.RoomID != "" || utf8.RuneCountInString(query.Text) >= minSubstringSearchLength {
+		db = db.Where("(messages.search_vector @@ plainto_tsquery('simple', ?) OR messages.body->>'content' ILIKE ?)",
+			query.Text, "%"+likeEscaper.Replace(query.Text)+"%")
+	} else {
+		db = db.Where("messages.search_vector @@ plainto_tsquery('simple', ?)", query.Text)
+	}
+
+	if query.RoomID != "" {
+		roomUUID, err := uuid.Parse(query.RoomID)
+		if err != nil {
+			return message.SearchPage{}, err
+		}
+		db = db.Where("messages.room_id = ?", roomUUID)
+	}
+	if query.AuthorID != "" {
+		db = db.Where("messages.author_id = ?", query.AuthorID)
+	}
+	if !query.From.IsZero() {
+		db = db.Where("messages.created_at >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		db = db.Where("messages.created_at < ?", query.To)
+	}
+	if query.Before != uuid.Nil {
+		db = db.Where("messages.id < ?", query.Before)
+	}
+
+	// UUIDv7은 시간 순서이므로 ID로 정렬하고 커서로 사용함
+	var rows []orm.Message
+	if err := db.Order("messages.id DESC").Limit(query.Limit + 1).Find(&rows).Error; err != nil {
+		return message.SearchPage{}, err
+	}
+
+	var page message.SearchPage
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		page.HasMore = true
+	}
+
+	entries, err := rowsToStreamMessages(rows)
+	if err != nil {
+		return message.SearchPage{}, err
+	}
+
+	page.Results = make([]message.SearchResult, len(entries))
+	for i, entry := range entries {
+		page.Results[i] = message.SearchResult{Message: entry.Message}
+	}
+	if page.HasMore && len(rows) > 0 {
+		page.NextCursor = rows[len(rows)-1].ID
+	}
+
+	return page, nil
+}
@@ -54,6 +54,101 @@ func (r *PostgresRoomRepository) RemoveUserFromRoom(ctx context.Context, roomID,
 	return result.Error
 }
 
+func (r *PostgresRoomRepository) IsUserInRoom(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	var count int64
+	result := r.db.WithContext(ctx).Model(&orm.RoomUser{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+func (r *PostgresRoomRepository) GetRoomUserIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	result := r.db.WithContext(ctx).Model(&orm.RoomUser{}).
+		Where("room_id = ?", roomID).
+		Pluck("user_id", &userIDs)
+	return userIDs, result.Error
+}
+
+func (r *PostgresRoomRepository) GetRoommateIDs(ctx context.Context, userID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	var roommateIDs []uuid.UUID
+	if len(userIDs) == 0 {
+		return roommateIDs, nil
+	}
+	result := r.db.WithContext(ctx).Table("room_users AS others").
+		Joins("JOIN room_users AS mine ON mine.room_id = others.room_id AND mine.user_id = ? AND mine.deleted_at IS NULL", userID).
+		Joins("JOIN rooms ON rooms.id = others.room_id AND rooms.deleted_at IS NULL").
+		Where("others.user_id IN ? AND others.deleted_at IS NULL", userIDs).
+		Distinct().
+		Pluck("others.user_id", &roommateIDs)
+	return roommateIDs, result.Error
+}
+
+func (r *PostgresRoomRepository) AdvanceReadCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	return r.advanceCursor(ctx, "last_read_message_id", roomID, userID, messageID)
+}
+
+func (r *PostgresRoomRepository) AdvanceDeliveryCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	return r.advanceCursor(ctx, "last_delivered_message_id", roomID, userID, messageID)
+}
+
+// advanceCursor는 커서가 비어 있거나 messageID보다 앞에 있을 때만 옮깁니다.
+// Postgres는 UUID를 바이트 순서로 비교하므로 UUIDv7의 생성 순서와 같음
+func (r *PostgresRoomRepository) advanceCursor(ctx context.Context, column string, roomID, userID, messageID uuid.UUID) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&orm.RoomUser{}).
+		Where("room_id = ? AND user_id = ?", roomID, userID).
+		Where("("+column+" IS NULL OR "+column+" < ?)", messageID).
+		Update(column, messageID)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *PostgresRoomRepository) GetReadCursors(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	var roomUsers []orm.RoomUser
+	result := r.db.WithContext(ctx).
+		Select("user_id", "last_read_message_id").
+		Where("room_id = ? AND last_read_message_id IS NOT NULL", roomID).
+		Find(&roomUsers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	cursors := make(map[uuid.UUID]uuid.UUID, len(roomUsers))
+	for _, roomUser := range roomUsers {
+		cursors[roomUser.UserID] = *roomUser.LastReadMessageID
+	}
+	return cursors, nil
+}
+
+func (r *PostgresRoomRepository) GetUserRoomMemberships(ctx context.Context, userID uuid.UUID) ([]orm.UserRoom, error) {
+	var rooms []orm.UserRoom
+	result := r.userRoomMemberships(ctx, userID).Find(&rooms)
+	return rooms, result.Error
+}
+
+func (r *PostgresRoomRepository) GetUserRoomMembershipsByID(ctx context.Context, userID uuid.UUID, roomIDs []uuid.UUID) ([]orm.UserRoom, error) {
+	var rooms []orm.UserRoom
+	if len(roomIDs) == 0 {
+		return rooms, nil
+	}
+	result := r.userRoomMemberships(ctx, userID).Where("rooms.id IN ?", roomIDs).Find(&rooms)
+	return rooms, result.Error
+}
+
+// userRoomMemberships는 사용자가 속한 방을 읽음 커서, 멤버 수와 함께 조회하는 쿼리입니다.
+func (r *PostgresRoomRepository) userRoomMemberships(ctx context.Context, userID uuid.UUID) *gorm.DB {
+	return r.db.WithContext(ctx).Table("rooms").
+		Select("rooms.*, room_users.last_read_message_id, "+
+			"(SELECT COUNT(*) FROM room_users AS members WHERE members.room_id = rooms.id AND members.deleted_at IS NULL) AS member_count").
+		Joins("join room_users on rooms.id = room_users.room_id").
+		Where("room_users.user_id = ? AND room_users.deleted_at IS NULL AND rooms.deleted_at IS NULL", userID)
+}
+
 func (r *PostgresRoomRepository) CreateRoomWithUsers(ctx context.Context, roomName string, userIDs []uuid.UUID) (uuid.UUID, error) {
 
 	tx := r.db.WithContext(ctx).Begin()
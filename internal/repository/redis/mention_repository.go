@@ -0,0 +1,100 @@
+package redis
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 사용자마다 보관하는 멘션 수. 넘으면 오래된 멘션부터 지움
+const maxMentionsPerUser = 1000
+
+// RedisMentionRepository는 사용자마다 멘션 목록을 점수가 모두 0인 정렬 집합에 "<메시지 ID>/<방 ID>" 멤버로 둡니다.
+// UUIDv7 문자열은 생성 순서대로 정렬되므로 여러 방의 멘션을 사전 순 범위 조회로 시간 순서대로 읽습니다.
+// 방별 읽지 않은 멘션 수는 사용자마다 해시(방 ID -> 수)에 둡니다.
+type RedisMentionRepository struct {
+	client *redis.Client
+}
+
+func NewRedisMentionRepository(client *redis.Client) repository.MentionRepository {
+	return &RedisMentionRepository{
+		client: client,
+	}
+}
+
+func mentionsKey(userID string) string {
+	return "mentions:user:" + userID
+}
+
+func unreadMentionsKey(userID string) string {
+	return "mentions:user:" + userID + ":unread"
+}
+
+func (r *RedisMentionRepository) AddMention(ctx context.Context, roomID string, messageID uuid.UUID, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	member := messageID.String() + "/" + roomID
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, userID := range userIDs {
+			pipe.ZAdd(ctx, mentionsKey(userID), redis.Z{Score: 0, Member: member})
+			pipe.ZRemRangeByRank(ctx, mentionsKey(userID), 0, -maxMentionsPerUser-1)
+			pipe.HIncrBy(ctx, unreadMentionsKey(userID), roomID, 1)
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *RedisMentionRepository) ListMentions(ctx context.Context, userID string, before uuid.UUID, limit int) ([]message.MentionRef, error) {
+	max := "+"
+	if before != uuid.Nil {
+		// "<before>/<방 ID>" 멤버는 "<before>"보다 뒤이므로 before의 멘션은 포함되지 않음
+		max = "(" + before.String()
+	}
+
+	members, err := r.client.ZRevRangeByLex(ctx, mentionsKey(userID), &redis.ZRangeBy{Min: "-", Max: max, Count: int64(limit)}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]message.MentionRef, 0, len(members))
+	for _, member := range members {
+		idStr, roomID, _ := strings.Cut(member, "/")
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, message.MentionRef{RoomId: roomID, MessageId: id})
+	}
+	return refs, nil
+}
+
+func (r *RedisMentionRepository) UnreadCounts(ctx context.Context, userID string) (map[string]int, error) {
+	values, err := r.client.HGetAll(ctx, unreadMentionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int, len(values))
+	for roomID, value := range values {
+		count, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			counts[roomID] = count
+		}
+	}
+	return counts, nil
+}
+
+func (r *RedisMentionRepository) ClearUnread(ctx context.Context, userID, roomID string) error {
+	return r.client.HDel(ctx, unreadMentionsKey(userID), roomID).Err()
+}
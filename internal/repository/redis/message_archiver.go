@@ -0,0 +1,200 @@
+package redis
+
+import (
+	"context"
+	"log"
+	"server/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultArchiveInterval은 보관 대기 방을 확인하는 기본 주기입니다.
+	DefaultArchiveInterval = 10 * time.Second
+	// archiveBatchSize는 한 트랜잭션으로 보관하는 최대 항목 수입니다.
+	archiveBatchSize = 500
+	// archiveLockTTL은 한 방을 보관하는 노드를 하나로 제한하는 잠금의 유효 시간입니다.
+	archiveLockTTL = time.Minute
+)
+
+// releaseLockScript는 잠금의 값이 ARGV[1]일 때만 KEYS[1]의 잠금을 지웁니다.
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// MessageArchiver는 Redis 스트림의 메시지를 보관소로 복사하고, 보관이 확인된 항목만 스트림에서 잘라냅니다.
+// 스트림에는 보관 여부와 관계없이 최근 maxStreamLength개의 항목이 캐시로 남습니다.
+type MessageArchiver struct {
+	client   *redis.Client
+	archive  repository.MessageArchiveRepository
+	interval time.Duration
+}
+
+func NewMessageArchiver(client *redis.Client, archive repository.MessageArchiveRepository, interval time.Duration) *MessageArchiver {
+	return &MessageArchiver{
+		client:   client,
+		archive:  archive,
+		interval: interval,
+	}
+}
+
+// Run은 ctx가 끝날 때까지 interval마다 ArchiveOnce를 실행합니다.
+func (a *MessageArchiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.ArchiveOnce(ctx); err != nil {
+				log.Println("Failed to archive messages:", err)
+			}
+		}
+	}
+}
+
+// ArchiveOnce는 보관 대기 중인 모든 방을 보관합니다. 한 방의 실패는 다음 주기에 다시 시도합니다.
+func (a *MessageArchiver) ArchiveOnce(ctx context.Context) error {
+	roomIDs, err := a.client.SMembers(ctx, pendingArchiveKey).Result()
+	if err != nil {
+		return err
+	}
+
+	for _, roomID := range roomIDs {
+		if err := a.archiveRoom(ctx, roomID); err != nil {
+			log.Println("Failed to archive room:", roomID, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *MessageArchiver) archiveRoom(ctx context.Context, roomID string) error {
+	key := streamKey(roomID)
+
+	// 보관이 잠금의 유효 시간을 넘기면 다른 노드가 잠금을 가져갈 수 있으므로 자신의 토큰일 때만 해제함
+	lockKey := key + ":archive-lock"
+	token := uuid.NewString()
+	locked, err := a.client.SetNX(ctx, lockKey, token, archiveLockTTL).Result()
+	if err != nil || !locked {
+		return err
+	}
+	defer releaseLockScript.Run(ctx, a.client, []string{lockKey}, token)
+
+	checkpoint, err := a.archive.Checkpoint(ctx, roomID)
+	if err != nil {
+		return err
+	}
+
+	for {
+		streams, err := a.client.XRangeN(ctx, key, "("+checkpoint.String(), "+", archiveBatchSize).Result()
+		if err != nil {
+			return err
+		}
+
+		entries, err := redisStreamToMessageList(streams)
+		if err != nil {
+			return err
+		}
+
+		// 삭제된 메시지는 스트림에 없으므로 읽은 구간의 툼스톤을 함께 보관함
+		end := repository.MaxStreamPosition
+		if len(streams) == archiveBatchSize {
+			end = entries[len(entries)-1].Position
+		}
+		tombstones, err := tombstonesBetween(ctx, a.client, roomID, checkpoint, end)
+		if err != nil {
+			return err
+		}
+		entries = mergeTombstones(entries, tombstones, true)
+		if len(entries) == 0 {
+			break
+		}
+
+		// 수정된 메시지는 최신 판을 보관함
+		if err := applyEdits(ctx, a.client, roomID, entries); err != nil {
+			return err
+		}
+
+		last := entries[len(entries)-1].Position
+
+		// 체크포인트는 메시지와 같은 트랜잭션으로 커밋되므로, 체크포인트 이전 항목은 모두 보관된 것
+		if err := a.archive.Archive(ctx, roomID, entries, last); err != nil {
+			return err
+		}
+		checkpoint = last
+
+		if len(streams) < archiveBatchSize {
+			break
+		}
+	}
+
+	if err := a.trim(ctx, key, checkpoint); err != nil {
+		return err
+	}
+	limit, err := prunableLimit(ctx, a.client, roomID, checkpoint)
+	if err != nil {
+		return err
+	}
+	if err := pruneTombstones(ctx, a.client, roomID, limit); err != nil {
+		return err
+	}
+	if err := a.pruneArchivedState(ctx, roomID, limit); err != nil {
+		return err
+	}
+
+	// 집합에서 뺀 뒤 새 메시지가 있으면 다시 넣음. 이후에 저장되는 메시지는 저장 스크립트가 넣음
+	if err := a.client.SRem(ctx, pendingArchiveKey, roomID).Err(); err != nil {
+		return err
+	}
+	tail, err := a.client.XRevRangeN(ctx, key, "+", "-", 1).Result()
+	if err != nil {
+		return err
+	}
+	if len(tail) > 0 {
+		position, err := repository.ParseStreamPosition(tail[0].ID)
+		if err != nil {
+			return err
+		}
+		if checkpoint.Less(position) {
+			return a.client.SAdd(ctx, pendingArchiveKey, roomID).Err()
+		}
+	}
+
+	return nil
+}
+
+// trim은 스트림이 maxStreamLength개를 넘으면 초과분 중 checkpoint까지 보관된 항목만 잘라냅니다.
+func (a *MessageArchiver) trim(ctx context.Context, key string, checkpoint repository.StreamPosition) error {
+	length, err := a.client.XLen(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if length <= maxStreamLength {
+		return nil
+	}
+
+	excess, err := a.client.XRangeN(ctx, key, "-", "+", length-maxStreamLength).Result()
+	if err != nil {
+		return err
+	}
+
+	cutoff, err := repository.ParseStreamPosition(excess[len(excess)-1].ID)
+	if err != nil {
+		return err
+	}
+	if checkpoint.Less(cutoff) {
+		cutoff = checkpoint
+	}
+
+	// MINID는 주어진 ID보다 작은 항목을 지우므로 cutoff 바로 다음 위치를 넘김
+	minID := repository.StreamPosition{Millis: cutoff.Millis, Seq: cutoff.Seq + 1}
+	return a.client.XTrimMinID(ctx, key, minID.String()).Err()
+}
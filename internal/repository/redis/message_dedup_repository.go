@@ -0,0 +1,81 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisMessageDedupRepository struct {
+	client *redis.Client
+}
+
+func NewRedisMessageDedupRepository(client *redis.Client) repository.MessageDedupRepository {
+	return &RedisMessageDedupRepository{
+		client: client,
+	}
+}
+
+func dedupKey(userID, clientMessageID string) string {
+	return "dedup:message:" + userID + ":" + clientMessageID
+}
+
+// claimScript는 ID가 선점되지 않았으면 Receipt를 저장 중 상태로 기록하고, 선점되었으면 기록된 Receipt와 상태를 반환합니다.
+// KEYS[1]: 선점 해시, ARGV[1]: Receipt JSON, ARGV[2]: 유지 시간(밀리초)
+var claimScript = redis.NewScript(`
+local existing = redis.call('HMGET', KEYS[1], 'receipt', 'state')
+if existing[1] then
+	return existing
+end
+redis.call('HSET', KEYS[1], 'receipt', ARGV[1], 'state', 'pending')
+redis.call('PEXPIRE', KEYS[1], ARGV[2])
+return false
+`)
+
+// commitScript는 선점이 남아 있을 때만 저장 완료 상태로 바꾸고 유지 시간을 늘립니다.
+// KEYS[1]: 선점 해시, ARGV[1]: 유지 시간(밀리초)
+var commitScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'state', 'committed')
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+return 1
+`)
+
+// Claim은 선점을 해시(receipt, state)에 기록합니다. state는 저장 중이면 pending, 저장이 끝나면 committed입니다.
+func (r *RedisMessageDedupRepository) Claim(ctx context.Context, userID, clientMessageID string, receipt message.Receipt, ttl time.Duration) (message.Receipt, bool, error) {
+	receiptJSON, err := json.Marshal(receipt)
+	if err != nil {
+		return message.Receipt{}, false, err
+	}
+
+	values, err := claimScript.Run(ctx, r.client, []string{dedupKey(userID, clientMessageID)}, receiptJSON, ttl.Milliseconds()).StringSlice()
+	if err == redis.Nil {
+		return receipt, true, nil
+	}
+	if err != nil {
+		return message.Receipt{}, false, err
+	}
+	if len(values) < 2 || values[1] != "committed" {
+		return message.Receipt{}, false, repository.ErrClaimPending
+	}
+
+	var original message.Receipt
+	if err := json.Unmarshal([]byte(values[0]), &original); err != nil {
+		return message.Receipt{}, false, err
+	}
+	return original, false, nil
+}
+
+func (r *RedisMessageDedupRepository) Commit(ctx context.Context, userID, clientMessageID string, ttl time.Duration) error {
+	return commitScript.Run(ctx, r.client, []string{dedupKey(userID, clientMessageID)}, ttl.Milliseconds()).Err()
+}
+
+func (r *RedisMessageDedupRepository) Release(ctx context.Context, userID, clientMessageID string) error {
+	return r.client.Del(ctx, dedupKey(userID, clientMessageID)).Err()
+}
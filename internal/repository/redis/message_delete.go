@@ -0,0 +1,296 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 메시지 삭제:
+//
+// 모두에게서 삭제하면 원래 본문이 남지 않도록 스트림 항목을 XDEL로 지우고(수정 전 판과 반응도 함께 지움), 같은 위치의 툼스톤을 방의 tombstones 정렬 집합에 둡니다.
+// 스트림에는 같은 ID로 항목을 다시 넣을 수 없으므로, 메시지를 읽을 때 읽은 구간의 툼스톤을 위치 순서대로 끼워 넣어 기록에 빈자리가 생기지 않게 합니다.
+// 툼스톤 본문은 edits 해시에 두므로 보관소에서 읽은 항목도 툼스톤으로 덮어씌워집니다.
+// 보관기는 툼스톤도 보관하며, 스트림이 잘려 보관소에서 읽게 된 구간의 툼스톤은 정렬 집합에서 지웁니다.
+// 그 구간의 툼스톤 본문과 숨김도 보관소로 옮기므로(message_prune.go), 삭제 여부는 보관소의 본문으로 판단합니다.
+
+func tombstonesKey(roomID string) string {
+	return "stream:room:" + roomID + ":tombstones"
+}
+
+func hiddenKey(roomID, userID string) string {
+	return "stream:room:" + roomID + ":hidden:" + userID
+}
+
+// tombstoneMember는 툼스톤 정렬 집합의 멤버 "<위치>/<메시지 ID>"를 만듭니다. 점수는 위치의 밀리초입니다.
+func tombstoneMember(position repository.StreamPosition, id uuid.UUID) string {
+	return position.String() + "/" + id.String()
+}
+
+func parseTombstoneMember(member string) (repository.StreamPosition, uuid.UUID, error) {
+	positionStr, idStr, _ := strings.Cut(member, "/")
+	position, err := repository.ParseStreamPosition(positionStr)
+	if err != nil {
+		return repository.StreamPosition{}, uuid.Nil, err
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return repository.StreamPosition{}, uuid.Nil, err
+	}
+	return position, id, nil
+}
+
+func (r *RedisMessageRepository) DeleteMessage(ctx context.Context, roomID string, tombstone *message.DeletedMessage) error {
+	id := tombstone.GetID()
+	if id.Version() != 7 {
+		return repository.ErrInvalidMessageID
+	}
+
+	position, found, err := r.locate(ctx, roomID, id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return repository.ErrMessageNotFound
+	}
+
+	msgJSON, err := json.Marshal(tombstone)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XDel(ctx, streamKey(roomID), position.String())
+		pipe.HSet(ctx, editsKey(roomID), id.String(), msgJSON)
+		pipe.Del(ctx, revisionsKey(roomID, id))
+		pipe.Del(ctx, reactionsKey(roomID, id))
+		pipe.ZAdd(ctx, tombstonesKey(roomID), redis.Z{Score: float64(position.Millis), Member: tombstoneMember(position, id)})
+		// 아직 보관되지 않은 툼스톤을 보관하고, 보관된 구간의 툼스톤을 정리하도록 보관 대기에 넣음
+		if r.archive != nil {
+			pipe.SAdd(ctx, pendingArchiveKey, roomID)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// 이미 삭제되어 보관된 메시지면 보관소는 그대로 둠
+	if r.archive != nil {
+		if err := r.archive.UpdateMessage(ctx, roomID, tombstone); err != nil && !errors.Is(err, repository.ErrMessageNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *RedisMessageRepository) HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error {
+	if r.archive == nil {
+		return r.client.SAdd(ctx, hiddenKey(roomID, userID), id.String()).Err()
+	}
+
+	// 보관기가 정리할 숨김 집합을 찾을 수 있도록 사용자를 색인에 넣음
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, hiddenKey(roomID, userID), id.String())
+		pipe.SAdd(ctx, hiddenUsersKey(roomID), userID)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisMessageRepository) HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	hidden := make(map[uuid.UUID]bool)
+	if len(ids) == 0 {
+		return hidden, nil
+	}
+
+	members := make([]interface{}, len(ids))
+	for i, id := range ids {
+		members[i] = id.String()
+	}
+
+	flags, err := r.client.SMIsMember(ctx, hiddenKey(roomID, userID), members...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []uuid.UUID
+	var pruned repository.StreamPosition
+	if r.archive != nil {
+		if pruned, err = prunedPosition(ctx, r.client, roomID); err != nil {
+			return nil, err
+		}
+	}
+	for i, flag := range flags {
+		if flag {
+			hidden[ids[i]] = true
+		} else if mayBePruned(ids[i], pruned) {
+			archived = append(archived, ids[i])
+		}
+	}
+	if len(archived) == 0 {
+		return hidden, nil
+	}
+
+	// 스트림에서 잘려 나간 메시지의 숨김은 보관소로 옮겨짐
+	archivedHidden, err := r.archive.HiddenMessages(ctx, roomID, userID, archived)
+	if err != nil {
+		return nil, err
+	}
+	for id := range archivedHidden {
+		hidden[id] = true
+	}
+	return hidden, nil
+}
+
+// findTombstone은 삭제된 메시지의 위치를 찾습니다.
+func (r *RedisMessageRepository) findTombstone(ctx context.Context, roomID string, id uuid.UUID) (repository.StreamPosition, bool, error) {
+	members, err := r.client.ZRangeByScore(ctx, tombstonesKey(roomID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(uuidMillis(id), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return repository.StreamPosition{}, false, err
+	}
+
+	for _, member := range members {
+		position, tombstoneID, err := parseTombstoneMember(member)
+		if err != nil {
+			return repository.StreamPosition{}, false, err
+		}
+		if tombstoneID == id {
+			return position, true, nil
+		}
+	}
+	return repository.StreamPosition{}, false, nil
+}
+
+// tombstonesBetween은 from과 to 사이(양 끝 제외)의 툼스톤을 오래된 순으로 반환합니다.
+func tombstonesBetween(ctx context.Context, client *redis.Client, roomID string, from, to repository.StreamPosition) ([]repository.StreamMessage, error) {
+	max := "+inf"
+	if to != repository.MaxStreamPosition {
+		max = strconv.FormatInt(to.Millis, 10)
+	}
+
+	members, err := client.ZRangeByScore(ctx, tombstonesKey(roomID), &redis.ZRangeBy{
+		Min: strconv.FormatInt(from.Millis, 10),
+		Max: max,
+	}).Result()
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+
+	var tombstones []repository.StreamMessage
+	var ids []string
+	for _, member := range members {
+		position, id, err := parseTombstoneMember(member)
+		if err != nil {
+			return nil, err
+		}
+		if !from.Less(position) || !position.Less(to) {
+			continue
+		}
+		tombstones = append(tombstones, repository.StreamMessage{Position: position})
+		ids = append(ids, id.String())
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	values, err := client.HMGet(ctx, editsKey(roomID), ids...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]repository.StreamMessage, 0, len(tombstones))
+	for i, value := range values {
+		msgJSON, ok := value.(string)
+		if !ok {
+			continue
+		}
+		msg, err := message.Decode([]byte(msgJSON))
+		if err != nil {
+			return nil, err
+		}
+		tombstones[i].Message = msg
+		entries = append(entries, tombstones[i])
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Position.Less(entries[j].Position)
+	})
+	return entries, nil
+}
+
+// mergeTombstones는 entries에 없는 위치의 툼스톤을 끼워 넣고 위치 순서로 정렬합니다.
+// ascending이 false이면 최신 순으로 정렬합니다.
+func mergeTombstones(entries, tombstones []repository.StreamMessage, ascending bool) []repository.StreamMessage {
+	if len(tombstones) == 0 {
+		return entries
+	}
+
+	// 보관소에서 읽은 항목에는 이미 보관된 툼스톤이 있을 수 있음
+	seen := make(map[repository.StreamPosition]bool, len(entries))
+	for _, entry := range entries {
+		seen[entry.Position] = true
+	}
+	for _, tombstone := range tombstones {
+		if !seen[tombstone.Position] {
+			entries = append(entries, tombstone)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if ascending {
+			return entries[i].Position.Less(entries[j].Position)
+		}
+		return entries[j].Position.Less(entries[i].Position)
+	})
+	return entries
+}
+
+// prunableLimit은 보관되었고 스트림에서도 잘려 나간 구간의 끝 위치를 반환합니다. 이 위치보다 앞선 구간은 보관소에서 읽습니다.
+func prunableLimit(ctx context.Context, client *redis.Client, roomID string, checkpoint repository.StreamPosition) (repository.StreamPosition, error) {
+	limit := repository.StreamPosition{Millis: checkpoint.Millis, Seq: checkpoint.Seq}
+	if limit.Seq < math.MaxInt64 {
+		limit.Seq++
+	}
+
+	first, err := client.XRangeN(ctx, streamKey(roomID), "-", "+", 1).Result()
+	if err != nil {
+		return repository.StreamPosition{}, err
+	}
+	if len(first) > 0 {
+		firstPosition, err := repository.ParseStreamPosition(first[0].ID)
+		if err != nil {
+			return repository.StreamPosition{}, err
+		}
+		if firstPosition.Less(limit) {
+			limit = firstPosition
+		}
+	}
+	return limit, nil
+}
+
+// pruneTombstones는 limit보다 앞선 구간의 툼스톤을 지웁니다. 그 구간은 보관소에서 읽습니다.
+func pruneTombstones(ctx context.Context, client *redis.Client, roomID string, limit repository.StreamPosition) error {
+	tombstones, err := tombstonesBetween(ctx, client, roomID, repository.StreamPosition{}, limit)
+	if err != nil || len(tombstones) == 0 {
+		return err
+	}
+
+	members := make([]interface{}, len(tombstones))
+	for i, tombstone := range tombstones {
+		members[i] = tombstoneMember(tombstone.Position, tombstone.Message.GetID())
+	}
+	return client.ZRem(ctx, tombstonesKey(roomID), members...).Err()
+}
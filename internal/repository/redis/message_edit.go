@@ -0,0 +1,273 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 메시지 수정:
+//
+// 스트림 항목은 바꿀 수 없으므로 수정된 메시지의 최신 판은 방의 edits 해시(메시지 ID -> 메시지 JSON)에 두고,
+// 스트림이나 보관소에서 읽은 메시지에 덮어씌워 반환합니다. 수정 전 판은 메시지마다 revisions 리스트에 쌓습니다.
+// 보관소에 이미 옮겨진 메시지는 보관소의 본문도 함께 바꾸고, 보관기는 옮길 때 최신 판을 저장하므로 검색도 최신 판을 대상으로 합니다.
+// 스트림에서 잘려 나간 메시지의 최신 판과 수정 기록은 보관기가 보관소로 옮깁니다(message_prune.go).
+
+func editsKey(roomID string) string {
+	return "stream:room:" + roomID + ":edits"
+}
+
+func revisionsKey(roomID string, id uuid.UUID) string {
+	return "stream:room:" + roomID + ":revisions:" + id.String()
+}
+
+func (r *RedisMessageRepository) GetMessage(ctx context.Context, roomID string, id uuid.UUID) (message.Message, error) {
+	if id.Version() != 7 {
+		return nil, repository.ErrInvalidMessageID
+	}
+
+	position, found, err := r.locate(ctx, roomID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, repository.ErrMessageNotFound
+	}
+
+	// 바로 앞 위치 다음의 항목 하나를 읽음
+	previous := repository.StreamPosition{Millis: position.Millis, Seq: position.Seq - 1}
+	if position.Seq == 0 {
+		previous = repository.StreamPosition{Millis: position.Millis - 1, Seq: math.MaxInt64}
+	}
+
+	entries, err := r.messagesAfter(ctx, roomID, previous, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 || entries[0].Message.GetID() != id {
+		return nil, repository.ErrMessageNotFound
+	}
+
+	return entries[0].Message, nil
+}
+
+// batchScanCount는 여러 메시지를 ID로 읽을 때 메시지마다 타임스탬프부터 훑는 항목 수입니다.
+// 같은 밀리초에 이보다 많이 저장되었거나 늦은 시계 때문에 뒤로 밀린 메시지는 GetMessage로 따로 찾습니다.
+const batchScanCount = 8
+
+// GetMessagesByID는 스트림과 수정 기록을 파이프라인 한 번으로 읽고, 스트림에서 잘려 나간 메시지는 보관소에서 한 번에 읽습니다.
+// 수정되거나 삭제된 메시지는 edits 해시의 최신 판을 그대로 사용합니다.
+func (r *RedisMessageRepository) GetMessagesByID(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.Message, error) {
+	found := make(map[uuid.UUID]message.Message, len(ids))
+
+	seen := make(map[uuid.UUID]bool, len(ids))
+	var lookups []uuid.UUID
+	for _, id := range ids {
+		if id.Version() == 7 && !seen[id] {
+			seen[id] = true
+			lookups = append(lookups, id)
+		}
+	}
+	if len(lookups) == 0 {
+		return found, nil
+	}
+
+	key := streamKey(roomID)
+	fields := make([]string, len(lookups))
+	for i, id := range lookups {
+		fields[i] = id.String()
+	}
+
+	pipe := r.client.Pipeline()
+	first := pipe.XRangeN(ctx, key, "-", "+", 1)
+	edits := pipe.HMGet(ctx, editsKey(roomID), fields...)
+	scans := make([]*redis.XMessageSliceCmd, len(lookups))
+	for i, id := range lookups {
+		scans[i] = pipe.XRangeN(ctx, key, strconv.FormatInt(uuidMillis(id), 10)+"-0", "+", batchScanCount)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	for i, value := range edits.Val() {
+		msgJSON, ok := value.(string)
+		if !ok {
+			continue
+		}
+		msg, err := message.Decode([]byte(msgJSON))
+		if err != nil {
+			return nil, err
+		}
+		found[lookups[i]] = msg
+	}
+
+	var firstMillis int64 = math.MaxInt64
+	if entries := first.Val(); len(entries) > 0 {
+		firstMillis = entryMillis(entries[0].ID)
+	}
+
+	var archived, rescan []uuid.UUID
+	for i, id := range lookups {
+		if _, ok := found[id]; ok {
+			continue
+		}
+
+		entries, err := redisStreamToMessageList(scans[i].Val())
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Message.GetID() == id {
+				found[id] = entry.Message
+				break
+			}
+		}
+		if _, ok := found[id]; ok {
+			continue
+		}
+
+		if r.archive != nil && uuidMillis(id) < firstMillis {
+			archived = append(archived, id)
+		} else if len(scans[i].Val()) == batchScanCount {
+			rescan = append(rescan, id)
+		}
+	}
+
+	if len(archived) > 0 {
+		entries, err := r.archive.Find(ctx, roomID, archived)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			found[entry.Message.GetID()] = entry.Message
+		}
+	}
+
+	for _, id := range rescan {
+		msg, err := r.GetMessage(ctx, roomID, id)
+		if errors.Is(err, repository.ErrMessageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found[id] = msg
+	}
+
+	return found, nil
+}
+
+// editMessageScript는 메시지가 삭제되지 않았을 때만 최신 판을 바꾸고 수정 전 판을 쌓습니다.
+// 서비스가 메시지를 읽은 뒤 쓰기 전에 삭제가 끼어들어도 툼스톤을 덮어써 메시지가 되살아나지 않습니다.
+// KEYS[1]: edits 해시, KEYS[2]: revisions 리스트, ARGV[1]: 메시지 ID, ARGV[2]: 메시지 JSON, ARGV[3]: 수정 전 판 JSON
+var editMessageScript = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if current and cjson.decode(current).type == 'deleted' then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('RPUSH', KEYS[2], ARGV[3])
+return 1
+`)
+
+func (r *RedisMessageRepository) EditMessage(ctx context.Context, roomID string, edited message.Message, previous message.Revision) error {
+	msgJSON, err := json.Marshal(edited)
+	if err != nil {
+		return err
+	}
+	revisionJSON, err := json.Marshal(previous)
+	if err != nil {
+		return err
+	}
+
+	// 보관소를 먼저 바꿈. 툼스톤이 Redis에서 정리된 메시지도 보관소가 삭제 여부를 알고 있음
+	// 보관소를 바꾼 뒤 Redis에서 삭제가 확인되면, 삭제하는 쪽이 이어서 보관소의 본문을 툼스톤으로 바꿈
+	if r.archive != nil {
+		if err := r.archive.UpdateMessage(ctx, roomID, edited); err != nil {
+			return err
+		}
+	}
+
+	keys := []string{editsKey(roomID), revisionsKey(roomID, edited.GetID())}
+	saved, err := editMessageScript.Run(ctx, r.client, keys, edited.GetID().String(), msgJSON, revisionJSON).Int()
+	if err != nil {
+		return err
+	}
+	// 수정 전에 읽은 뒤 모두에게서 삭제된 메시지
+	if saved == 0 {
+		return repository.ErrMessageNotFound
+	}
+	return nil
+}
+
+func (r *RedisMessageRepository) GetRevisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error) {
+	values, err := r.client.LRange(ctx, revisionsKey(roomID, id), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := decodeRevisions(values)
+	if err != nil || r.archive == nil {
+		return revisions, err
+	}
+
+	// 스트림에서 잘려 나간 메시지의 수정 기록은 보관소로 옮겨지며, 그 뒤의 수정 기록만 Redis에 남음
+	pruned, err := prunedPosition(ctx, r.client, roomID)
+	if err != nil || !mayBePruned(id, pruned) {
+		return revisions, err
+	}
+	archived, err := r.archive.Revisions(ctx, roomID, id)
+	if err != nil {
+		return nil, err
+	}
+	return append(archived, revisions...), nil
+}
+
+func decodeRevisions(values []string) ([]message.Revision, error) {
+	revisions := make([]message.Revision, 0, len(values))
+	for _, value := range values {
+		var revision message.Revision
+		if err := json.Unmarshal([]byte(value), &revision); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+// applyEdits는 수정된 메시지를 최신 판으로 바꿉니다.
+func applyEdits(ctx context.Context, client *redis.Client, roomID string, entries []repository.StreamMessage) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.Message.GetID().String()
+	}
+
+	values, err := client.HMGet(ctx, editsKey(roomID), ids...).Result()
+	if err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		msgJSON, ok := value.(string)
+		if !ok {
+			continue
+		}
+		msg, err := message.Decode([]byte(msgJSON))
+		if err != nil {
+			return err
+		}
+		entries[i].Message = msg
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"server/internal/models/message"
+	"server/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 보관된 메시지 상태 정리:
+//
+// 수정된 메시지의 최신 판과 수정 기록, 사용자가 숨긴 메시지, 스레드의 답장 목록과 참여자는 메시지마다 Redis에 쌓입니다.
+// 보관기는 보관되었고 스트림에서도 잘려 나간 메시지(툼스톤을 지우는 구간과 같은 구간)의 상태를 보관소로 옮기고 Redis에서 지웁니다.
+// 최신 판은 보관소의 본문에 반영되어 있으므로 본문을 한 번 더 맞춘 뒤 지우고, 나머지는 보관소의 별도 테이블로 옮깁니다.
+// 옮긴 구간의 끝 위치는 방마다 pruned 키에 두며, 이 위치보다 앞설 수 있는 메시지의 상태는 Redis와 보관소를 함께 읽습니다.
+// 정리한 뒤에 바뀐 상태(오래된 메시지의 수정, 숨김, 스레드의 새 답장)는 다시 Redis에 쌓였다가 다음 정리 때 옮겨집니다.
+
+func prunedKey(roomID string) string {
+	return "stream:room:" + roomID + ":pruned"
+}
+
+// hiddenUsersKey는 방에서 메시지를 숨긴 사용자 ID 집합입니다. 보관기가 정리할 숨김 집합을 찾을 때 사용합니다.
+func hiddenUsersKey(roomID string) string {
+	return "stream:room:" + roomID + ":hidden-users"
+}
+
+// threadsKey는 방에서 답장이 달렸거나 참여자가 있는 스레드의 루트 ID 집합입니다. 보관기가 정리할 스레드를 찾을 때 사용합니다.
+func threadsKey(roomID string) string {
+	return "stream:room:" + roomID + ":threads"
+}
+
+// prunedPosition은 상태를 보관소로 옮긴 구간의 끝 위치를 반환합니다. 옮긴 적이 없으면 0 위치입니다.
+func prunedPosition(ctx context.Context, client *redis.Client, roomID string) (repository.StreamPosition, error) {
+	value, err := client.Get(ctx, prunedKey(roomID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return repository.StreamPosition{}, nil
+	}
+	if err != nil {
+		return repository.StreamPosition{}, err
+	}
+	return repository.ParseStreamPosition(value)
+}
+
+// mayBePruned는 메시지의 상태가 보관소로 옮겨졌을 수 있는지 확인합니다.
+// 항목의 밀리초는 메시지 타임스탬프 이상이므로 타임스탬프가 pruned 위치의 밀리초보다 뒤면 옮겨지지 않은 것입니다.
+func mayBePruned(id uuid.UUID, pruned repository.StreamPosition) bool {
+	return pruned != (repository.StreamPosition{}) && uuidMillis(id) <= pruned.Millis
+}
+
+// pruneEditScript는 보관소로 옮긴 수정 기록을 지우고, 최신 판이 읽은 뒤 바뀌지 않았으면 최신 판도 지웁니다.
+// KEYS[1]: edits 해시, KEYS[2]: revisions 리스트, ARGV[1]: 메시지 ID, ARGV[2]: 읽은 최신 판 JSON, ARGV[3]: 옮긴 수정 기록 수
+var pruneEditScript = redis.NewScript(`
+if tonumber(ARGV[3]) > 0 then
+	redis.call('LTRIM', KEYS[2], ARGV[3], -1)
+end
+if redis.call('HGET', KEYS[1], ARGV[1]) == ARGV[2] then
+	redis.call('HDEL', KEYS[1], ARGV[1])
+end
+return 1
+`)
+
+// pruneMembersScript는 집합에서 보관소로 옮긴 멤버를 지우고, 집합이 비면 색인 집합에서도 뺍니다.
+// KEYS[1]: 집합, KEYS[2]: 색인 집합, ARGV[1]: 색인 멤버, ARGV[2...]: 지울 멤버
+var pruneMembersScript = redis.NewScript(`
+for i = 2, #ARGV do
+	redis.call('SREM', KEYS[1], ARGV[i])
+end
+if redis.call('SCARD', KEYS[1]) == 0 then
+	redis.call('SREM', KEYS[2], ARGV[1])
+end
+return 1
+`)
+
+// pruneThreadScript는 스레드에서 보관소로 옮긴 답장과 참여자를 지우고, 스레드가 비면 색인 집합에서도 뺍니다.
+// KEYS[1]: 스레드 정렬 집합, KEYS[2]: 참여자 집합, KEYS[3]: 스레드 색인 집합
+// ARGV[1]: 루트 ID, ARGV[2]: 지울 답장 수 n, ARGV[3...n+2]: 답장 ID, 나머지: 참여자 ID
+var pruneThreadScript = redis.NewScript(`
+local n = tonumber(ARGV[2])
+for i = 3, #ARGV do
+	if i <= n + 2 then
+		redis.call('ZREM', KEYS[1], ARGV[i])
+	else
+		redis.call('SREM', KEYS[2], ARGV[i])
+	end
+end
+if redis.call('ZCARD', KEYS[1]) == 0 and redis.call('SCARD', KEYS[2]) == 0 then
+	redis.call('SREM', KEYS[3], ARGV[1])
+end
+return 1
+`)
+
+// pruneArchivedState는 limit보다 앞선 위치에 보관된 메시지의 상태를 보관소로 옮기고 Redis에서 지웁니다.
+func (a *MessageArchiver) pruneArchivedState(ctx context.Context, roomID string, limit repository.StreamPosition) error {
+	pipe := a.client.Pipeline()
+	editsCmd := pipe.HGetAll(ctx, editsKey(roomID))
+	usersCmd := pipe.SMembers(ctx, hiddenUsersKey(roomID))
+	rootsCmd := pipe.SMembers(ctx, threadsKey(roomID))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	users := usersCmd.Val()
+	roots, err := parseIDs(rootsCmd.Val())
+	if err != nil {
+		return err
+	}
+
+	pipe = a.client.Pipeline()
+	hiddenCmds := make([]*redis.StringSliceCmd, len(users))
+	for i, userID := range users {
+		hiddenCmds[i] = pipe.SMembers(ctx, hiddenKey(roomID, userID))
+	}
+	replyCmds := make([]*redis.StringSliceCmd, len(roots))
+	participantCmds := make([]*redis.StringSliceCmd, len(roots))
+	for i, rootID := range roots {
+		replyCmds[i] = pipe.ZRange(ctx, threadKey(roomID, rootID), 0, -1)
+		participantCmds[i] = pipe.SMembers(ctx, threadParticipantsKey(roomID, rootID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// 메시지 타임스탬프가 limit보다 뒤면 항목도 limit보다 뒤이므로 보관소에서 위치를 확인할 후보에서 뺌
+	candidates := make(map[uuid.UUID]bool)
+	addCandidates := func(values []string) error {
+		ids, err := parseIDs(values)
+		for _, id := range ids {
+			if uuidMillis(id) <= limit.Millis {
+				candidates[id] = true
+			}
+		}
+		return err
+	}
+	edited := make([]string, 0, len(editsCmd.Val()))
+	for id := range editsCmd.Val() {
+		edited = append(edited, id)
+	}
+	if err := addCandidates(edited); err != nil {
+		return err
+	}
+	for _, cmd := range hiddenCmds {
+		if err := addCandidates(cmd.Val()); err != nil {
+			return err
+		}
+	}
+	for i, cmd := range replyCmds {
+		if err := addCandidates(append(cmd.Val(), roots[i].String())); err != nil {
+			return err
+		}
+	}
+
+	pruned, err := a.prunable(ctx, roomID, candidates, limit)
+	if err != nil || len(pruned) == 0 {
+		return err
+	}
+
+	prunedEdits := filterPruned(edited, pruned)
+	revisionCmds := make([]*redis.StringSliceCmd, len(prunedEdits))
+	pipe = a.client.Pipeline()
+	for i, id := range prunedEdits {
+		revisionCmds[i] = pipe.LRange(ctx, revisionsKey(roomID, uuid.MustParse(id)), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	// 보관소에 먼저 옮기고 Redis에서 한꺼번에 지움. 도중에 실패하면 다음 주기에 다시 옮기며, 보관소는 같은 상태를 두 번 기록하지 않음
+	tx := a.client.TxPipeline()
+	for i, idStr := range prunedEdits {
+		id := uuid.MustParse(idStr)
+		msgJSON := editsCmd.Val()[idStr]
+		msg, err := message.Decode([]byte(msgJSON))
+		if err != nil {
+			return err
+		}
+		// 보관하는 동안 바뀐 최신 판이 본문에 반영되지 않았을 수 있으므로 지우기 전에 맞춤
+		if err := a.archive.UpdateMessage(ctx, roomID, msg); err != nil && !errors.Is(err, repository.ErrMessageNotFound) {
+			return err
+		}
+
+		revisions, err := decodeRevisions(revisionCmds[i].Val())
+		if err != nil {
+			return err
+		}
+		if len(revisions) > 0 {
+			if err := a.archive.AppendRevisions(ctx, roomID, id, revisions); err != nil {
+				return err
+			}
+		}
+		pruneEditScript.Eval(ctx, tx, []string{editsKey(roomID), revisionsKey(roomID, id)}, idStr, msgJSON, len(revisions))
+	}
+
+	for i, userID := range users {
+		prunedHidden := filterPruned(hiddenCmds[i].Val(), pruned)
+		if len(prunedHidden) == 0 {
+			continue
+		}
+		ids, _ := parseIDs(prunedHidden)
+		if err := a.archive.HideMessages(ctx, roomID, userID, ids); err != nil {
+			return err
+		}
+		pruneMembersScript.Eval(ctx, tx, []string{hiddenKey(roomID, userID), hiddenUsersKey(roomID)}, append([]interface{}{userID}, toArgs(prunedHidden)...)...)
+	}
+
+	for i, rootID := range roots {
+		replies := replyCmds[i].Val()
+		prunedReplies := filterPruned(replies, pruned)
+
+		// 루트와 모든 답장이 옮겨진 스레드는 참여자도 옮김. 이후 스레드에 다시 답장이 달리면 참여자는 Redis와 보관소를 함께 읽음
+		var participants []string
+		if pruned[rootID] && len(prunedReplies) == len(replies) {
+			participants = participantCmds[i].Val()
+			if err := a.archive.AddThreadParticipants(ctx, roomID, rootID, participants); err != nil {
+				return err
+			}
+		}
+		if len(prunedReplies) == 0 && len(participants) == 0 {
+			continue
+		}
+
+		args := []interface{}{rootID.String(), len(prunedReplies)}
+		args = append(args, toArgs(prunedReplies)...)
+		args = append(args, toArgs(participants)...)
+		keys := []string{threadKey(roomID, rootID), threadParticipantsKey(roomID, rootID), threadsKey(roomID)}
+		pruneThreadScript.Eval(ctx, tx, keys, args...)
+	}
+
+	tx.Set(ctx, prunedKey(roomID), limit.String(), 0)
+	_, err = tx.Exec(ctx)
+	return err
+}
+
+// prunable은 후보 중 limit보다 앞선 위치에 보관된 메시지를 찾습니다.
+func (a *MessageArchiver) prunable(ctx context.Context, roomID string, candidates map[uuid.UUID]bool, limit repository.StreamPosition) (map[uuid.UUID]bool, error) {
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+
+	pruned := make(map[uuid.UUID]bool)
+	for start := 0; start < len(ids); start += archiveBatchSize {
+		end := start + archiveBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		entries, err := a.archive.Find(ctx, roomID, ids[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.Position.Less(limit) {
+				pruned[entry.Message.GetID()] = true
+			}
+		}
+	}
+	return pruned, nil
+}
+
+// filterPruned는 values 중 옮길 메시지 ID만 반환합니다.
+func filterPruned(values []string, pruned map[uuid.UUID]bool) []string {
+	var result []string
+	for _, value := range values {
+		if id, err := uuid.Parse(value); err == nil && pruned[id] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func parseIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, value := range values {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func toArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, value := range values {
+		args[i] = value
+	}
+	return args
+}
@@ -0,0 +1,136 @@
+package redis
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 메시지 반응:
+//
+// 반응은 메시지마다 정렬 집합에 "<이모지>\n<사용자 ID>" 멤버로 저장하며, 점수는 반응한 순서입니다.
+// 집계할 때 이모지는 처음 반응한 순서로, 사용자는 반응한 순서로 나열합니다.
+
+func reactionsKey(roomID string, id uuid.UUID) string {
+	return "stream:room:" + roomID + ":reactions:" + id.String()
+}
+
+func reactionMember(userID, emoji string) string {
+	return emoji + "\n" + userID
+}
+
+// addReactionScript는 사용자의 서로 다른 반응 수를 확인하고 반응을 추가합니다.
+// KEYS[1]: 반응 정렬 집합, ARGV[1]: 멤버, ARGV[2]: 사용자 ID, ARGV[3]: 사용자별 최대 반응 수
+// 반환값: 1 추가함, 0 이미 있음, -1 최대 반응 수를 넘음
+var addReactionScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	return 0
+end
+local suffix = '\n' .. ARGV[2]
+local count = 0
+for _, member in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+	if string.sub(member, -#suffix) == suffix then
+		count = count + 1
+	end
+end
+if count >= tonumber(ARGV[3]) then
+	return -1
+end
+local score = 0
+local last = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+if #last > 0 then
+	score = tonumber(last[2]) + 1
+end
+redis.call('ZADD', KEYS[1], score, ARGV[1])
+return 1
+`)
+
+func (r *RedisMessageRepository) AddReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string, limit int) ([]message.Reaction, bool, error) {
+	key := reactionsKey(roomID, id)
+
+	result, err := addReactionScript.Run(ctx, r.client, []string{key}, reactionMember(userID, emoji), userID, limit).Int()
+	if err != nil {
+		return nil, false, err
+	}
+	if result < 0 {
+		return nil, false, repository.ErrTooManyReactions
+	}
+
+	reactions, err := r.reactionsOf(ctx, key)
+	return reactions, result == 1, err
+}
+
+func (r *RedisMessageRepository) RemoveReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string) ([]message.Reaction, bool, error) {
+	key := reactionsKey(roomID, id)
+
+	removed, err := r.client.ZRem(ctx, key, reactionMember(userID, emoji)).Result()
+	if err != nil {
+		return nil, false, err
+	}
+
+	reactions, err := r.reactionsOf(ctx, key)
+	return reactions, removed > 0, err
+}
+
+func (r *RedisMessageRepository) GetReactions(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID][]message.Reaction, error) {
+	reactions := make(map[uuid.UUID][]message.Reaction)
+	if len(ids) == 0 {
+		return reactions, nil
+	}
+
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.StringSliceCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.ZRange(ctx, reactionsKey(roomID, id), 0, -1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for i, cmd := range cmds {
+		members, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(members) > 0 {
+			reactions[ids[i]] = aggregateReactions(members)
+		}
+	}
+	return reactions, nil
+}
+
+func (r *RedisMessageRepository) reactionsOf(ctx context.Context, key string) ([]message.Reaction, error) {
+	members, err := r.client.ZRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return aggregateReactions(members), nil
+}
+
+// aggregateReactions는 반응한 순서로 정렬된 멤버를 이모지별로 모읍니다.
+func aggregateReactions(members []string) []message.Reaction {
+	reactions := []message.Reaction{}
+	index := make(map[string]int)
+
+	for _, member := range members {
+		cut := strings.LastIndex(member, "\n")
+		if cut < 0 {
+			continue
+		}
+		emoji, userID := member[:cut], member[cut+1:]
+
+		i, ok := index[emoji]
+		if !ok {
+			i = len(reactions)
+			index[emoji] = i
+			reactions = append(reactions, message.Reaction{Emoji: emoji})
+		}
+		reactions[i].Count++
+		reactions[i].UserIds = append(reactions[i].UserIds, userID)
+	}
+	return reactions
+}
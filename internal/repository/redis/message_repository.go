@@ -5,7 +5,6 @@ import (
 	"encoding/json"
 	"server/internal/models/message"
 	"server/internal/repository"
-	"strconv"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -13,116 +12,365 @@ import (
 
 const (
 	maxStreamLength = 5000
+	// pendingArchiveKey는 보관하지 않은 메시지가 있는 방 ID 집합입니다.
+	pendingArchiveKey = "stream:rooms:pending-archive"
 )
 
+func streamKey(roomID string) string {
+	return "stream:room:" + roomID + ":messages"
+}
+
+func lastEntryKey(roomID string) string {
+	return "stream:room:" + roomID + ":last-id"
+}
+
+// RedisMessageRepository는 방마다 Redis 스트림에 메시지를 저장합니다.
+// 보관소가 있으면 스트림은 최근 메시지의 캐시이고, 스트림 밖의 기록은 보관소에서 이어서 읽습니다.
 type RedisMessageRepository struct {
-	client *redis.Client
+	client  *redis.Client
+	archive repository.MessageArchiveRepository
 }
 
+// NewRedisMessageRepository는 스트림만 사용하는 저장소를 만듭니다. 스트림은 저장할 때마다 maxStreamLength개로 잘립니다.
 func NewRedisMessageRepository(client *redis.Client) repository.MessageRepository {
 	return &RedisMessageRepository{
 		client: client,
 	}
 }
 
+// NewTieredMessageRepository는 스트림을 캐시로, archive를 영구 저장소로 사용하는 저장소를 만듭니다.
+// 스트림은 저장할 때 잘리지 않으며, MessageArchiver가 보관을 확인한 뒤에 자릅니다.
+func NewTieredMessageRepository(client *redis.Client, archive repository.MessageArchiveRepository) repository.MessageRepository {
+	return &RedisMessageRepository{
+		client:  client,
+		archive: archive,
+	}
+}
+
 func (r *RedisMessageRepository) SaveMessage(ctx context.Context, roomID string, msg message.Message) error {
+	msgID := msg.GetID()
+	if msgID == uuid.Nil {
+		msgID, _ = uuid.NewV7()
+		msg.Base().Id = msgID
+	}
+	if msgID.Version() != 7 {
+		return repository.ErrInvalidMessageID
+	}
+
+	// 인용은 인용한 메시지의 ID만 저장하므로 인용한 메시지가 삭제되면 답장에도 본문이 남지 않음
+	if quote := msg.Base().ReplyTo; quote != nil {
+		msg.Base().ReplyTo = message.QuoteOf(quote.Id)
+		defer func() { msg.Base().ReplyTo = quote }()
+	}
 	msgJSON, err := json.Marshal(msg)
 	if err != nil {
 		return err
 	}
 
-	streamKey := "stream:room:" + roomID + ":messages"
+	key := streamKey(roomID)
+	keys := []string{key, lastEntryKey(roomID)}
+	if r.archive != nil {
+		keys = append(keys, pendingArchiveKey)
+	}
 
-	var msgID uuid.UUID
+	err = appendScript.Run(ctx, r.client, keys, uuidMillis(msgID), msgID.String(), string(msgJSON), roomID).Err()
+	if err != nil {
+		return err
+	}
 
-	if baseMsg, ok := msg.(*message.BaseMessage); ok {
-		msgID = baseMsg.Id
+	if threadID := msg.Base().ThreadId; threadID != nil {
+		if err := r.addToThread(ctx, roomID, *threadID, msg); err != nil {
+			return err
+		}
 	}
 
-	if msgID == uuid.Nil {
-		msgID, _ = uuid.NewV7()
+	if r.archive == nil {
+		r.client.XTrimMaxLen(ctx, key, maxStreamLength)
 	}
 
-	_, err = r.client.XAdd(ctx, &redis.XAddArgs{
-		Stream: streamKey,
-		ID:     msgID.String(),
-		Values: map[string]interface{}{
-			"message": string(msgJSON),
-		},
-	}).Result()
+	return nil
+}
 
+// GetMessages는 lastMessageID(밀리초 타임스탬프) 이후에 저장된 메시지를 오래된 순으로 최대 limit개 반환합니다.
+// lastMessageID가 0 이하이면 방의 처음부터 읽습니다.
+func (r *RedisMessageRepository) GetMessages(ctx context.Context, roomID string, lastMessageID int64, limit int) (message.Page, error) {
+	var after repository.StreamPosition
+	if lastMessageID > 0 {
+		after = repository.StreamPosition{Millis: lastMessageID, Seq: repository.MaxStreamPosition.Seq}
+	}
+
+	return r.pageAfter(ctx, roomID, after, limit)
+}
+
+// GetMessagesByUUID는 lastMessageUUID 이후의 메시지를 오래된 순으로 최대 limit개 반환합니다.
+// lastMessageUUID가 uuid.Nil이면 방의 처음부터 읽습니다.
+func (r *RedisMessageRepository) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID, limit int) (message.Page, error) {
+	var after repository.StreamPosition
+	if lastMessageUUID != uuid.Nil {
+		if lastMessageUUID.Version() != 7 {
+			return message.Page{}, repository.ErrInvalidMessageID
+		}
+
+		var err error
+		after, err = r.positionAfter(ctx, roomID, lastMessageUUID)
+		if err != nil {
+			return message.Page{}, err
+		}
+	}
+
+	return r.pageAfter(ctx, roomID, after, limit)
+}
+
+// pageAfter는 after보다 뒤의 메시지를 최대 limit개 읽고, 한 개를 더 읽어 남은 메시지가 있는지 판단합니다.
+func (r *RedisMessageRepository) pageAfter(ctx context.Context, roomID string, after repository.StreamPosition, limit int) (message.Page, error) {
+	entries, err := r.messagesAfter(ctx, roomID, after, limit+1)
 	if err != nil {
-		return err
+		return message.Page{}, err
 	}
 
-	r.client.XTrimMaxLen(ctx, streamKey, maxStreamLength)
+	hasMore := len(entries) > limit
+	if hasMore {
+		entries = entries[:limit]
+	}
 
-	return nil
+	messages := messagesOf(entries)
+	page := message.Page{Messages: messages, HasMore: hasMore}
+	if hasMore && len(messages) > 0 {
+		page.NextCursor = messages[len(messages)-1].GetID()
+	}
+
+	return page, nil
 }
 
-func (r *RedisMessageRepository) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	streamKey := "stream:room:" + roomID + ":messages"
+// ListMessages는 커서 기준으로 최대 Limit개의 메시지를 오래된 순서로 반환합니다.
+// 한 개를 더 읽어 같은 방향에 남은 메시지가 있는지 판단합니다.
+func (r *RedisMessageRepository) ListMessages(ctx context.Context, roomID string, query message.PageQuery) (message.Page, error) {
+	for _, cursor := range []uuid.UUID{query.Before, query.After} {
+		if cursor != uuid.Nil && cursor.Version() != 7 {
+			return message.Page{}, repository.ErrInvalidMessageID
+		}
+	}
+
+	count := query.Limit + 1
 
-	var start string
-	if lastMessageID <= 0 {
-		start = "-"
+	var entries []repository.StreamMessage
+	if query.After != uuid.Nil {
+		after, err := r.positionAfter(ctx, roomID, query.After)
+		if err != nil {
+			return message.Page{}, err
+		}
+		entries, err = r.messagesAfter(ctx, roomID, after, count)
+		if err != nil {
+			return message.Page{}, err
+		}
 	} else {
-		start = "(" + strconv.FormatInt(lastMessageID, 10)
+		before := repository.MaxStreamPosition
+		if query.Before != uuid.Nil {
+			var err error
+			before, err = r.positionBefore(ctx, roomID, query.Before)
+			if err != nil {
+				return message.Page{}, err
+			}
+		}
+		var err error
+		entries, err = r.messagesBefore(ctx, roomID, before, count)
+		if err != nil {
+			return message.Page{}, err
+		}
 	}
 
-	streams, err := r.client.XRange(ctx, streamKey, start, "+").Result()
+	hasMore := len(entries) > query.Limit
+	if hasMore {
+		entries = entries[:query.Limit]
+	}
+
+	// 이전 방향은 최신 순으로 읽었으므로 오래된 순서로 되돌림
+	if query.After == uuid.Nil {
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+	}
+
+	messages := messagesOf(entries)
+	page := message.Page{Messages: messages, HasMore: hasMore}
+	if hasMore && len(messages) > 0 {
+		if query.After != uuid.Nil {
+			page.NextCursor = messages[len(messages)-1].GetID()
+		} else {
+			page.NextCursor = messages[0].GetID()
+		}
+	}
+
+	return page, nil
+}
+
+// messagesAfter는 after보다 뒤의 메시지를 오래된 순으로 최대 count개 반환합니다.
+// 삭제된 메시지는 원래 자리에 툼스톤으로, 수정된 메시지는 최신 판으로 반환합니다.
+func (r *RedisMessageRepository) messagesAfter(ctx context.Context, roomID string, after repository.StreamPosition, count int) ([]repository.StreamMessage, error) {
+	entries, err := r.readAfter(ctx, roomID, after, count)
 	if err != nil {
 		return nil, err
 	}
 
-	messages, err := r.redisStreamToMessageList(streams)
+	// count개를 모두 읽었으면 읽은 구간 안의 툼스톤만 끼워 넣음
+	end := repository.MaxStreamPosition
+	if len(entries) >= count {
+		end = entries[len(entries)-1].Position
+	}
+	tombstones, err := tombstonesBetween(ctx, r.client, roomID, after, end)
 	if err != nil {
 		return nil, err
 	}
 
-	return messages, nil
+	entries = mergeTombstones(entries, tombstones, true)
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries, applyEdits(ctx, r.client, roomID, entries)
 }
 
-func (r *RedisMessageRepository) redisStreamToMessageList(streams []redis.XMessage) ([]message.Message, error) {
-	messages := make([]message.Message, 0, len(streams))
+// messagesBefore는 before보다 앞선 메시지를 최신 순으로 최대 count개 반환합니다.
+// 삭제된 메시지는 원래 자리에 툼스톤으로, 수정된 메시지는 최신 판으로 반환합니다.
+func (r *RedisMessageRepository) messagesBefore(ctx context.Context, roomID string, before repository.StreamPosition, count int) ([]repository.StreamMessage, error) {
+	entries, err := r.readBefore(ctx, roomID, before, count)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, stream := range streams {
-		msgStr, ok := stream.Values["message"].(string)
-		if !ok {
-			continue
-		}
+	var start repository.StreamPosition
+	if len(entries) >= count {
+		start = entries[len(entries)-1].Position
+	}
+	tombstones, err := tombstonesBetween(ctx, r.client, roomID, start, before)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = mergeTombstones(entries, tombstones, false)
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries, applyEdits(ctx, r.client, roomID, entries)
+}
 
-		var baseMsg message.BaseMessage
-		err := json.Unmarshal([]byte(msgStr), &baseMsg)
+// readAfter는 after보다 뒤의 항목을 오래된 순으로 최대 count개 읽습니다.
+// after가 스트림의 첫 항목보다 앞서면 잘려 나간 구간을 보관소에서 먼저 읽습니다.
+func (r *RedisMessageRepository) readAfter(ctx context.Context, roomID string, after repository.StreamPosition, count int) ([]repository.StreamMessage, error) {
+	key := streamKey(roomID)
+
+	var entries []repository.StreamMessage
+	if r.archive != nil {
+		first, err := r.client.XRangeN(ctx, key, "-", "+", 1).Result()
 		if err != nil {
 			return nil, err
 		}
 
-		messages = append(messages, &baseMsg)
+		trimmed := len(first) == 0
+		if !trimmed {
+			firstPosition, err := repository.ParseStreamPosition(first[0].ID)
+			if err != nil {
+				return nil, err
+			}
+			trimmed = after.Less(firstPosition)
+		}
+
+		if trimmed {
+			entries, err = r.archive.ListAfter(ctx, roomID, after, count)
+			if err != nil {
+				return nil, err
+			}
+			if len(entries) >= count {
+				return entries[:count], nil
+			}
+			// 보관 후 아직 잘리지 않은 항목은 스트림에도 있으므로 보관소에서 읽은 마지막 위치 다음부터 읽음
+			if len(entries) > 0 {
+				after = entries[len(entries)-1].Position
+			}
+		}
+	}
+
+	streams, err := r.client.XRangeN(ctx, key, "("+after.String(), "+", int64(count-len(entries))).Result()
+	if err != nil {
+		return nil, err
 	}
 
-	return messages, nil
+	recent, err := redisStreamToMessageList(streams)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = append(entries, recent...)
+	return entries, nil
 }
 
-func (r *RedisMessageRepository) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error) {
-	streamKey := "stream:room:" + roomID + ":messages"
+// readBefore는 before보다 앞선 항목을 최신 순으로 최대 count개 읽습니다.
+// 스트림의 첫 항목까지 읽고도 부족하면 나머지를 보관소에서 읽습니다.
+func (r *RedisMessageRepository) readBefore(ctx context.Context, roomID string, before repository.StreamPosition, count int) ([]repository.StreamMessage, error) {
+	end := "+"
+	if before != repository.MaxStreamPosition {
+		end = "(" + before.String()
+	}
 
-	var start string
-	if lastMessageUUID == uuid.Nil {
-		start = "-"
-	} else {
-		start = "(" + lastMessageUUID.String()
+	streams, err := r.client.XRevRangeN(ctx, streamKey(roomID), end, "-", int64(count)).Result()
+	if err != nil {
+		return nil, err
 	}
 
-	streams, err := r.client.XRange(ctx, streamKey, start, "+").Result()
+	entries, err := redisStreamToMessageList(streams)
 	if err != nil {
 		return nil, err
 	}
 
-	messages, err := r.redisStreamToMessageList(streams)
+	if r.archive == nil || len(streams) >= count {
+		return entries, nil
+	}
+
+	// 스트림에 남은 가장 오래된 항목 이전은 모두 보관소에 있음
+	if len(streams) > 0 {
+		before, err = repository.ParseStreamPosition(streams[len(streams)-1].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	older, err := r.archive.ListBefore(ctx, roomID, before, count-len(streams))
 	if err != nil {
 		return nil, err
 	}
 
-	return messages, nil
+	entries = append(entries, older...)
+	return entries, nil
+}
+
+func redisStreamToMessageList(streams []redis.XMessage) ([]repository.StreamMessage, error) {
+	entries := make([]repository.StreamMessage, 0, len(streams))
+
+	for _, stream := range streams {
+		msgStr, ok := stream.Values["message"].(string)
+		if !ok {
+			continue
+		}
+
+		position, err := repository.ParseStreamPosition(stream.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		msg, err := message.Decode([]byte(msgStr))
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, repository.StreamMessage{Position: position, Message: msg})
+	}
+
+	return entries, nil
+}
+
+func messagesOf(entries []repository.StreamMessage) []message.Message {
+	messages := make([]message.Message, len(entries))
+	for i, entry := range entries {
+		messages[i] = entry.Message
+	}
+	return messages
 }
@@ -0,0 +1,283 @@
+package redis
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 스레드:
+//
+// 답장은 방의 스트림에 다른 메시지처럼 저장하고, 루트 메시지마다 답장 ID를 점수가 모두 0인 정렬 집합에 모읍니다.
+// UUIDv7 문자열은 생성 순서대로 정렬되므로 사전 순 범위 조회로 답장을 시간 순서대로 페이지 단위로 읽습니다.
+// 참여자(루트 작성자, 답장 작성자, 스레드에 참여한 사용자)는 루트 메시지마다 집합에 둡니다.
+// 스트림에서 잘려 나간 답장과 참여자는 보관기가 보관소로 옮기므로(message_prune.go), 오래된 스레드는 Redis와 보관소를 함께 읽습니다.
+
+func threadKey(roomID string, rootID uuid.UUID) string {
+	return "stream:room:" + roomID + ":thread:" + rootID.String()
+}
+
+func threadParticipantsKey(roomID string, rootID uuid.UUID) string {
+	return "stream:room:" + roomID + ":thread:" + rootID.String() + ":participants"
+}
+
+// addToThread는 저장된 답장을 스레드에 추가하고 작성자를 참여자로 등록합니다.
+func (r *RedisMessageRepository) addToThread(ctx context.Context, roomID string, rootID uuid.UUID, reply message.Message) error {
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZAdd(ctx, threadKey(roomID, rootID), redis.Z{Score: 0, Member: reply.GetID().String()})
+		pipe.SAdd(ctx, threadParticipantsKey(roomID, rootID), reply.GetAuthor().Id)
+		if r.archive != nil {
+			pipe.SAdd(ctx, threadsKey(roomID), rootID.String())
+		}
+		return nil
+	})
+	return err
+}
+
+func (r *RedisMessageRepository) GetThread(ctx context.Context, roomID string, rootID uuid.UUID, query message.PageQuery) (message.Page, error) {
+	for _, cursor := range []uuid.UUID{rootID, query.Before, query.After} {
+		if cursor != uuid.Nil && cursor.Version() != 7 {
+			return message.Page{}, repository.ErrInvalidMessageID
+		}
+	}
+
+	key := threadKey(roomID, rootID)
+	// 한 개를 더 읽어 같은 방향에 남은 답장이 있는지 판단함
+	count := int64(query.Limit + 1)
+
+	var ids []string
+	var err error
+	if query.After != uuid.Nil {
+		ids, err = r.client.ZRangeByLex(ctx, key, &redis.ZRangeBy{Min: "(" + query.After.String(), Max: "+", Count: count}).Result()
+	} else {
+		max := "+"
+		if query.Before != uuid.Nil {
+			max = "(" + query.Before.String()
+		}
+		ids, err = r.client.ZRevRangeByLex(ctx, key, &redis.ZRangeBy{Min: "-", Max: max, Count: count}).Result()
+	}
+	if err != nil {
+		return message.Page{}, err
+	}
+
+	ids, err = r.withArchivedReplies(ctx, roomID, rootID, query, ids, int(count))
+	if err != nil {
+		return message.Page{}, err
+	}
+
+	hasMore := len(ids) > query.Limit
+	if hasMore {
+		ids = ids[:query.Limit]
+	}
+
+	// 이전 방향은 최신 순으로 읽었으므로 오래된 순서로 되돌림
+	if query.After == uuid.Nil {
+		for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+	}
+
+	replyIDs := make([]uuid.UUID, len(ids))
+	for i, idStr := range ids {
+		replyIDs[i], err = uuid.Parse(idStr)
+		if err != nil {
+			return message.Page{}, err
+		}
+	}
+	replies, err := r.GetMessagesByID(ctx, roomID, replyIDs)
+	if err != nil {
+		return message.Page{}, err
+	}
+
+	page := message.Page{Messages: []message.Message{}, HasMore: hasMore}
+	for _, id := range replyIDs {
+		if msg, ok := replies[id]; ok {
+			page.Messages = append(page.Messages, msg)
+		}
+	}
+
+	// 건너뛴 답장이 있어도 다음 커서는 읽은 ID 기준이므로 그대로 이어서 조회할 수 있음
+	if hasMore && len(ids) > 0 {
+		cursor := ids[0]
+		if query.After != uuid.Nil {
+			cursor = ids[len(ids)-1]
+		}
+		page.NextCursor, _ = uuid.Parse(cursor)
+	}
+
+	return page, nil
+}
+
+// withArchivedReplies는 Redis에서 읽은 답장 ID에 보관소로 옮겨진 답장 ID를 합쳐 같은 방향으로 최대 count개 반환합니다.
+// 옮겨진 답장은 pruned 위치보다 앞서고 Redis에 남은 답장은 그 뒤이므로 두 목록은 겹치지 않습니다.
+func (r *RedisMessageRepository) withArchivedReplies(ctx context.Context, roomID string, rootID uuid.UUID, query message.PageQuery, ids []string, count int) ([]string, error) {
+	if r.archive == nil {
+		return ids, nil
+	}
+	// 답장은 루트보다 뒤에 저장되므로 루트가 옮겨지지 않았으면 답장도 옮겨지지 않음
+	pruned, err := prunedPosition(ctx, r.client, roomID)
+	if err != nil || !mayBePruned(rootID, pruned) {
+		return ids, err
+	}
+
+	query.Limit = count
+	archived, err := r.archive.ThreadReplies(ctx, roomID, rootID, pruned, query)
+	if err != nil || len(archived) == 0 {
+		return ids, err
+	}
+	for _, id := range archived {
+		ids = append(ids, id.String())
+	}
+
+	if query.After != uuid.Nil {
+		sort.Strings(ids)
+	} else {
+		sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+	}
+	if len(ids) > count {
+		ids = ids[:count]
+	}
+	return ids, nil
+}
+
+func (r *RedisMessageRepository) GetThreadSummaries(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.ThreadSummary, error) {
+	summaries := make(map[uuid.UUID]message.ThreadSummary)
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	pipe := r.client.Pipeline()
+	counts := make([]*redis.IntCmd, len(ids))
+	lasts := make([]*redis.StringSliceCmd, len(ids))
+	for i, id := range ids {
+		counts[i] = pipe.ZCard(ctx, threadKey(roomID, id))
+		lasts[i] = pipe.ZRange(ctx, threadKey(roomID, id), -1, -1)
+	}
+	var prunedCmd *redis.StringCmd
+	if r.archive != nil {
+		prunedCmd = pipe.Get(ctx, prunedKey(roomID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	// 루트가 옮겨졌을 수 있는 스레드는 보관소로 옮겨진 답장을 함께 셈
+	archivedCounts := map[uuid.UUID]repository.ThreadCount{}
+	if prunedCmd != nil && prunedCmd.Val() != "" {
+		pruned, err := repository.ParseStreamPosition(prunedCmd.Val())
+		if err != nil {
+			return nil, err
+		}
+		var roots []uuid.UUID
+		for _, id := range ids {
+			if mayBePruned(id, pruned) {
+				roots = append(roots, id)
+			}
+		}
+		if len(roots) > 0 {
+			archivedCounts, err = r.archive.ThreadCounts(ctx, roomID, roots, pruned)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	lastIDs := make(map[uuid.UUID]uuid.UUID, len(ids))
+	var replyIDs []uuid.UUID
+	for i, id := range ids {
+		count, err := counts[i].Result()
+		if err != nil {
+			return nil, err
+		}
+		archived := archivedCounts[id]
+		if count == 0 && archived.Count == 0 {
+			continue
+		}
+
+		summaries[id] = message.ThreadSummary{ReplyCount: int(count) + archived.Count}
+		lastID := archived.LastReply
+		if last := lasts[i].Val(); len(last) > 0 {
+			lastID, err = uuid.Parse(last[0])
+			if err != nil {
+				return nil, err
+			}
+		}
+		if lastID != uuid.Nil {
+			lastIDs[id] = lastID
+			replyIDs = append(replyIDs, lastID)
+		}
+	}
+
+	// 마지막 답장은 한꺼번에 읽고, 보관 기간이 지나 사라진 답장은 요약에서 뺌
+	replies, err := r.GetMessagesByID(ctx, roomID, replyIDs)
+	if err != nil {
+		return nil, err
+	}
+	for id, lastID := range lastIDs {
+		if reply, ok := replies[lastID]; ok {
+			summary := summaries[id]
+			summary.LastReply = message.NewQuote(reply)
+			summaries[id] = summary
+		}
+	}
+	return summaries, nil
+}
+
+func (r *RedisMessageRepository) JoinThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	if r.archive == nil {
+		return r.client.SAdd(ctx, threadParticipantsKey(roomID, rootID), userID).Err()
+	}
+
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, threadParticipantsKey(roomID, rootID), userID)
+		pipe.SAdd(ctx, threadsKey(roomID), rootID.String())
+		return nil
+	})
+	return err
+}
+
+func (r *RedisMessageRepository) LeaveThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	if err := r.client.SRem(ctx, threadParticipantsKey(roomID, rootID), userID).Err(); err != nil {
+		return err
+	}
+	if r.archive == nil {
+		return nil
+	}
+
+	pruned, err := prunedPosition(ctx, r.client, roomID)
+	if err != nil || !mayBePruned(rootID, pruned) {
+		return err
+	}
+	return r.archive.RemoveThreadParticipant(ctx, roomID, rootID, userID)
+}
+
+func (r *RedisMessageRepository) ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error) {
+	participants, err := r.client.SMembers(ctx, threadParticipantsKey(roomID, rootID)).Result()
+	if err != nil || r.archive == nil {
+		return participants, err
+	}
+
+	pruned, err := prunedPosition(ctx, r.client, roomID)
+	if err != nil || !mayBePruned(rootID, pruned) {
+		return participants, err
+	}
+	archived, err := r.archive.ThreadParticipants(ctx, roomID, rootID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(participants))
+	for _, userID := range participants {
+		seen[userID] = true
+	}
+	for _, userID := range archived {
+		if !seen[userID] {
+			participants = append(participants, userID)
+		}
+	}
+	return participants, nil
+}
@@ -0,0 +1,164 @@
+package redis
+
+import (
+	"context"
+	"server/internal/models/presence"
+	"server/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPresenceRepository는 사용자마다 세션 ID -> 만료 시각(밀리초)을 정렬 집합에, 자리 비움 세션 ID를 집합에 둡니다.
+// 두 키는 하트비트마다 ttl로 만료를 연장하므로 모든 노드가 사라져도 결국 지워집니다.
+// 마지막 접속 시각과 공개 설정은 만료되지 않는 해시에 둡니다.
+type RedisPresenceRepository struct {
+	client *redis.Client
+}
+
+func NewRedisPresenceRepository(client *redis.Client) repository.PresenceRepository {
+	return &RedisPresenceRepository{
+		client: client,
+	}
+}
+
+func presenceSessionsKey(userID string) string {
+	return "presence:user:" + userID + ":sessions"
+}
+
+func presenceAwayKey(userID string) string {
+	return "presence:user:" + userID + ":away"
+}
+
+func presenceKey(userID string) string {
+	return "presence:user:" + userID
+}
+
+// presenceStatusLua는 만료된 세션을 지운 뒤 남은 세션으로 사용자의 상태를 계산합니다.
+const presenceStatusLua = `
+local function status(now)
+	redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+	local sessions = redis.call('ZRANGE', KEYS[1], 0, -1)
+	if #sessions == 0 then
+		return 'offline'
+	end
+	for _, session in ipairs(sessions) do
+		if redis.call('SISMEMBER', KEYS[2], session) == 0 then
+			return 'online'
+		end
+	end
+	return 'away'
+end
+`
+
+// heartbeatScript는 세션의 만료 시각과 상태를 기록하고 기록 전후 사용자의 상태를 반환합니다.
+// KEYS[1]: 세션 집합, KEYS[2]: 자리 비움 세션 집합, KEYS[3]: 접속 정보 해시,
+// ARGV[1]: 현재 시각(밀리초), ARGV[2]: 세션 ID, ARGV[3]: 자리 비움이면 "1", ARGV[4]: ttl(밀리초)
+var heartbeatScript = redis.NewScript(presenceStatusLua + `
+local before = status(ARGV[1])
+redis.call('ZADD', KEYS[1], tonumber(ARGV[1]) + tonumber(ARGV[4]), ARGV[2])
+if ARGV[3] == '1' then
+	redis.call('SADD', KEYS[2], ARGV[2])
+else
+	redis.call('SREM', KEYS[2], ARGV[2])
+end
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+redis.call('HSET', KEYS[3], 'lastSeen', ARGV[1])
+return {before, status(ARGV[1])}
+`)
+
+// removeSessionScript는 세션을 지우고 지우기 전후 사용자의 상태를 반환합니다.
+// KEYS는 heartbeatScript와 같고, ARGV[1]: 현재 시각(밀리초), ARGV[2]: 세션 ID
+var removeSessionScript = redis.NewScript(presenceStatusLua + `
+local before = status(ARGV[1])
+redis.call('ZREM', KEYS[1], ARGV[2])
+redis.call('SREM', KEYS[2], ARGV[2])
+if before ~= 'offline' then
+	redis.call('HSET', KEYS[3], 'lastSeen', ARGV[1])
+end
+return {before, status(ARGV[1])}
+`)
+
+func presenceKeys(userID string) []string {
+	return []string{presenceSessionsKey(userID), presenceAwayKey(userID), presenceKey(userID)}
+}
+
+func (r *RedisPresenceRepository) Heartbeat(ctx context.Context, userID, sessionID string, status presence.Status, now time.Time, ttl time.Duration) (before, after presence.Status, err error) {
+	away := "0"
+	if status == presence.StatusAway {
+		away = "1"
+	}
+
+	result, err := heartbeatScript.Run(ctx, r.client, presenceKeys(userID), now.UnixMilli(), sessionID, away, ttl.Milliseconds()).StringSlice()
+	if err != nil {
+		return "", "", err
+	}
+	return presence.Status(result[0]), presence.Status(result[1]), nil
+}
+
+func (r *RedisPresenceRepository) RemoveSession(ctx context.Context, userID, sessionID string, now time.Time) (before, after presence.Status, err error) {
+	result, err := removeSessionScript.Run(ctx, r.client, presenceKeys(userID), now.UnixMilli(), sessionID).StringSlice()
+	if err != nil {
+		return "", "", err
+	}
+	return presence.Status(result[0]), presence.Status(result[1]), nil
+}
+
+func (r *RedisPresenceRepository) GetPresence(ctx context.Context, userIDs []string, now time.Time) (map[string]presence.Record, error) {
+	records := make(map[string]presence.Record, len(userIDs))
+	if len(userIDs) == 0 {
+		return records, nil
+	}
+
+	live := "(" + strconv.FormatInt(now.UnixMilli(), 10)
+	pipe := r.client.Pipeline()
+	sessions := make([]*redis.StringSliceCmd, len(userIDs))
+	away := make([]*redis.StringSliceCmd, len(userIDs))
+	fields := make([]*redis.SliceCmd, len(userIDs))
+	for i, userID := range userIDs {
+		sessions[i] = pipe.ZRangeByScore(ctx, presenceSessionsKey(userID), &redis.ZRangeBy{Min: live, Max: "+inf"})
+		away[i] = pipe.SMembers(ctx, presenceAwayKey(userID))
+		fields[i] = pipe.HMGet(ctx, presenceKey(userID), "lastSeen", "hideLastSeen")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	for i, userID := range userIDs {
+		awaySessions := make(map[string]struct{})
+		for _, session := range away[i].Val() {
+			awaySessions[session] = struct{}{}
+		}
+
+		record := presence.Record{Status: presence.StatusOffline}
+		for _, session := range sessions[i].Val() {
+			if _, ok := awaySessions[session]; !ok {
+				record.Status = presence.StatusOnline
+				break
+			}
+			record.Status = presence.StatusAway
+		}
+
+		values := fields[i].Val()
+		if lastSeen, ok := values[0].(string); ok {
+			ms, err := strconv.ParseInt(lastSeen, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			record.LastSeen = time.UnixMilli(ms).UTC()
+		}
+		record.HideLastSeen = values[1] == "1"
+
+		records[userID] = record
+	}
+	return records, nil
+}
+
+func (r *RedisPresenceRepository) SetHideLastSeen(ctx context.Context, userID string, hide bool) error {
+	if hide {
+		return r.client.HSet(ctx, presenceKey(userID), "hideLastSeen", "1").Err()
+	}
+	return r.client.HDel(ctx, presenceKey(userID), "hideLastSeen").Err()
+}
@@ -0,0 +1,155 @@
+package redis
+
+import (
+	"context"
+	"server/internal/models/ratelimit"
+	"server/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRateLimitRepository는 버킷마다 남은 토큰 수와 마지막으로 채운 시각(밀리초)을 해시에 둡니다.
+// 버킷은 가득 차는 데 걸리는 시간이 지나면 만료되며, 만료된 버킷은 가득 찬 것으로 봅니다.
+// 제한을 넘긴 횟수와 차단이 끝나는 시각도 사용자마다 두고, 모두 전달받은 현재 시각을 기준으로 계산합니다.
+type RedisRateLimitRepository struct {
+	client *redis.Client
+}
+
+func NewRedisRateLimitRepository(client *redis.Client) repository.RateLimitRepository {
+	return &RedisRateLimitRepository{
+		client: client,
+	}
+}
+
+func rateLimitUserKey(userID string) string {
+	return "ratelimit:user:" + userID
+}
+
+func rateLimitRoomKey(roomID string) string {
+	return "ratelimit:room:" + roomID
+}
+
+func rateLimitStrikesKey(userID string) string {
+	return "ratelimit:user:" + userID + ":strikes"
+}
+
+func rateLimitBanKey(userID string) string {
+	return "ratelimit:user:" + userID + ":ban"
+}
+
+// takeTokenScript는 두 버킷에서 토큰을 하나씩 가져가고 {제한된 버킷 번호, 다시 보낼 수 있을 때까지 남은 시간(밀리초)}를 반환합니다.
+// 버킷 번호는 허용되면 0, 사용자 버킷이면 1, 채팅방 버킷이면 2, 차단되어 있으면 3입니다.
+// KEYS[1]: 사용자 버킷, KEYS[2]: 채팅방 버킷, KEYS[3]: 차단 키,
+// ARGV[1]: 현재 시각(밀리초), ARGV[2], ARGV[3]: 사용자 버킷 크기와 초당 토큰 수, ARGV[4], ARGV[5]: 채팅방 버킷 크기와 초당 토큰 수
+// 버킷 크기가 0이면 그 버킷은 제한하지 않음
+var takeTokenScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local bannedUntil = tonumber(redis.call('GET', KEYS[3]))
+if bannedUntil and bannedUntil > now then
+	return {3, bannedUntil - now}
+end
+
+local tokens = {}
+for i = 1, 2 do
+	local burst = tonumber(ARGV[i * 2])
+	local rate = tonumber(ARGV[i * 2 + 1])
+	if burst > 0 then
+		local state = redis.call('HMGET', KEYS[i], 'tokens', 'ts')
+		local available = burst
+		if state[1] then
+			available = math.min(burst, tonumber(state[1]) + math.max(0, now - tonumber(state[2])) * rate / 1000)
+		end
+		if available < 1 then
+			return {i, math.ceil((1 - available) * 1000 / rate)}
+		end
+		tokens[i] = available
+	end
+end
+
+for i, available in pairs(tokens) do
+	local burst = tonumber(ARGV[i * 2])
+	local rate = tonumber(ARGV[i * 2 + 1])
+	redis.call('HSET', KEYS[i], 'tokens', tostring(available - 1), 'ts', ARGV[1])
+	redis.call('PEXPIRE', KEYS[i], math.ceil(burst * 1000 / rate))
+end
+return {0, 0}
+`)
+
+// violationScript는 제한을 넘긴 횟수를 늘리고, strikes번에 이르면 사용자를 차단한 뒤 1을 반환합니다.
+// KEYS[1]: 횟수 해시, KEYS[2]: 차단 키,
+// ARGV[1]: 현재 시각(밀리초), ARGV[2]: 횟수를 세는 기간(밀리초), ARGV[3]: 차단할 횟수, ARGV[4]: 차단 기간(밀리초)
+var violationScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local since = tonumber(redis.call('HGET', KEYS[1], 'since'))
+if not since or now - since >= window then
+	redis.call('DEL', KEYS[1])
+	redis.call('HSET', KEYS[1], 'since', ARGV[1])
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+if redis.call('HINCRBY', KEYS[1], 'count', 1) < tonumber(ARGV[3]) then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('SET', KEYS[2], now + tonumber(ARGV[4]), 'PX', ARGV[4])
+return 1
+`)
+
+func (r *RedisRateLimitRepository) TakeMessageToken(ctx context.Context, userID, roomID string, userLimit, roomLimit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	args := []interface{}{now.UnixMilli()}
+	for _, limit := range []ratelimit.Limit{userLimit, roomLimit} {
+		if !limit.Enabled() {
+			limit = ratelimit.Limit{}
+		}
+		args = append(args, limit.Burst, limit.Rate)
+	}
+
+	keys := []string{rateLimitUserKey(userID), rateLimitRoomKey(roomID), rateLimitBanKey(userID)}
+	result, err := takeTokenScript.Run(ctx, r.client, keys, args...).Int64Slice()
+	if err != nil {
+		return ratelimit.Result{}, err
+	}
+
+	retryAfter := time.Duration(result[1]) * time.Millisecond
+	switch result[0] {
+	case 1:
+		return ratelimit.Result{Reason: ratelimit.ReasonUser, RetryAfter: retryAfter}, nil
+	case 2:
+		return ratelimit.Result{Reason: ratelimit.ReasonRoom, RetryAfter: retryAfter}, nil
+	case 3:
+		return ratelimit.Result{Reason: ratelimit.ReasonBanned, RetryAfter: retryAfter}, nil
+	}
+	return ratelimit.Result{Allowed: true}, nil
+}
+
+func (r *RedisRateLimitRepository) RecordViolation(ctx context.Context, userID string, now time.Time, window time.Duration, strikes int, banFor time.Duration) (bool, error) {
+	keys := []string{rateLimitStrikesKey(userID), rateLimitBanKey(userID)}
+	banned, err := violationScript.Run(ctx, r.client, keys, now.UnixMilli(), window.Milliseconds(), strikes, banFor.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return banned == 1, nil
+}
+
+func (r *RedisRateLimitRepository) BannedUntil(ctx context.Context, userID string, now time.Time) (time.Time, error) {
+	value, err := r.client.Get(ctx, rateLimitBanKey(userID)).Result()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	until := time.UnixMilli(ms).UTC()
+	if !until.After(now) {
+		return time.Time{}, nil
+	}
+	return until, nil
+}
@@ -0,0 +1,308 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 방마다 기록하는 최근 메시지 수. 읽지 않은 메시지 수는 이 값을 넘지 않음
+const maxSummaryMessages = 1000
+
+// RedisRoomSummaryRepository는 방마다 해시에 마지막 메시지 ID, 요약(Quote JSON), 활동 시각(밀리초)을 두고,
+// 최근 메시지 ID를 점수가 모두 0인 정렬 집합에 모읍니다. UUIDv7 문자열은 생성 순서대로 정렬되므로
+// 읽지 않은 메시지 수는 읽음 커서 이후의 사전 순 범위 개수에서 사용자가 숨긴 메시지를 빼서 구합니다.
+//
+// 사용자마다 속한 방을 활동 시각(밀리초)을 점수로 하는 정렬 집합에 두고, 방마다 목록이 만들어진 멤버를 집합에 둡니다.
+// 새 메시지는 방의 멤버 집합을 따라 각 멤버의 목록에 반영되므로 목록 조회는 한 페이지만 읽습니다.
+// 목록은 처음 조회할 때 BuildRoomList로 만들며, 그 전에는 멤버 집합에 넣지 않아 일부만 담긴 목록이 생기지 않습니다.
+type RedisRoomSummaryRepository struct {
+	client *redis.Client
+}
+
+func NewRedisRoomSummaryRepository(client *redis.Client) repository.RoomSummaryRepository {
+	return &RedisRoomSummaryRepository{
+		client: client,
+	}
+}
+
+func roomSummaryKey(roomID string) string {
+	return "summary:room:" + roomID
+}
+
+func roomSummaryMessagesKey(roomID string) string {
+	return "summary:room:" + roomID + ":messages"
+}
+
+func roomSummaryHiddenKey(roomID, userID string) string {
+	return "summary:room:" + roomID + ":hidden:" + userID
+}
+
+func roomSummaryMembersKey(roomID string) string {
+	return "summary:room:" + roomID + ":members"
+}
+
+func userRoomsKey(userID string) string {
+	return "summary:user:" + userID + ":rooms"
+}
+
+// recordSummaryScript는 메시지 ID를 최근 메시지에 추가하고, 마지막 메시지보다 뒤의 메시지일 때만 요약을 바꿉니다.
+// 여러 노드가 저장한 메시지가 순서가 바뀌어 도착해도 가장 최근 메시지가 남습니다.
+// KEYS[1]: 요약 해시, KEYS[2]: 최근 메시지 집합, ARGV[1]: 메시지 ID, ARGV[2]: Quote JSON, ARGV[3]: 밀리초 타임스탬프, ARGV[4]: 최근 메시지 수
+var recordSummaryScript = redis.NewScript(`
+redis.call('ZADD', KEYS[2], 0, ARGV[1])
+redis.call('ZREMRANGEBYRANK', KEYS[2], 0, -tonumber(ARGV[4]) - 1)
+local lastId = redis.call('HGET', KEYS[1], 'lastId')
+if not lastId or ARGV[1] > lastId then
+	redis.call('HSET', KEYS[1], 'lastId', ARGV[1], 'lastMessage', ARGV[2], 'lastActivity', ARGV[3])
+end
+return 1
+`)
+
+// refreshSummaryScript는 마지막 메시지가 ARGV[1]일 때만 요약을 바꿉니다.
+// KEYS[1]: 요약 해시, ARGV[1]: 메시지 ID, ARGV[2]: Quote JSON
+var refreshSummaryScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'lastId') == ARGV[1] then
+	redis.call('HSET', KEYS[1], 'lastMessage', ARGV[2])
+end
+return 1
+`)
+
+// unreadCountScript는 방마다 읽음 커서 이후의 메시지 수에서 사용자가 숨긴 메시지 수를 뺍니다.
+// 숨긴 메시지 중 삭제되었거나 최근 메시지에서 밀려난 메시지는 이미 세지 않으므로 빼지 않습니다.
+// KEYS[2i-1]: i번째 방의 최근 메시지 집합, KEYS[2i]: 사용자가 숨긴 메시지 집합, ARGV[i]: 사전 순 범위의 시작
+var unreadCountScript = redis.NewScript(`
+local counts = {}
+for i = 1, #ARGV do
+	local messages = KEYS[2 * i - 1]
+	local count = redis.call('ZLEXCOUNT', messages, ARGV[i], '+')
+	for _, id in ipairs(redis.call('ZRANGEBYLEX', KEYS[2 * i], ARGV[i], '+')) do
+		if redis.call('ZSCORE', messages, id) then
+			count = count - 1
+		end
+	end
+	counts[i] = count
+end
+return counts
+`)
+
+// addRoomsScript는 방들을 사용자의 목록에 넣고 사용자를 방의 멤버 집합에 넣습니다.
+// 점수는 방의 마지막 활동 시각이며, 메시지가 없는 방이면 방을 만든 시각입니다.
+// ARGV[2]가 "1"이면 목록이 이미 있을 때만 넣습니다.
+// KEYS[1]: 사용자의 목록, KEYS[2i], KEYS[2i+1]: i번째 방의 멤버 집합과 요약 해시
+// ARGV[1]: 사용자 ID, ARGV[2]: 목록이 있을 때만 넣을지, ARGV[2i+1], ARGV[2i+2]: i번째 방 ID와 만든 시각(밀리초)
+var addRoomsScript = redis.NewScript(`
+if ARGV[2] == '1' and redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+for i = 1, (#KEYS - 1) / 2 do
+	local activity = redis.call('HGET', KEYS[2 * i + 1], 'lastActivity') or ARGV[2 * i + 2]
+	redis.call('SADD', KEYS[2 * i], ARGV[1])
+	redis.call('ZADD', KEYS[1], 'GT', activity, ARGV[2 * i + 1])
+end
+return 1
+`)
+
+// listRoomsScript는 사용자의 목록에서 커서 뒤의 방을 활동 시각의 역순으로 읽습니다.
+// 커서와 활동 시각이 같은 방은 방 ID가 커서보다 작은 것만 커서 뒤에 있으므로 나머지는 건너뜁니다.
+// KEYS[1]: 사용자의 목록, ARGV[1]: 커서의 활동 시각(밀리초, 처음부터면 +inf), ARGV[2]: 커서의 방 ID(처음부터면 빈 문자열), ARGV[3]: 개수
+var listRoomsScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return false
+end
+local offset = 0
+if ARGV[2] ~= '' then
+	for _, roomId in ipairs(redis.call('ZRANGEBYSCORE', KEYS[1], ARGV[1], ARGV[1])) do
+		if roomId >= ARGV[2] then
+			offset = offset + 1
+		end
+	end
+end
+return redis.call('ZREVRANGEBYSCORE', KEYS[1], ARGV[1], '-inf', 'WITHSCORES', 'LIMIT', offset, ARGV[3])
+`)
+
+func (r *RedisRoomSummaryRepository) RecordMessage(ctx context.Context, roomID string, msg message.Message) error {
+	id := msg.GetID()
+	if id.Version() != 7 {
+		return repository.ErrInvalidMessageID
+	}
+
+	quoteJSON, err := json.Marshal(message.NewQuote(msg))
+	if err != nil {
+		return err
+	}
+
+	ms := uuidMillis(id)
+	keys := []string{roomSummaryKey(roomID), roomSummaryMessagesKey(roomID)}
+	if err := recordSummaryScript.Run(ctx, r.client, keys, id.String(), string(quoteJSON), ms, maxSummaryMessages).Err(); err != nil {
+		return err
+	}
+
+	members, err := r.client.SMembers(ctx, roomSummaryMembersKey(roomID)).Result()
+	if err != nil || len(members) == 0 {
+		return err
+	}
+
+	// 늦게 도착한 이전 메시지가 활동 시각을 되돌리지 않도록 GT, 그 사이 방에서 나간 멤버의 목록에 다시 넣지 않도록 XX
+	pipe := r.client.Pipeline()
+	for _, userID := range members {
+		pipe.ZAddArgs(ctx, userRoomsKey(userID), redis.ZAddArgs{
+			XX:      true,
+			GT:      true,
+			Members: []redis.Z{{Score: float64(ms), Member: roomID}},
+		})
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisRoomSummaryRepository) RefreshLastMessage(ctx context.Context, roomID string, msg message.Message) error {
+	quoteJSON, err := json.Marshal(message.NewQuote(msg))
+	if err != nil {
+		return err
+	}
+
+	return refreshSummaryScript.Run(ctx, r.client, []string{roomSummaryKey(roomID)}, msg.GetID().String(), string(quoteJSON)).Err()
+}
+
+func (r *RedisRoomSummaryRepository) RemoveMessage(ctx context.Context, roomID string, id uuid.UUID) error {
+	return r.client.ZRem(ctx, roomSummaryMessagesKey(roomID), id.String()).Err()
+}
+
+func (r *RedisRoomSummaryRepository) HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error {
+	key := roomSummaryHiddenKey(roomID, userID)
+	pipe := r.client.Pipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: 0, Member: id.String()})
+	// 최근 메시지에서 밀려난 메시지는 세지 않으므로 숨긴 메시지도 같은 수만 남김
+	pipe.ZRemRangeByRank(ctx, key, 0, -maxSummaryMessages-1)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisRoomSummaryRepository) GetSummaries(ctx context.Context, userID string, readCursors map[string]uuid.UUID) (map[string]message.RoomSummary, error) {
+	summaries := make(map[string]message.RoomSummary)
+	if len(readCursors) == 0 {
+		return summaries, nil
+	}
+
+	pipe := r.client.Pipeline()
+	fields := make(map[string]*redis.SliceCmd, len(readCursors))
+	roomIDs := make([]string, 0, len(readCursors))
+	keys := make([]string, 0, 2*len(readCursors))
+	mins := make([]interface{}, 0, len(readCursors))
+	for roomID, cursor := range readCursors {
+		fields[roomID] = pipe.HMGet(ctx, roomSummaryKey(roomID), "lastMessage", "lastActivity")
+		min := "-"
+		if cursor != uuid.Nil {
+			min = "(" + cursor.String()
+		}
+		roomIDs = append(roomIDs, roomID)
+		keys = append(keys, roomSummaryMessagesKey(roomID), roomSummaryHiddenKey(roomID, userID))
+		mins = append(mins, min)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	counts, err := unreadCountScript.Run(ctx, r.client, keys, mins...).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	unread := make(map[string]int64, len(roomIDs))
+	for i, roomID := range roomIDs {
+		unread[roomID] = counts[i]
+	}
+
+	for roomID, cmd := range fields {
+		values, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		// 메시지가 저장된 적 없는 방
+		quoteJSON, ok := values[0].(string)
+		if !ok {
+			continue
+		}
+		activity, _ := values[1].(string)
+
+		var quote message.Quote
+		if err := json.Unmarshal([]byte(quoteJSON), &quote); err != nil {
+			return nil, err
+		}
+		ms, err := strconv.ParseInt(activity, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		summaries[roomID] = message.RoomSummary{
+			LastMessage:  &quote,
+			LastActivity: time.UnixMilli(ms).UTC(),
+			UnreadCount:  int(unread[roomID]),
+		}
+	}
+	return summaries, nil
+}
+
+func (r *RedisRoomSummaryRepository) BuildRoomList(ctx context.Context, userID string, rooms map[string]time.Time) error {
+	return r.addRooms(ctx, userID, rooms, false)
+}
+
+func (r *RedisRoomSummaryRepository) AddMember(ctx context.Context, roomID, userID string, createdAt time.Time) error {
+	return r.addRooms(ctx, userID, map[string]time.Time{roomID: createdAt}, true)
+}
+
+func (r *RedisRoomSummaryRepository) addRooms(ctx context.Context, userID string, rooms map[string]time.Time, onlyExisting bool) error {
+	if len(rooms) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, 1+2*len(rooms))
+	args := make([]interface{}, 0, 2+2*len(rooms))
+	keys = append(keys, userRoomsKey(userID))
+	args = append(args, userID, onlyExisting)
+	for roomID, createdAt := range rooms {
+		keys = append(keys, roomSummaryMembersKey(roomID), roomSummaryKey(roomID))
+		args = append(args, roomID, createdAt.UnixMilli())
+	}
+	return addRoomsScript.Run(ctx, r.client, keys, args...).Err()
+}
+
+func (r *RedisRoomSummaryRepository) RemoveMember(ctx context.Context, roomID, userID string) error {
+	pipe := r.client.TxPipeline()
+	pipe.SRem(ctx, roomSummaryMembersKey(roomID), userID)
+	pipe.ZRem(ctx, userRoomsKey(userID), roomID)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func (r *RedisRoomSummaryRepository) ListRooms(ctx context.Context, userID string, after *message.RoomActivity, limit int) ([]message.RoomActivity, error) {
+	max, afterRoomID := "+inf", ""
+	if after != nil {
+		max = strconv.FormatInt(after.LastActivity.UnixMilli(), 10)
+		afterRoomID = after.RoomID
+	}
+
+	values, err := listRoomsScript.Run(ctx, r.client, []string{userRoomsKey(userID)}, max, afterRoomID, limit).StringSlice()
+	if err == redis.Nil {
+		return nil, repository.ErrRoomListNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rooms := make([]message.RoomActivity, 0, len(values)/2)
+	for i := 0; i+1 < len(values); i += 2 {
+		score, err := strconv.ParseFloat(values[i+1], 64)
+		if err != nil {
+			return nil, err
+		}
+		rooms = append(rooms, message.RoomActivity{RoomID: values[i], LastActivity: time.UnixMilli(int64(score)).UTC()})
+	}
+	return rooms, nil
+}
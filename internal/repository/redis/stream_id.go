@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"server/internal/repository"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// 메시지 ID와 스트림 항목 ID의 관계:
+//
+// 스트림 항목 ID는 "<ms>-<seq>"이며, ms는 메시지 UUIDv7에 담긴 밀리초 타임스탬프입니다.
+// 같은 밀리초에 저장된 메시지는 seq를 1씩 늘려 구분합니다. 다른 노드의 시계가 늦어
+// 스트림의 마지막 항목보다 이른 타임스탬프가 오면 스트림 순서를 지키기 위해 마지막 항목의 ms에 이어 붙입니다.
+// 따라서 항목의 ms는 항상 메시지 타임스탬프 이상이고, 항목에 저장한 "id" 값으로 원래 메시지를 찾습니다.
+
+// appendScript는 메시지 타임스탬프로 항목 ID를 정해 원자적으로 추가합니다.
+// 마지막 항목이 삭제되어도 그보다 작은 ID를 만들지 않도록 마지막으로 만든 ID를 따로 기록합니다.
+// KEYS[1]: 스트림 키, KEYS[2]: 마지막 항목 ID 키, KEYS[3]: 보관 대기 방 집합(선택), ARGV[1]: 밀리초 타임스탬프, ARGV[2]: 메시지 ID, ARGV[3]: 메시지 JSON, ARGV[4]: 방 ID
+var appendScript = redis.NewScript(`
+local ms = ARGV[1]
+local seq = 0
+local lastId = redis.call('GET', KEYS[2])
+if not lastId then
+	local last = redis.call('XREVRANGE', KEYS[1], '+', '-', 'COUNT', 1)
+	if #last > 0 then
+		lastId = last[1][1]
+	end
+end
+if lastId then
+	local dash = string.find(lastId, '-', 1, true)
+	local lastMs = string.sub(lastId, 1, dash - 1)
+	if tonumber(ms) <= tonumber(lastMs) then
+		ms = lastMs
+		seq = tonumber(string.sub(lastId, dash + 1)) + 1
+	end
+end
+local id = redis.call('XADD', KEYS[1], ms .. '-' .. string.format('%d', seq), 'id', ARGV[2], 'message', ARGV[3])
+redis.call('SET', KEYS[2], id)
+if #KEYS > 2 then
+	redis.call('SADD', KEYS[3], ARGV[4])
+end
+return id
+`)
+
+// cursorScanBatch는 커서 메시지를 찾을 때 한 번에 읽는 항목 수입니다.
+const cursorScanBatch = 100
+
+// uuidMillis는 UUIDv7의 앞 48비트에 담긴 밀리초 타임스탬프를 반환합니다.
+func uuidMillis(id uuid.UUID) int64 {
+	var ms int64
+	for _, b := range id[:6] {
+		ms = ms<<8 | int64(b)
+	}
+	return ms
+}
+
+// findEntryID는 메시지 ID가 저장된 스트림 항목 ID를 찾습니다.
+// 메시지 타임스탬프의 첫 항목부터 훑어 "id"가 일치하는 항목을 찾으며, 잘려 나간 경우 등 없으면 found가 false입니다.
+func (r *RedisMessageRepository) findEntryID(ctx context.Context, streamKey string, id uuid.UUID) (entryID string, found bool, err error) {
+	start := strconv.FormatInt(uuidMillis(id), 10) + "-0"
+	target := id.String()
+
+	for {
+		entries, err := r.client.XRangeN(ctx, streamKey, start, "+", cursorScanBatch).Result()
+		if err != nil {
+			return "", false, err
+		}
+
+		for _, entry := range entries {
+			if entry.Values["id"] == target {
+				return entry.ID, true, nil
+			}
+		}
+
+		if len(entries) < cursorScanBatch {
+			return "", false, nil
+		}
+		start = "(" + entries[len(entries)-1].ID
+	}
+}
+
+// locate는 메시지의 스트림 위치를 찾습니다. 스트림에서 잘려 나간 메시지는 보관소에서 찾습니다.
+func (r *RedisMessageRepository) locate(ctx context.Context, roomID string, id uuid.UUID) (position repository.StreamPosition, found bool, err error) {
+	key := streamKey(roomID)
+
+	// 스트림의 첫 항목보다 이른 메시지는 대부분 잘려 나간 것이므로 스트림을 훑기 전에 보관소에서 찾음
+	if r.archive != nil {
+		first, err := r.client.XRangeN(ctx, key, "-", "+", 1).Result()
+		if err != nil {
+			return repository.StreamPosition{}, false, err
+		}
+		if len(first) == 0 || uuidMillis(id) < entryMillis(first[0].ID) {
+			position, found, err = r.archive.Position(ctx, roomID, id)
+			if err != nil || found {
+				return position, found, err
+			}
+		}
+	}
+
+	entryID, found, err := r.findEntryID(ctx, key, id)
+	if err != nil {
+		return repository.StreamPosition{}, false, err
+	}
+	// 삭제된 메시지는 스트림에 없고 툼스톤으로 남음
+	if !found {
+		return r.findTombstone(ctx, roomID, id)
+	}
+
+	position, err = repository.ParseStreamPosition(entryID)
+	return position, err == nil, err
+}
+
+// entryMillis는 스트림 항목 ID의 밀리초 부분을 반환합니다.
+func entryMillis(entryID string) int64 {
+	position, _ := repository.ParseStreamPosition(entryID)
+	return position.Millis
+}
+
+// positionAfter는 메시지 다음부터 읽기 위한 위치를 반환합니다.
+// 메시지를 찾지 못하면 해당 밀리초의 첫 항목부터 읽도록 그 직전 위치를 반환합니다.
+func (r *RedisMessageRepository) positionAfter(ctx context.Context, roomID string, id uuid.UUID) (repository.StreamPosition, error) {
+	position, found, err := r.locate(ctx, roomID, id)
+	if err != nil {
+		return repository.StreamPosition{}, err
+	}
+	if !found {
+		if uuidMillis(id) == 0 {
+			return repository.StreamPosition{}, nil
+		}
+		return repository.StreamPosition{Millis: uuidMillis(id) - 1, Seq: math.MaxInt64}, nil
+	}
+	return position, nil
+}
+
+// positionBefore는 메시지 이전 항목까지 읽기 위한 위치를 반환합니다.
+// 메시지를 찾지 못하면 해당 밀리초 이전 항목부터 읽습니다.
+func (r *RedisMessageRepository) positionBefore(ctx context.Context, roomID string, id uuid.UUID) (repository.StreamPosition, error) {
+	position, found, err := r.locate(ctx, roomID, id)
+	if err != nil {
+		return repository.StreamPosition{}, err
+	}
+	if !found {
+		return repository.StreamPosition{Millis: uuidMillis(id)}, nil
+	}
+	return position, nil
+}
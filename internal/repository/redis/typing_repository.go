@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"context"
+	"server/internal/repository"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 타이핑 상태 키의 유효 기간. 어떤 노드도 만료를 처리하지 못해도 남은 항목이 결국 지워짐
+const typingKeyTTL = time.Hour
+
+// RedisTypingRepository는 채팅방마다 사용자 ID -> 타이핑 상태 만료 시각(밀리초)을 정렬 집합에 둡니다.
+// 만료 시각이 지난 항목은 목록에서 빠지지만, 타이핑을 받은 노드가 ExpireTyping으로 지울 때까지 남겨 둡니다.
+// 미리 지우면 그 노드가 상태가 바뀌었음을 알 수 없기 때문입니다.
+type RedisTypingRepository struct {
+	client *redis.Client
+}
+
+func NewRedisTypingRepository(client *redis.Client) repository.TypingRepository {
+	return &RedisTypingRepository{
+		client: client,
+	}
+}
+
+func typingKey(roomID string) string {
+	return "typing:room:" + roomID
+}
+
+// typingScript는 사용자의 타이핑 상태를 바꾸고 {바뀌었으면 1, 타이핑 중인 사용자 ID...}를 반환합니다.
+// KEYS[1]: 타이핑 집합, ARGV[1]: "start", "stop", "expire" 중 하나, ARGV[2]: 사용자 ID,
+// ARGV[3]: 현재 시각(밀리초), ARGV[4]: 만료 시각(밀리초, start만), ARGV[5]: 키의 유효 기간(밀리초)
+var typingScript = redis.NewScript(`
+local now = tonumber(ARGV[3])
+local score = redis.call('ZSCORE', KEYS[1], ARGV[2])
+local active = score and tonumber(score) > now
+local changed = 0
+if ARGV[1] == 'start' then
+	if not active then
+		changed = 1
+	end
+	redis.call('ZADD', KEYS[1], ARGV[4], ARGV[2])
+	redis.call('PEXPIRE', KEYS[1], ARGV[5])
+elseif ARGV[1] == 'stop' then
+	if active then
+		changed = 1
+	end
+	redis.call('ZREM', KEYS[1], ARGV[2])
+elseif score and not active then
+	changed = 1
+	redis.call('ZREM', KEYS[1], ARGV[2])
+end
+local result = {changed}
+for _, userId in ipairs(redis.call('ZRANGEBYSCORE', KEYS[1], '(' .. ARGV[3], '+inf')) do
+	table.insert(result, userId)
+end
+return result
+`)
+
+func (r *RedisTypingRepository) run(ctx context.Context, op, roomID, userID string, now, expiresAt time.Time) ([]string, bool, error) {
+	result, err := typingScript.Run(ctx, r.client, []string{typingKey(roomID)}, op, userID, now.UnixMilli(), expiresAt.UnixMilli(), typingKeyTTL.Milliseconds()).Slice()
+	if err != nil {
+		return nil, false, err
+	}
+
+	typing := make([]string, 0, len(result)-1)
+	for _, userID := range result[1:] {
+		typing = append(typing, userID.(string))
+	}
+	return typing, result[0].(int64) == 1, nil
+}
+
+func (r *RedisTypingRepository) StartTyping(ctx context.Context, roomID, userID string, now, expiresAt time.Time) ([]string, bool, error) {
+	return r.run(ctx, "start", roomID, userID, now, expiresAt)
+}
+
+func (r *RedisTypingRepository) StopTyping(ctx context.Context, roomID, userID string, now time.Time) ([]string, bool, error) {
+	return r.run(ctx, "stop", roomID, userID, now, now)
+}
+
+func (r *RedisTypingRepository) ExpireTyping(ctx context.Context, roomID, userID string, now time.Time) ([]string, bool, error) {
+	return r.run(ctx, "expire", roomID, userID, now, now)
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"math"
+	"server/internal/models/message"
+	"strconv"
+	"strings"
+)
+
+// StreamPosition은 방의 메시지 스트림에서 메시지의 위치(Redis 스트림 항목 ID "<ms>-<seq>")입니다.
+// Redis 스트림과 Postgres 보관소가 같은 위치로 메시지를 정렬하므로 두 저장소를 이어서 읽을 수 있습니다.
+type StreamPosition struct {
+	Millis int64
+	Seq    int64
+}
+
+// MaxStreamPosition은 모든 메시지보다 뒤에 있는 위치입니다.
+var MaxStreamPosition = StreamPosition{Millis: math.MaxInt64, Seq: math.MaxInt64}
+
+// ParseStreamPosition은 "<ms>-<seq>" 형식의 스트림 항목 ID를 위치로 변환합니다.
+func ParseStreamPosition(entryID string) (StreamPosition, error) {
+	ms, seq, ok := strings.Cut(entryID, "-")
+	if !ok {
+		return StreamPosition{}, errors.New("invalid stream entry ID: " + entryID)
+	}
+
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return StreamPosition{}, err
+	}
+	sequence, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return StreamPosition{}, err
+	}
+
+	return StreamPosition{Millis: millis, Seq: sequence}, nil
+}
+
+func (p StreamPosition) String() string {
+	return strconv.FormatInt(p.Millis, 10) + "-" + strconv.FormatInt(p.Seq, 10)
+}
+
+// Less는 p가 other보다 앞선 위치인지 반환합니다.
+func (p StreamPosition) Less(other StreamPosition) bool {
+	if p.Millis != other.Millis {
+		return p.Millis < other.Millis
+	}
+	return p.Seq < other.Seq
+}
+
+// StreamMessage는 스트림 위치와 함께 읽은 메시지입니다.
+type StreamMessage struct {
+	Position StreamPosition
+	Message  message.Message
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// 연결마다 쌓아둘 수 있는 송신 메시지 수
+	sendQueueSize = 256
+	// 느린 소비자에게 종료 프레임을 보낼 때 기다리는 최대 시간
+	closeGracePeriod = time.Second
+)
+
+// 느린 소비자 정책:
+// 송신 큐가 가득 찬 연결은 메시지를 버리거나 기다리지 않고 즉시 끊습니다.
+// 한 클라이언트가 느려도 같은 방의 다른 클라이언트 전송이 지연되지 않으며,
+// 끊긴 클라이언트는 재접속 후 /messages로 놓친 메시지를 다시 받아야 합니다.
+const (
+	closeSlowConsumer       = websocket.CloseTryAgainLater
+	closeSlowConsumerReason = "slow consumer"
+
+	closeTimedOut       = websocket.CloseGoingAway
+	closeTimedOutReason = "connection timed out"
+)
+
+// 전달 확인 묶음:
+// 소켓에 쓴 메시지마다 전달 커서를 옮기지 않고, 방과 작성자별로 마지막 메시지만 모아
+// deliveryFlushInterval마다 또는 메시지를 deliveryFlushCount개 쓸 때마다 한 번에 넘깁니다.
+// 커서는 누적이므로 작성자별 마지막 메시지로만 옮겨도 됩니다.
+const (
+	deliveryFlushInterval = 500 * time.Millisecond
+	deliveryFlushCount    = 64
+)
+
+// outbound는 송신 큐의 한 항목입니다.
+type outbound struct {
+	data []byte
+	// 메시지 이벤트면 소켓에 쓴 뒤 전달 커서를 옮길 수 있도록 메시지를 가리킴. 다른 이벤트는 nil
+	delivery *delivery
+}
+
+// delivery는 소켓에 쓴 메시지 이벤트의 방, 메시지 ID, 작성자입니다.
+type delivery struct {
+	roomID    string
+	messageID uuid.UUID
+	authorID  string
+}
+
+type deliveryKey struct {
+	roomID   string
+	authorID string
+}
+
+// deliveryBatch는 소켓에 썼지만 아직 넘기지 않은 전달을 방과 작성자별로 모읍니다. writePump에서만 사용합니다.
+type deliveryBatch struct {
+	latest  map[deliveryKey]delivery
+	written int
+}
+
+func (b *deliveryBatch) add(d delivery) {
+	if b.latest == nil {
+		b.latest = make(map[deliveryKey]delivery)
+	}
+	key := deliveryKey{roomID: d.roomID, authorID: d.authorID}
+	if current, ok := b.latest[key]; !ok || bytes.Compare(current.messageID[:], d.messageID[:]) < 0 {
+		b.latest[key] = d
+	}
+	b.written++
+}
+
+// client는 하나의 WebSocket 연결과 전용 쓰기 고루틴을 묶습니다.
+// gorilla 연결은 동시 쓰기를 지원하지 않으므로 모든 쓰기는 writePump를 통해서만 이루어집니다.
+type client struct {
+	conn *websocket.Conn
+	// 연결마다 발급되는 세션 ID. 같은 사용자의 여러 기기를 구분함
+	sessionID string
+	userID    string
+	// 구독 중인 방 목록. ChatServiceImpl.connectionMutex로 보호됨
+	rooms map[string]struct{}
+
+	config ChatConfig
+	// 마지막으로 프레임(pong 포함)을 받은 시각과, 응답을 기다리는 ping을 보낸 시각 (UnixNano, 없으면 0)
+	lastSeen   atomic.Int64
+	pingSentAt atomic.Int64
+	// 클라이언트가 presence 프레임으로 자리 비움을 알렸는지 여부
+	away atomic.Bool
+	// 세션에서 빠졌는지 여부. 빠진 뒤에 끝난 하트비트가 세션을 되살리지 않도록 확인함
+	removed atomic.Bool
+
+	send chan outbound
+	done chan struct{}
+	// 소켓에 쓴 다른 사용자의 메시지를 묶어 넘김. 쓰기를 막지 않도록 별도 고루틴에서 실행됨
+	delivered func(userID string, deliveries []delivery)
+
+	// 재전송 중인 방 ID -> 재전송이 끝날 때까지 보류한 실시간 이벤트
+	replayMutex sync.Mutex
+	replaying   map[string][]outbound
+
+	closeOnce   sync.Once
+	closeCode   int
+	closeReason string
+}
+
+func newClient(conn *websocket.Conn, userID string, config ChatConfig, delivered func(userID string, deliveries []delivery)) *client {
+	c := &client{
+		conn:      conn,
+		sessionID: uuid.NewString(),
+		userID:    userID,
+		rooms:     make(map[string]struct{}),
+		replaying: make(map[string][]outbound),
+		config:    config,
+		send:      make(chan outbound, sendQueueSize),
+		done:      make(chan struct{}),
+		delivered: delivered,
+	}
+
+	c.touch()
+	conn.SetReadLimit(config.MaxMessageSize)
+	conn.SetPongHandler(func(string) error {
+		c.pingSentAt.Store(0)
+		c.touch()
+		return nil
+	})
+
+	return c
+}
+
+// touch는 클라이언트로부터 프레임을 받았음을 기록하고 읽기 데드라인을 연장합니다.
+func (c *client) touch() {
+	now := c.config.Clock.Now()
+	c.lastSeen.Store(now.UnixNano())
+	c.conn.SetReadDeadline(now.Add(c.config.ReadTimeout))
+}
+
+// expired는 pong 대기 시간이나 읽기 타임아웃이 지났는지 확인합니다.
+func (c *client) expired(now time.Time) bool {
+	if pingSentAt := c.pingSentAt.Load(); pingSentAt != 0 && now.Sub(time.Unix(0, pingSentAt)) > c.config.PongWait {
+		return true
+	}
+	return now.Sub(time.Unix(0, c.lastSeen.Load())) > c.config.ReadTimeout
+}
+
+// enqueue는 메시지를 송신 큐에 넣습니다. 큐가 가득 찼거나 이미 닫힌 경우 false를 반환합니다.
+func (c *client) enqueue(item outbound) bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+	}
+
+	select {
+	case c.send <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// enqueueWait는 송신 큐에 자리가 날 때까지 기다렸다가 메시지를 넣습니다.
+// 재전송처럼 이 연결만을 위한 대량 전송에 사용하며, 연결이 닫히면 false를 반환합니다.
+func (c *client) enqueueWait(item outbound) bool {
+	select {
+	case c.send <- item:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// deliver는 방의 실시간 이벤트를 전달합니다. 해당 방을 재전송하는 중이면 송신 큐 대신 보류 목록에 쌓습니다.
+// 보류 목록도 송신 큐와 같은 크기로 제한되며, 넘치면 false를 반환합니다.
+func (c *client) deliver(roomID string, item outbound) bool {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+
+	if pending, ok := c.replaying[roomID]; ok {
+		if len(pending) >= sendQueueSize {
+			return false
+		}
+		c.replaying[roomID] = append(pending, item)
+		return true
+	}
+
+	return c.enqueue(item)
+}
+
+// beginReplay는 방의 실시간 이벤트를 보류하기 시작합니다.
+func (c *client) beginReplay(roomID string) {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+
+	c.replaying[roomID] = nil
+}
+
+// takePending은 보류된 이벤트를 꺼냅니다. 보류된 이벤트가 없으면 재전송을 끝내고 done으로 true를 반환하며,
+// 이후 이벤트는 바로 송신 큐로 전달됩니다.
+func (c *client) takePending(roomID string) (pending []outbound, done bool) {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+
+	pending = c.replaying[roomID]
+	if len(pending) == 0 {
+		delete(c.replaying, roomID)
+		return nil, true
+	}
+
+	c.replaying[roomID] = nil
+	return pending, false
+}
+
+// cancelReplay는 재전송을 중단하고 보류된 이벤트를 버립니다.
+func (c *client) cancelReplay(roomID string) {
+	c.replayMutex.Lock()
+	defer c.replayMutex.Unlock()
+
+	delete(c.replaying, roomID)
+}
+
+// close는 쓰기 고루틴에 종료 프레임 전송과 연결 종료를 요청합니다. 여러 번 호출해도 안전하며,
+// 처음 호출된 경우에만 true를 반환합니다.
+func (c *client) close(code int, reason string) bool {
+	closed := false
+	c.closeOnce.Do(func() {
+		c.closeCode = code
+		c.closeReason = reason
+		close(c.done)
+		closed = true
+	})
+	return closed
+}
+
+// disconnect는 쓰기 고루틴이 막혀 있더라도 연결을 끊습니다.
+// 종료 프레임 전송을 잠시 시도한 뒤 연결을 강제로 닫으며, 호출한 쪽은 기다리지 않습니다.
+// 이미 닫히는 중인 연결이면 아무것도 하지 않고 false를 반환합니다.
+func (c *client) disconnect(code int, reason string) bool {
+	if !c.close(code, reason) {
+		return false
+	}
+
+	go func() {
+		// WriteControl과 Close는 다른 쓰기와 동시에 호출해도 안전함
+		c.conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(code, reason),
+			time.Now().Add(closeGracePeriod),
+		)
+		c.conn.Close()
+	}()
+	return true
+}
+
+// flushDeliveries는 모은 전달을 넘기고 묶음을 비웁니다.
+func (c *client) flushDeliveries(batch *deliveryBatch) {
+	if len(batch.latest) == 0 || c.delivered == nil {
+		*batch = deliveryBatch{}
+		return
+	}
+
+	deliveries := make([]delivery, 0, len(batch.latest))
+	for _, d := range batch.latest {
+		deliveries = append(deliveries, d)
+	}
+	*batch = deliveryBatch{}
+	go c.delivered(c.userID, deliveries)
+}
+
+func (c *client) writePump() {
+	ticker := c.config.Clock.NewTicker(c.config.PingInterval)
+	flushTicker := c.config.Clock.NewTicker(deliveryFlushInterval)
+	var deliveries deliveryBatch
+	defer func() {
+		ticker.Stop()
+		flushTicker.Stop()
+		// 끊기기 전에 쓴 메시지도 전달된 것으로 반영함
+		c.flushDeliveries(&deliveries)
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case item := <-c.send:
+			c.conn.SetWriteDeadline(c.config.Clock.Now().Add(c.config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.TextMessage, item.data); err != nil {
+				// 연결을 닫으면 읽기 루프가 끝나면서 정리 작업이 진행됨
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+			// 자신이 보낸 메시지는 전달 확인 대상이 아님
+			if item.delivery != nil && item.delivery.authorID != c.userID {
+				deliveries.add(*item.delivery)
+				if deliveries.written >= deliveryFlushCount {
+					c.flushDeliveries(&deliveries)
+				}
+			}
+		case <-flushTicker.C():
+			c.flushDeliveries(&deliveries)
+		case <-ticker.C():
+			now := c.config.Clock.Now()
+			// 이전 ping의 응답을 기다리는 중이면 그 시각을 유지함
+			c.pingSentAt.CompareAndSwap(0, now.UnixNano())
+
+			c.conn.SetWriteDeadline(now.Add(c.config.WriteTimeout))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.close(websocket.CloseAbnormalClosure, "")
+				return
+			}
+		case <-c.done:
+			c.conn.WriteControl(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(c.closeCode, c.closeReason),
+				c.config.Clock.Now().Add(c.config.WriteTimeout),
+			)
+			return
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"server/internal/models/ratelimit"
+	"server/pkg/clock"
+	"time"
+)
+
+// ChatConfig는 WebSocket 연결의 하트비트, 타임아웃과 메시지 전송 설정입니다.
+type ChatConfig struct {
+	// 서버가 ping을 보내는 주기
+	PingInterval time.Duration
+	// ping을 보낸 뒤 pong을 기다리는 시간. 이 안에 pong이 없으면 연결을 정리함
+	PongWait time.Duration
+	// pong을 포함해 어떤 프레임도 받지 못한 채 유지할 수 있는 최대 시간
+	ReadTimeout time.Duration
+	// 프레임 하나를 쓰는 데 허용하는 시간
+	WriteTimeout time.Duration
+	// 클라이언트가 보낼 수 있는 프레임의 최대 크기(바이트)
+	MaxMessageSize int64
+	// 같은 클라이언트 메시지 ID로 재시도한 메시지를 중복으로 인식하는 기간
+	DedupWindow time.Duration
+	// 작성자가 메시지를 보낸 뒤 수정할 수 있는 기간. 0이면 제한하지 않음
+	EditWindow time.Duration
+	// 작성자가 메시지를 보낸 뒤 모두에게서 삭제할 수 있는 기간. 0이면 제한하지 않음
+	DeleteWindow time.Duration
+	// 한 사용자가 한 메시지에 남길 수 있는 서로 다른 반응 수
+	MaxReactionsPerUser int
+	// 접속 상태 하트비트의 유효 기간. 노드가 사라져 하트비트가 끊기면 이 시간 뒤에 offline이 됨
+	PresenceTTL time.Duration
+	// typing 프레임을 받은 뒤 타이핑 상태를 유지하는 시간. 갱신되지 않으면 타이핑을 멈춘 것으로 봄
+	TypingTTL time.Duration
+	// 같은 사용자가 같은 방에 보낸 typing 프레임을 처리하는 최소 간격. 그 안에 다시 보낸 시작 프레임은 무시함
+	TypingThrottle time.Duration
+	// 한 사용자가 보내는 메시지의 토큰 버킷. 모든 연결과 채팅방에서 보낸 메시지를 합쳐 셈
+	UserMessageLimit ratelimit.Limit
+	// 한 채팅방에 보내는 메시지의 토큰 버킷. 모든 멤버가 보낸 메시지를 합쳐 셈
+	RoomMessageLimit ratelimit.Limit
+	// RateLimitStrikeWindow 안에 사용자의 버킷이 비어 제한된 횟수가 RateLimitStrikes에 이르면
+	// 연결을 끊고 RateLimitBan 동안 다시 접속하지 못하게 함. RateLimitStrikes나 RateLimitBan이 0이면 끊지 않음
+	RateLimitStrikes      int
+	RateLimitStrikeWindow time.Duration
+	RateLimitBan          time.Duration
+
+	Clock clock.Clock
+}
+
+func DefaultChatConfig() ChatConfig {
+	return ChatConfig{
+		PingInterval:          30 * time.Second,
+		PongWait:              10 * time.Second,
+		ReadTimeout:           90 * time.Second,
+		WriteTimeout:          10 * time.Second,
+		MaxMessageSize:        64 * 1024,
+		DedupWindow:           10 * time.Minute,
+		EditWindow:            15 * time.Minute,
+		DeleteWindow:          time.Hour,
+		MaxReactionsPerUser:   3,
+		PresenceTTL:           90 * time.Second,
+		TypingTTL:             6 * time.Second,
+		TypingThrottle:        2 * time.Second,
+		UserMessageLimit:      ratelimit.Limit{Burst: 10, Rate: 1},
+		RoomMessageLimit:      ratelimit.Limit{Burst: 50, Rate: 20},
+		RateLimitStrikes:      10,
+		RateLimitStrikeWindow: time.Minute,
+		RateLimitBan:          time.Minute,
+		Clock:                 clock.New(),
+	}
+}
+
+// presenceInterval은 접속 상태 하트비트를 갱신하는 주기입니다. 한두 번 놓쳐도 만료되지 않도록 유효 기간의 3분의 1마다 갱신합니다.
+func (c ChatConfig) presenceInterval() time.Duration {
+	return c.PresenceTTL / 3
+}
+
+// typingSweepInterval은 만료된 타이핑 상태를 찾는 주기입니다.
+func (c ChatConfig) typingSweepInterval() time.Duration {
+	return c.TypingTTL / 4
+}
+
+// reapInterval은 끊긴 연결을 찾는 주기입니다. pong 대기 시간의 절반마다 확인합니다.
+func (c ChatConfig) reapInterval() time.Duration {
+	return c.PongWait / 2
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrDeleteWindowExpired는 모두에게서 삭제할 수 있는 기간이 지난 메시지를 삭제하려 할 때 반환됩니다.
+var ErrDeleteWindowExpired = errors.New("delete window has expired")
+
+// DeleteMessage는 작성자가 보낸 메시지를 모두에게서 삭제하고 방에 messageDeleted 이벤트를 보냅니다.
+// 기록에는 원래 자리에 툼스톤이 남습니다. 이미 삭제된 메시지면 이벤트 없이 툼스톤을 반환합니다.
+func (s *ChatServiceImpl) DeleteMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) (message.Message, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messageRepo.GetMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetAuthor().Id != userID {
+		return nil, ErrNotMessageAuthor
+	}
+	if deleted, ok := msg.(*message.DeletedMessage); ok {
+		return deleted, nil
+	}
+
+	now := s.config.Clock.Now()
+	if s.config.DeleteWindow > 0 && now.Sub(messageTime(messageID)) > s.config.DeleteWindow {
+		return nil, ErrDeleteWindowExpired
+	}
+
+	tombstone := message.NewTombstone(msg, now.Format(time.RFC3339))
+	if err := s.messageRepo.DeleteMessage(ctx, roomID, tombstone); err != nil {
+		return nil, err
+	}
+
+	s.broadcastMessageDeleted(ctx, roomID, tombstone)
+	s.refreshSummary(ctx, roomID, tombstone)
+	s.excludeFromUnread(ctx, roomID, "", messageID)
+
+	return tombstone, nil
+}
+
+// HideMessage는 사용자에게만 메시지를 숨깁니다. 숨긴 메시지는 이후 이 사용자의 기록 조회와 재전송에서 빠집니다.
+func (s *ChatServiceImpl) HideMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return err
+	}
+
+	if _, err := s.messageRepo.GetMessage(ctx, roomID, messageID); err != nil {
+		return err
+	}
+
+	if err := s.messageRepo.HideMessage(ctx, roomID, userID, messageID); err != nil {
+		return err
+	}
+	s.excludeFromUnread(ctx, roomID, userID, messageID)
+	return nil
+}
+
+// withoutHidden은 roomID 방의 메시지 중 사용자가 숨긴 메시지를 뺀 목록을 반환합니다.
+func withoutHidden(ctx context.Context, messageRepo repository.MessageRepository, roomID, userID string, messages []message.Message) ([]message.Message, error) {
+	ids := make([]uuid.UUID, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.GetID()
+	}
+
+	hidden, err := messageRepo.HiddenMessages(ctx, roomID, userID, ids)
+	if err != nil || len(hidden) == 0 {
+		return messages, err
+	}
+
+	visible := make([]message.Message, 0, len(messages)-len(hidden))
+	for _, msg := range messages {
+		if !hidden[msg.GetID()] {
+			visible = append(visible, msg)
+		}
+	}
+	return visible, nil
+}
+
+func (s *ChatServiceImpl) broadcastMessageDeleted(ctx context.Context, roomID string, tombstone *message.DeletedMessage) {
+	deletedEvent := map[string]interface{}{
+		"type":      "messageDeleted",
+		"roomId":    roomID,
+		"messageId": tombstone.Id,
+		"deletedAt": tombstone.DeletedAt,
+	}
+
+	msgJSON, _ := json.Marshal(deletedEvent)
+	s.broadcastChange(ctx, roomID, threadRoot(tombstone), msgJSON)
+}
+
+// deleteFromFrame은 delete 프레임을 처리하고 결과를 ack 또는 error 이벤트로 알립니다.
+func (s *ChatServiceImpl) deleteFromFrame(ctx context.Context, c *client, frame WebSocketMessage) {
+	// 스키마에서 UUID 형식을 검증함
+	messageID, _ := uuid.Parse(frame.MessageId)
+
+	receipt := message.Receipt{MessageId: messageID}
+	if frame.ForEveryone {
+		tombstone, err := s.DeleteMessage(ctx, frame.RoomId, c.userID, messageID)
+		if err != nil {
+			s.sendChangeError(c, frame, err)
+			return
+		}
+		receipt.Timestamp = tombstone.(*message.DeletedMessage).DeletedAt
+	} else {
+		if err := s.HideMessage(ctx, frame.RoomId, c.userID, messageID); err != nil {
+			s.sendChangeError(c, frame, err)
+			return
+		}
+		receipt.Timestamp = s.config.Clock.Now().Format(time.RFC3339)
+	}
+
+	s.sendAck(c, frame.RoomId, frame.ClientMessageId, receipt)
+}
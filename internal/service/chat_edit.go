@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"server/internal/models/message"
+	"server/internal/protocol"
+	"server/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotMessageAuthor는 작성자가 아닌 사용자가 메시지를 바꾸려 할 때 반환됩니다.
+	ErrNotMessageAuthor = errors.New("only the author can change the message")
+	// ErrEditWindowExpired는 수정할 수 있는 기간이 지난 메시지를 수정하려 할 때 반환됩니다.
+	ErrEditWindowExpired = errors.New("edit window has expired")
+	// ErrMessageNotEditable은 본문이 없는 메시지를 수정하려 할 때 반환됩니다.
+	ErrMessageNotEditable = errors.New("message type cannot be edited")
+)
+
+// messageTime은 메시지 ID(UUIDv7)에 담긴 작성 시각을 반환합니다.
+func messageTime(id uuid.UUID) time.Time {
+	sec, nsec := id.Time().UnixTime()
+	return time.Unix(sec, nsec)
+}
+
+// EditMessage는 작성자가 보낸 텍스트 메시지의 본문과 멘션을 바꾸고 방에 messageEdited 이벤트를 보냅니다.
+// 설정된 수정 가능 기간이 지났으면 ErrEditWindowExpired를 반환합니다. 새로 멘션된 사용자에게만 mention 이벤트를 보냅니다.
+func (s *ChatServiceImpl) EditMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID, content string, mentions []message.Mention) (message.Message, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messageRepo.GetMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.GetAuthor().Id != userID {
+		return nil, ErrNotMessageAuthor
+	}
+	text, ok := msg.(*message.TextMessage)
+	if !ok {
+		return nil, ErrMessageNotEditable
+	}
+
+	now := s.config.Clock.Now()
+	if s.config.EditWindow > 0 && now.Sub(messageTime(messageID)) > s.config.EditWindow {
+		return nil, ErrEditWindowExpired
+	}
+
+	if err := s.validateMentions(ctx, roomID, content, mentions); err != nil {
+		return nil, err
+	}
+
+	previous := message.Revision{Content: text.Content, Timestamp: text.Timestamp}
+	if text.EditedAt != "" {
+		previous.Timestamp = text.EditedAt
+	}
+
+	edited := *text
+	edited.Content = content
+	edited.Mentions = mentions
+	edited.EditedAt = now.Format(time.RFC3339)
+
+	if err := s.messageRepo.EditMessage(ctx, roomID, &edited, previous); err != nil {
+		return nil, err
+	}
+
+	s.broadcastMessageEdited(ctx, roomID, &edited)
+	s.recordMentions(ctx, roomID, &edited, text.Mentions)
+	s.refreshSummary(ctx, roomID, &edited)
+
+	return &edited, nil
+}
+
+// GetMessageRevisions는 메시지 본문의 모든 판을 오래된 순으로 반환합니다. 마지막 항목이 현재 본문입니다.
+func (s *ChatServiceImpl) GetMessageRevisions(ctx context.Context, roomID, userID string, messageID uuid.UUID) ([]message.Revision, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messageRepo.GetMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := msg.(*message.TextMessage)
+	if !ok {
+		return nil, ErrMessageNotEditable
+	}
+
+	revisions, err := s.messageRepo.GetRevisions(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	current := message.Revision{Content: text.Content, Timestamp: text.Timestamp}
+	if text.EditedAt != "" {
+		current.Timestamp = text.EditedAt
+	}
+
+	return append(revisions, current), nil
+}
+
+func (s *ChatServiceImpl) broadcastMessageEdited(ctx context.Context, roomID string, msg *message.TextMessage) {
+	// 멘션이 지워졌음을 알 수 있도록 항상 배열로 보냄
+	mentions := msg.Mentions
+	if mentions == nil {
+		mentions = []message.Mention{}
+	}
+
+	editedEvent := map[string]interface{}{
+		"type":      "messageEdited",
+		"roomId":    roomID,
+		"messageId": msg.Id,
+		"content":   msg.Content,
+		"mentions":  mentions,
+		"editedAt":  msg.EditedAt,
+	}
+
+	msgJSON, _ := json.Marshal(editedEvent)
+	s.broadcastChange(ctx, roomID, threadRoot(msg), msgJSON)
+}
+
+// editFromFrame은 edit 프레임을 처리하고 결과를 ack 또는 error 이벤트로 알립니다.
+func (s *ChatServiceImpl) editFromFrame(ctx context.Context, c *client, frame WebSocketMessage) {
+	// 스키마에서 UUID 형식을 검증함
+	messageID, _ := uuid.Parse(frame.MessageId)
+
+	edited, err := s.EditMessage(ctx, frame.RoomId, c.userID, messageID, frame.Content, frame.Mentions)
+	if err != nil {
+		s.sendChangeError(c, frame, err)
+		return
+	}
+
+	s.sendAck(c, frame.RoomId, frame.ClientMessageId, message.Receipt{MessageId: edited.GetID(), Timestamp: edited.Base().EditedAt})
+}
+
+// sendChangeError는 이미 보낸 메시지를 바꾸는 프레임의 오류를 error 이벤트로 보냅니다.
+func (s *ChatServiceImpl) sendChangeError(c *client, frame WebSocketMessage, err error) {
+	switch {
+	case errors.Is(err, repository.ErrMessageNotFound), errors.Is(err, repository.ErrInvalidMessageID):
+		s.sendError(c, frame, protocol.ErrorCodeMessageNotFound, "message not found")
+	case errors.Is(err, ErrNotMessageAuthor):
+		s.sendError(c, frame, protocol.ErrorCodeNotAuthor, err.Error())
+	case errors.Is(err, ErrEditWindowExpired):
+		s.sendError(c, frame, protocol.ErrorCodeEditWindowExpired, err.Error())
+	case errors.Is(err, ErrMessageNotEditable):
+		s.sendError(c, frame, protocol.ErrorCodeNotEditable, err.Error())
+	case errors.Is(err, ErrDeleteWindowExpired):
+		s.sendError(c, frame, protocol.ErrorCodeDeleteWindowExpired, err.Error())
+	case errors.Is(err, repository.ErrTooManyReactions):
+		s.sendError(c, frame, protocol.ErrorCodeTooManyReactions, err.Error())
+	case errors.Is(err, message.ErrInvalidMention):
+		s.sendError(c, frame, protocol.ErrorCodeInvalidMention, err.Error())
+	default:
+		log.Println("Error changing message:", err)
+		s.sendError(c, frame, protocol.ErrorCodeStorageFailure, "failed to change message")
+	}
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"server/internal/models/message"
+	"server/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// 멘션 목록을 한 번에 조회하는 수의 기본값과 최댓값
+	defaultMentionPageLimit = 20
+	maxMentionPageLimit     = 100
+)
+
+// validateMentions는 멘션 구간을 확인하고, 멘션한 사용자가 채팅방 멤버인지 확인합니다.
+func (s *ChatServiceImpl) validateMentions(ctx context.Context, roomID, content string, mentions []message.Mention) error {
+	if err := message.ValidateMentions(content, mentions); err != nil {
+		return err
+	}
+
+	for _, mention := range mentions {
+		if mention.Type != message.MentionTypeUser {
+			continue
+		}
+		err := s.membership.check(ctx, roomID, mention.UserId)
+		var notMemberErr *NotRoomMemberError
+		if errors.As(err, &notMemberErr) {
+			return fmt.Errorf("%w: user %s is not a member of the room", message.ErrInvalidMention, mention.UserId)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mentionRecipients는 멘션으로 알릴 사용자 ID를 반환합니다. @all은 채팅방의 모든 멤버로 펼치며, 작성자는 빠집니다.
+func (s *ChatServiceImpl) mentionRecipients(ctx context.Context, roomID, authorID string, mentions []message.Mention) ([]string, error) {
+	seen := map[string]bool{authorID: true}
+	var userIDs []string
+	add := func(userID string) {
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+
+	expanded := false
+	for _, mention := range mentions {
+		switch mention.Type {
+		case message.MentionTypeUser:
+			add(mention.UserId)
+		case message.MentionTypeAll:
+			if expanded {
+				continue
+			}
+			expanded = true
+
+			roomUUID, err := uuid.Parse(roomID)
+			if err != nil {
+				return nil, err
+			}
+			members, err := s.roomRepo.GetRoomUserIDs(ctx, roomUUID)
+			if err != nil {
+				return nil, err
+			}
+			for _, member := range members {
+				add(member.String())
+			}
+		}
+	}
+	return userIDs, nil
+}
+
+// recordMentions는 저장된 텍스트 메시지로 멘션된 사용자의 멘션 목록과 읽지 않은 멘션 수를 갱신하고 mention 이벤트를 보냅니다.
+// 수정된 메시지면 previous로 이미 멘션된 사용자는 빼고 새로 멘션된 사용자에게만 알립니다.
+// 메시지는 이미 저장되었으므로 실패해도 기록만 남깁니다.
+func (s *ChatServiceImpl) recordMentions(ctx context.Context, roomID string, msg message.Message, previous []message.Mention) {
+	text, ok := msg.(*message.TextMessage)
+	if !ok || len(text.Mentions) == 0 {
+		return
+	}
+
+	authorID := text.GetAuthor().Id
+	recipients, err := s.mentionRecipients(ctx, roomID, authorID, text.Mentions)
+	if err == nil && len(previous) > 0 {
+		var notified []string
+		notified, err = s.mentionRecipients(ctx, roomID, authorID, previous)
+		recipients = without(recipients, notified)
+	}
+	if err != nil {
+		log.Println("Error resolving mentions:", err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	if err := s.mentionRepo.AddMention(ctx, roomID, text.GetID(), recipients); err != nil {
+		log.Println("Error recording mentions:", err)
+		return
+	}
+
+	mentionEvent := map[string]interface{}{
+		"type":      "mention",
+		"roomId":    roomID,
+		"messageId": text.GetID(),
+		"message":   text,
+	}
+
+	msgJSON, _ := json.Marshal(mentionEvent)
+	// 채팅방 구독이나 알림 설정과 관계없이 사용자의 모든 연결에 보냄
+	s.sendToUsers("", recipients, msgJSON)
+}
+
+// without은 userIDs에서 excluded에 있는 ID를 뺀 목록을 반환합니다.
+func without(userIDs, excluded []string) []string {
+	skip := make(map[string]bool, len(excluded))
+	for _, userID := range excluded {
+		skip[userID] = true
+	}
+
+	var remaining []string
+	for _, userID := range userIDs {
+		if !skip[userID] {
+			remaining = append(remaining, userID)
+		}
+	}
+	return remaining
+}
+
+// ListMentions는 사용자가 멘션된 메시지를 최신 순으로 조회하고 방별 읽지 않은 멘션 수를 함께 반환합니다.
+// 나간 채팅방의 멘션과 삭제되거나 숨긴 메시지는 빠집니다.
+func (s *ChatServiceImpl) ListMentions(ctx context.Context, userID string, query message.MentionQuery) (message.MentionPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultMentionPageLimit
+	}
+	if query.Limit > maxMentionPageLimit {
+		query.Limit = maxMentionPageLimit
+	}
+
+	// 한 개를 더 읽어 남은 멘션이 있는지 판단함
+	refs, err := s.mentionRepo.ListMentions(ctx, userID, query.Before, query.Limit+1)
+	if err != nil {
+		return message.MentionPage{}, err
+	}
+
+	page := message.MentionPage{Messages: []message.Message{}, HasMore: len(refs) > query.Limit}
+	if page.HasMore {
+		refs = refs[:query.Limit]
+		page.NextCursor = refs[len(refs)-1].MessageId
+	}
+
+	for _, ref := range refs {
+		msg, err := s.mentionedMessage(ctx, userID, ref)
+		if err != nil {
+			return message.MentionPage{}, err
+		}
+		if msg != nil {
+			page.Messages = append(page.Messages, msg)
+		}
+	}
+
+	page.UnreadCounts, err = s.mentionRepo.UnreadCounts(ctx, userID)
+	if err != nil {
+		return message.MentionPage{}, err
+	}
+	return page, nil
+}
+
+// mentionedMessage는 멘션 목록의 메시지를 사용자에게 보여 줄 형태로 읽습니다. 보여 줄 수 없는 메시지면 nil을 반환합니다.
+func (s *ChatServiceImpl) mentionedMessage(ctx context.Context, userID string, ref message.MentionRef) (message.Message, error) {
+	err := s.membership.check(ctx, ref.RoomId, userID)
+	var notMemberErr *NotRoomMemberError
+	if errors.As(err, &notMemberErr) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := s.messageRepo.GetMessage(ctx, ref.RoomId, ref.MessageId)
+	if errors.Is(err, repository.ErrMessageNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := msg.(*message.DeletedMessage); ok {
+		return nil, nil
+	}
+
+	visible, err := s.historyFor(ctx, ref.RoomId, userID, []message.Message{msg})
+	if err != nil || len(visible) == 0 {
+		return nil, err
+	}
+	return visible[0], nil
+}
+
+// ReadMentions는 채팅방의 읽지 않은 멘션 수를 0으로 만듭니다.
+func (s *ChatServiceImpl) ReadMentions(ctx context.Context, roomID, userID string) error {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return err
+	}
+	return s.mentionRepo.ClearUnread(ctx, userID, roomID)
+}
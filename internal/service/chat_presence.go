@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"server/internal/models/presence"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 접속 상태:
+//
+// 연결마다 PresenceTTL 동안 유효한 하트비트를 Redis에 기록하고, 연결을 가진 노드가 유효 기간의 3분의 1마다 갱신합니다.
+// 사용자의 상태는 살아 있는 연결로 정해지며(하나라도 online이면 online, 모두 away이면 away, 없으면 offline),
+// 상태가 바뀌면 그 사용자를 친구로 추가한 사용자에게 presence 이벤트를 보냅니다.
+// 노드가 갑자기 사라지면 그 노드의 연결은 유효 기간이 지나 offline이 되지만 이벤트는 나가지 않습니다.
+
+// heartbeat는 연결이 살아 있음과 연결의 상태를 기록하고, 사용자의 상태가 바뀌었으면 친구에게 알립니다.
+func (s *ChatServiceImpl) heartbeat(c *client) {
+	status := presence.StatusOnline
+	if c.away.Load() {
+		status = presence.StatusAway
+	}
+
+	ctx := context.Background()
+	before, after, err := s.presenceRepo.Heartbeat(ctx, c.userID, c.sessionID, status, s.config.Clock.Now(), s.config.PresenceTTL)
+	if err != nil {
+		log.Println("Error recording presence heartbeat:", err)
+		return
+	}
+
+	// 하트비트 도중 세션에서 빠졌으면 방금 기록한 세션을 다시 지움
+	if c.removed.Load() {
+		s.removePresence(c)
+		return
+	}
+	if before != after {
+		s.notifyPresence(ctx, c.userID)
+	}
+}
+
+// removePresence는 끊긴 연결을 접속 상태에서 지우고, 사용자의 상태가 바뀌었으면 친구에게 알립니다.
+func (s *ChatServiceImpl) removePresence(c *client) {
+	c.removed.Store(true)
+
+	ctx := context.Background()
+	before, after, err := s.presenceRepo.RemoveSession(ctx, c.userID, c.sessionID, s.config.Clock.Now())
+	if err != nil {
+		log.Println("Error removing presence session:", err)
+		return
+	}
+	if before != after {
+		s.notifyPresence(ctx, c.userID)
+	}
+}
+
+// refreshPresence는 이 노드의 모든 연결의 하트비트를 주기적으로 갱신합니다.
+func (s *ChatServiceImpl) refreshPresence() {
+	ticker := s.config.Clock.NewTicker(s.config.presenceInterval())
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		s.connectionMutex.RLock()
+		clients := make([]*client, 0, len(s.sessions))
+		for _, c := range s.sessions {
+			clients = append(clients, c)
+		}
+		s.connectionMutex.RUnlock()
+
+		for _, c := range clients {
+			s.heartbeat(c)
+		}
+	}
+}
+
+// notifyPresence는 사용자의 현재 접속 상태를 그 사용자를 친구로 추가한 사용자의 모든 연결에 보냅니다.
+func (s *ChatServiceImpl) notifyPresence(ctx context.Context, userID string) {
+	// 친구 관계는 UUID 사용자 사이에만 있음
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+
+	watcherIDs, err := s.friendRepo.GetAddedByUserIDs(ctx, userUUID)
+	if err != nil {
+		log.Println("Error loading presence watchers:", err)
+		return
+	}
+	if len(watcherIDs) == 0 {
+		return
+	}
+
+	records, err := s.presenceRepo.GetPresence(ctx, []string{userID}, s.config.Clock.Now())
+	if err != nil {
+		log.Println("Error loading presence:", err)
+		return
+	}
+	status := visiblePresence(userID, records[userID], "")
+
+	presenceEvent := map[string]interface{}{
+		"type":   "presence",
+		"userId": userID,
+		"status": status.Status,
+	}
+	if status.LastSeen != nil {
+		presenceEvent["lastSeen"] = status.LastSeen.Format(time.RFC3339)
+	}
+	msgJSON, _ := json.Marshal(presenceEvent)
+
+	recipients := make([]string, len(watcherIDs))
+	for i, watcherID := range watcherIDs {
+		recipients[i] = watcherID.String()
+	}
+	s.sendToUsers("", recipients, msgJSON)
+}
+
+// presenceFromFrame은 presence 프레임으로 받은 연결의 상태를 기록합니다.
+func (s *ChatServiceImpl) presenceFromFrame(c *client, frame WebSocketMessage) {
+	// 스키마에서 online 또는 away만 허용함
+	c.away.Store(presence.Status(frame.Status) == presence.StatusAway)
+	s.heartbeat(c)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"server/internal/models/ratelimit"
+	"server/internal/protocol"
+	"time"
+)
+
+// 메시지 전송량 제한:
+//
+// 새 메시지 프레임마다 사용자와 채팅방의 토큰 버킷에서 토큰을 하나씩 가져가며, 버킷은 모든 노드가 공유합니다.
+// 버킷이 비어 있으면 프레임을 처리하지 않고 rate_limited 오류로 다시 보낼 수 있을 때까지 남은 시간을 알립니다.
+// 사용자의 버킷이 비어 제한되는 일이 반복되면 사용자를 RateLimitBan 동안 차단합니다.
+// 차단된 사용자가 메시지를 보낸 연결은 끊고, 새 연결은 받지 않습니다.
+const (
+	closeRateLimited       = 4029
+	closeRateLimitedReason = "rate limited"
+)
+
+// RateLimitedError는 전송량 제한을 반복해서 넘겨 차단된 사용자가 접속할 때 반환됩니다.
+type RateLimitedError struct {
+	UserID string
+	Until  time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("user %s is rate limited until %s", e.UserID, e.Until.Format(time.RFC3339))
+}
+
+// checkBanned는 사용자가 차단되어 있으면 RateLimitedError를 반환합니다.
+// 제한 저장소에 장애가 있어도 접속은 막지 않습니다.
+func (s *ChatServiceImpl) checkBanned(ctx context.Context, userID string) error {
+	until, err := s.limitRepo.BannedUntil(ctx, userID, s.config.Clock.Now())
+	if err != nil {
+		log.Println("Error checking rate limit ban:", err)
+		return nil
+	}
+	if !until.IsZero() {
+		return &RateLimitedError{UserID: userID, Until: until}
+	}
+	return nil
+}
+
+// allowMessage는 새 메시지 프레임을 처리해도 되는지 확인합니다.
+// 제한되면 rate_limited 오류를 보내고 false를 반환합니다. 차단되었거나 이번 제한으로 차단되면 오류 대신 연결을 닫습니다.
+// 제한 저장소에 장애가 있어도 메시지 전송은 막지 않습니다.
+func (s *ChatServiceImpl) allowMessage(ctx context.Context, c *client, frame WebSocketMessage) bool {
+	now := s.config.Clock.Now()
+	result, err := s.limitRepo.TakeMessageToken(ctx, c.userID, frame.RoomId, s.config.UserMessageLimit, s.config.RoomMessageLimit, now)
+	if err != nil {
+		log.Println("Error checking rate limit:", err)
+		return true
+	}
+	if result.Allowed {
+		return true
+	}
+
+	if result.Reason == ratelimit.ReasonBanned {
+		c.close(closeRateLimited, closeRateLimitedReason)
+		return false
+	}
+
+	if result.Reason == ratelimit.ReasonUser && s.config.RateLimitStrikes > 0 && s.config.RateLimitBan > 0 {
+		banned, err := s.limitRepo.RecordViolation(ctx, c.userID, now, s.config.RateLimitStrikeWindow, s.config.RateLimitStrikes, s.config.RateLimitBan)
+		if err != nil {
+			log.Println("Error recording rate limit violation:", err)
+		}
+		if banned {
+			log.Println("Disconnected rate limited user:", c.userID, c.sessionID)
+			c.close(closeRateLimited, closeRateLimitedReason)
+			return false
+		}
+	}
+
+	s.sendRateLimited(c, frame, result)
+	return false
+}
+
+// sendRateLimited는 제한된 버킷과 다시 보낼 수 있을 때까지 남은 시간을 담은 rate_limited 오류를 보냅니다.
+func (s *ChatServiceImpl) sendRateLimited(c *client, frame WebSocketMessage, result ratelimit.Result) {
+	reason := "too many messages"
+	if result.Reason == ratelimit.ReasonRoom {
+		reason = "too many messages in the room"
+	}
+
+	event := errorEvent(frame, protocol.ErrorCodeRateLimited, reason)
+	event["retryAfterMs"] = result.RetryAfter.Milliseconds()
+
+	msgJSON, _ := json.Marshal(event)
+	s.reply(c, msgJSON)
+}
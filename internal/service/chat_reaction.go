@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// react는 메시지에 사용자의 반응을 추가하거나 지우고, 반응이 바뀌었으면 방에 reaction 이벤트를 보냅니다.
+// 삭제된 메시지에는 반응할 수 없습니다.
+func (s *ChatServiceImpl) react(ctx context.Context, roomID, userID string, messageID uuid.UUID, emoji, action string) error {
+	msg, err := s.messageRepo.GetMessage(ctx, roomID, messageID)
+	if err != nil {
+		return err
+	}
+	if _, ok := msg.(*message.DeletedMessage); ok {
+		return repository.ErrMessageNotFound
+	}
+
+	var reactions []message.Reaction
+	var changed bool
+	if action == "remove" {
+		reactions, changed, err = s.messageRepo.RemoveReaction(ctx, roomID, messageID, userID, emoji)
+	} else {
+		reactions, changed, err = s.messageRepo.AddReaction(ctx, roomID, messageID, userID, emoji, s.config.MaxReactionsPerUser)
+	}
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		s.broadcastReaction(ctx, roomID, userID, msg, emoji, action, reactions)
+	}
+	return nil
+}
+
+// withReactions는 메시지마다 반응 집계를 채웁니다.
+func (s *ChatServiceImpl) withReactions(ctx context.Context, roomID string, messages []message.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.GetID()
+	}
+
+	reactions, err := s.messageRepo.GetReactions(ctx, roomID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		msg.Base().Reactions = reactions[msg.GetID()]
+	}
+	return nil
+}
+
+// broadcastReaction은 바뀐 반응과 메시지의 새 반응 집계를 방의 모든 구독자와 스레드 참여자에게 보냅니다.
+func (s *ChatServiceImpl) broadcastReaction(ctx context.Context, roomID, userID string, msg message.Message, emoji, action string, reactions []message.Reaction) {
+	reactionEvent := map[string]interface{}{
+		"type":      "reaction",
+		"roomId":    roomID,
+		"messageId": msg.GetID(),
+		"userId":    userID,
+		"emoji":     emoji,
+		"action":    action,
+		"reactions": reactions,
+	}
+
+	msgJSON, _ := json.Marshal(reactionEvent)
+	s.broadcastChange(ctx, roomID, threadRoot(msg), msgJSON)
+}
+
+// reactionFromFrame은 reaction 프레임을 처리하고 결과를 ack 또는 error 이벤트로 알립니다.
+func (s *ChatServiceImpl) reactionFromFrame(ctx context.Context, c *client, frame WebSocketMessage) {
+	// 스키마에서 UUID 형식과 action 값을 검증함
+	messageID, _ := uuid.Parse(frame.MessageId)
+
+	if err := s.react(ctx, frame.RoomId, c.userID, messageID, frame.Emoji, frame.Action); err != nil {
+		s.sendChangeError(c, frame, err)
+		return
+	}
+
+	s.sendAck(c, frame.RoomId, frame.ClientMessageId, message.Receipt{MessageId: messageID, Timestamp: s.config.Clock.Now().Format(time.RFC3339)})
+}
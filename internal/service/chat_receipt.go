@@ -0,0 +1,207 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"server/internal/models/message"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 읽음과 전달 확인:
+//
+// 멤버마다 room_users에 읽음 커서와 전달 커서를 두며, 두 커서는 메시지 ID로 앞으로만 움직입니다.
+// 커서는 누적이므로 "이 메시지까지 읽음/전달됨"을 뜻하고, 커서가 움직일 때만 readReceipt는 방에,
+// deliveryReceipt는 메시지 작성자에게 보냅니다. 한 사용자의 여러 세션이나 여러 노드가 같은 메시지를 전달해도
+// 커서는 한 번만 움직이므로 이벤트도 한 번만 나갑니다. 전달 커서는 연결마다 묶어서 옮깁니다(chat_client.go 참고).
+//
+// 메시지를 보내면 작성자는 그 메시지까지 읽은 것이지만, 보낼 때마다 커서를 옮기고 방에 알리지는 않습니다.
+// 작성자의 커서는 노드마다 방과 작성자별 마지막 메시지만 모아 authorReadFlushInterval마다 이벤트 없이 옮기며,
+// 그 사이 채팅방 목록에서 자신이 보낸 메시지가 읽지 않은 메시지로 세지지 않도록 요약에서는 바로 뺍니다.
+
+// 작성자의 읽음 커서를 모아서 옮기는 주기
+const authorReadFlushInterval = time.Second
+
+// authorReadKey는 옮길 작성자의 읽음 커서를 방과 작성자별로 구분합니다.
+type authorReadKey struct {
+	roomID uuid.UUID
+	userID uuid.UUID
+}
+
+// markRead는 사용자의 읽음 커서를 messageID로 옮기고, 옮겼으면 방에 readReceipt 이벤트를 보냅니다.
+// 이미 더 뒤의 메시지까지 읽었으면 아무것도 하지 않습니다.
+func (s *ChatServiceImpl) markRead(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	if _, err := s.messageRepo.GetMessage(ctx, roomID, messageID); err != nil {
+		return err
+	}
+	return s.advanceRead(ctx, roomID, userID, messageID)
+}
+
+// advanceRead는 저장된 메시지 messageID로 읽음 커서를 옮기고, 옮겼으면 방에 readReceipt 이벤트를 보냅니다.
+func (s *ChatServiceImpl) advanceRead(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return err
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return err
+	}
+
+	advanced, err := s.roomRepo.AdvanceReadCursor(ctx, roomUUID, userUUID, messageID)
+	if err != nil {
+		return err
+	}
+	if advanced {
+		s.broadcastReceipt(roomID, "readReceipt", "readAt", userID, messageID)
+	}
+	return nil
+}
+
+// deferAuthorRead는 작성자의 읽음 커서를 보낸 메시지 messageID로 옮기도록 모아 둡니다.
+func (s *ChatServiceImpl) deferAuthorRead(roomID, userID, messageID uuid.UUID) {
+	key := authorReadKey{roomID: roomID, userID: userID}
+
+	s.authorReadMutex.Lock()
+	defer s.authorReadMutex.Unlock()
+	if pending, ok := s.authorReads[key]; !ok || bytes.Compare(pending[:], messageID[:]) < 0 {
+		s.authorReads[key] = messageID
+	}
+}
+
+// flushAuthorReads는 모아 둔 작성자의 읽음 커서를 주기적으로 옮깁니다.
+// 작성자는 자신이 보낸 메시지를 이미 보았으므로 readReceipt 이벤트는 보내지 않습니다.
+func (s *ChatServiceImpl) flushAuthorReads() {
+	ticker := s.config.Clock.NewTicker(authorReadFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		s.authorReadMutex.Lock()
+		pending := s.authorReads
+		s.authorReads = make(map[authorReadKey]uuid.UUID)
+		s.authorReadMutex.Unlock()
+
+		for key, messageID := range pending {
+			if _, err := s.roomRepo.AdvanceReadCursor(context.Background(), key.roomID, key.userID, messageID); err != nil {
+				log.Println("Error advancing author read cursor:", err)
+			}
+		}
+	}
+}
+
+// recordDelivery는 사용자의 소켓에 쓴 메시지 묶음을 받아 전달 커서를 옮깁니다.
+// 묶음에는 방과 작성자별 마지막 메시지만 있으며, 오래된 메시지부터 커서를 옮겨 옮겨진 메시지의 작성자에게만
+// deliveryReceipt 이벤트를 보냅니다.
+func (s *ChatServiceImpl) recordDelivery(userID string, deliveries []delivery) {
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return bytes.Compare(deliveries[i].messageID[:], deliveries[j].messageID[:]) < 0
+	})
+
+	for _, d := range deliveries {
+		roomUUID, err := uuid.Parse(d.roomID)
+		if err != nil {
+			continue
+		}
+
+		advanced, err := s.roomRepo.AdvanceDeliveryCursor(context.Background(), roomUUID, userUUID, d.messageID)
+		if err != nil {
+			log.Println("Error advancing delivery cursor:", err)
+			continue
+		}
+		if advanced {
+			s.sendDeliveryReceipt(d, userID)
+		}
+	}
+}
+
+// sendDeliveryReceipt는 userID 사용자에게 전달된 메시지의 작성자에게 deliveryReceipt 이벤트를 보냅니다.
+// 작성자가 방을 구독하지 않은 세션에도 보냅니다.
+func (s *ChatServiceImpl) sendDeliveryReceipt(d delivery, userID string) {
+	receiptEvent := map[string]interface{}{
+		"type":        "deliveryReceipt",
+		"roomId":      d.roomID,
+		"userId":      userID,
+		"messageId":   d.messageID,
+		"deliveredAt": s.config.Clock.Now().Format(time.RFC3339),
+	}
+
+	msgJSON, _ := json.Marshal(receiptEvent)
+	s.sendToUsers("", []string{d.authorID}, msgJSON)
+}
+
+// deliveryOf는 메시지를 소켓에 쓴 뒤 전달 커서를 옮길 대상을 반환합니다.
+func deliveryOf(msg message.Message) *delivery {
+	return &delivery{roomID: msg.GetRoomID(), messageID: msg.GetID(), authorID: msg.GetAuthor().Id}
+}
+
+// broadcastReceipt는 읽음 확인 이벤트를 방의 모든 구독자에게 보냅니다.
+// 같은 사용자의 다른 세션도 받아 읽음 상태를 맞출 수 있습니다.
+func (s *ChatServiceImpl) broadcastReceipt(roomID, eventType, timeField, userID string, messageID uuid.UUID) {
+	receiptEvent := map[string]interface{}{
+		"type":      eventType,
+		"roomId":    roomID,
+		"userId":    userID,
+		"messageId": messageID,
+		timeField:   s.config.Clock.Now().Format(time.RFC3339),
+	}
+
+	msgJSON, _ := json.Marshal(receiptEvent)
+	s.broadcast(roomID, "", msgJSON)
+}
+
+// withReadCounts는 메시지마다 작성자를 뺀 멤버 중 그 메시지까지 읽은 멤버 수를 채웁니다.
+func (s *ChatServiceImpl) withReadCounts(ctx context.Context, roomID string, messages []message.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	// UUID가 아닌 방에는 멤버가 없음
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return nil
+	}
+
+	cursors, err := s.roomRepo.GetReadCursors(ctx, roomUUID)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if _, ok := msg.(*message.DeletedMessage); ok {
+			continue
+		}
+
+		id := msg.GetID()
+		authorID := msg.GetAuthor().Id
+		count := 0
+		for userID, cursor := range cursors {
+			if userID.String() != authorID && bytes.Compare(cursor[:], id[:]) >= 0 {
+				count++
+			}
+		}
+		msg.Base().ReadCount = count
+	}
+	return nil
+}
+
+// readFromFrame은 read 프레임을 처리하고 결과를 ack 또는 error 이벤트로 알립니다.
+func (s *ChatServiceImpl) readFromFrame(ctx context.Context, c *client, frame WebSocketMessage) {
+	// 스키마에서 UUID 형식을 검증함
+	messageID, _ := uuid.Parse(frame.MessageId)
+
+	if err := s.markRead(ctx, frame.RoomId, c.userID, messageID); err != nil {
+		s.sendChangeError(c, frame, err)
+		return
+	}
+
+	s.sendAck(c, frame.RoomId, frame.ClientMessageId, message.Receipt{MessageId: messageID, Timestamp: s.config.Clock.Now().Format(time.RFC3339)})
+}
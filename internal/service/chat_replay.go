@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/models/message"
+
+	"github.com/google/uuid"
+)
+
+// subscribeFrom은 방을 구독하고 lastMessageID 이후 메시지를 재전송한 뒤 실시간 전달로 전환합니다.
+//
+// 저장소를 조회하기 전에 구독을 먼저 등록하고, 재전송이 끝날 때까지 도착한 실시간 이벤트는 보류합니다.
+// 따라서 조회 직후 저장된 메시지도 보류 목록으로 들어와 빠지지 않으며,
+// 조회 결과와 보류 목록에 모두 있는 메시지는 ID로 걸러 한 번만 전달합니다.
+// 재전송은 maxCatchUpMessages개까지만 하고, 남은 메시지가 있으면 replayTruncated 이벤트로 이어서 조회할 커서를 알립니다.
+func (s *ChatServiceImpl) subscribeFrom(ctx context.Context, c *client, roomID string, lastMessageID uuid.UUID) error {
+	if lastMessageID == uuid.Nil || s.isSubscribed(c, roomID) {
+		s.subscribe(c, roomID)
+		return nil
+	}
+
+	c.beginReplay(roomID)
+	s.subscribe(c, roomID)
+
+	page, err := s.messageRepo.GetMessagesByUUID(ctx, roomID, lastMessageID, maxCatchUpMessages)
+	var messages []message.Message
+	if err == nil {
+		messages, err = s.historyFor(ctx, roomID, c.userID, page.Messages)
+	}
+	if err != nil {
+		c.cancelReplay(roomID)
+		return err
+	}
+
+	// 전달 커서는 누적이므로 마지막으로 재전송한 메시지만 전달 확인 대상으로 둠
+	replayed := make(map[uuid.UUID]struct{}, len(messages))
+	for i, msg := range messages {
+		replayed[msg.GetID()] = struct{}{}
+		item := outbound{data: []byte(msg.ToJson())}
+		if i == len(messages)-1 {
+			item.delivery = deliveryOf(msg)
+		}
+		if !c.enqueueWait(item) {
+			return nil
+		}
+	}
+	if page.HasMore && !c.enqueueWait(outbound{data: replayTruncatedEvent(roomID, page.NextCursor)}) {
+		return nil
+	}
+
+	// 보류 목록을 비우는 동안에도 이벤트가 더 쌓일 수 있으므로 비어 있을 때까지 반복함
+	for {
+		pending, done := c.takePending(roomID)
+		if done {
+			return nil
+		}
+
+		for _, item := range pending {
+			// 메시지 이벤트만 전달 확인 대상이 있음
+			if item.delivery != nil {
+				if _, ok := replayed[item.delivery.messageID]; ok {
+					continue
+				}
+			}
+			if !c.enqueueWait(item) {
+				return nil
+			}
+		}
+	}
+}
+
+// replayTruncatedEvent는 재전송하지 못한 메시지를 nextCursor 이후부터 조회하라고 알리는 이벤트를 만듭니다.
+func replayTruncatedEvent(roomID string, nextCursor uuid.UUID) []byte {
+	msgJSON, _ := json.Marshal(map[string]interface{}{
+		"type":       "replayTruncated",
+		"roomId":     roomID,
+		"nextCursor": nextCursor.String(),
+	})
+	return msgJSON
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"server/internal/models/message"
+	"server/internal/protocol"
+	"server/internal/repository"
+	"time"
+)
+
+// pendingClaimTTL은 저장 중인 clientMessageId 선점이 유지되는 시간입니다.
+// 저장하던 노드가 멈춰도 이 시간이 지나면 같은 ID로 다시 보낼 수 있습니다.
+const pendingClaimTTL = 30 * time.Second
+
+// claimClientMessage는 프레임에 clientMessageId가 있으면 저장하기 전에 ID를 저장 중 상태로 선점합니다.
+//
+// 중복 방지 기간 동안 같은 ID로 다시 보낸 메시지는 저장하지 않고, 처음 메시지의 저장이 끝났으면 그 ID와 시각으로 ack를,
+// 아직 저장 중이면 send_pending 오류를 보내 잠시 뒤 다시 보내게 합니다. 이 경우 false를 반환합니다.
+// 선점한 뒤 메시지를 저장하지 못하면 releaseClientMessage로 선점을 해제해 재시도할 수 있게 해야 합니다.
+func (s *ChatServiceImpl) claimClientMessage(ctx context.Context, c *client, frame WebSocketMessage, msg message.Message) bool {
+	clientMessageID := frame.ClientMessageId
+	if clientMessageID == "" {
+		return true
+	}
+
+	receipt := message.Receipt{MessageId: msg.GetID(), Timestamp: msg.GetTimestamp()}
+	original, claimed, err := s.dedupRepo.Claim(ctx, c.userID, clientMessageID, receipt, pendingClaimTTL)
+	if errors.Is(err, repository.ErrClaimPending) {
+		s.sendError(c, frame, protocol.ErrorCodeSendPending, "message is still being saved; retry later")
+		return false
+	}
+	if err != nil {
+		log.Println("Error claiming client message ID:", err)
+		s.sendError(c, frame, protocol.ErrorCodeStorageFailure, "failed to save message")
+		return false
+	}
+	if !claimed {
+		s.sendAck(c, msg.GetRoomID(), clientMessageID, original)
+		return false
+	}
+	return true
+}
+
+// releaseClientMessage는 저장하지 못한 메시지의 clientMessageId 선점을 해제합니다.
+func (s *ChatServiceImpl) releaseClientMessage(ctx context.Context, c *client, frame WebSocketMessage) {
+	if frame.ClientMessageId == "" {
+		return
+	}
+	if err := s.dedupRepo.Release(ctx, c.userID, frame.ClientMessageId); err != nil {
+		log.Println("Error releasing client message ID:", err)
+	}
+}
+
+// sendMessage는 claimClientMessage로 선점한 메시지를 저장하고 보낸 연결에 ack 또는 error 프레임으로 결과를 알립니다.
+// 저장하면 선점을 저장 완료로 바꾸고, 저장에 실패하면 선점을 해제합니다.
+func (s *ChatServiceImpl) sendMessage(ctx context.Context, c *client, frame WebSocketMessage, msg message.Message) {
+	roomID := msg.GetRoomID()
+	clientMessageID := frame.ClientMessageId
+	receipt := message.Receipt{MessageId: msg.GetID(), Timestamp: msg.GetTimestamp()}
+
+	err := s.SaveMessage(ctx, roomID, msg)
+	if err != nil {
+		log.Println("Error saving message:", err)
+		s.releaseClientMessage(ctx, c, frame)
+		s.sendError(c, frame, protocol.ErrorCodeStorageFailure, "failed to save message")
+		return
+	}
+
+	if clientMessageID != "" {
+		// 메시지는 이미 저장되었으므로 실패해도 ack를 보냄. 선점은 pendingClaimTTL 뒤에 만료됨
+		if err := s.dedupRepo.Commit(ctx, c.userID, clientMessageID, s.config.DedupWindow); err != nil {
+			log.Println("Error committing client message ID:", err)
+		}
+	}
+	s.sendAck(c, roomID, clientMessageID, receipt)
+}
+
+func (s *ChatServiceImpl) sendAck(c *client, roomID, clientMessageID string, receipt message.Receipt) {
+	ackEvent := map[string]interface{}{
+		"type":      "ack",
+		"roomId":    roomID,
+		"messageId": receipt.MessageId,
+		"timestamp": receipt.Timestamp,
+	}
+	if clientMessageID != "" {
+		ackEvent["clientMessageId"] = clientMessageID
+	}
+
+	msgJSON, _ := json.Marshal(ackEvent)
+	s.reply(c, msgJSON)
+}
+
+// sendError는 처리하지 못한 프레임의 type, roomId, clientMessageId를 담은 error 이벤트를 보냅니다.
+func (s *ChatServiceImpl) sendError(c *client, frame WebSocketMessage, code, reason string) {
+	msgJSON, _ := json.Marshal(errorEvent(frame, code, reason))
+	s.reply(c, msgJSON)
+}
+
+// errorEvent는 프레임에서 읽을 수 있었던 값을 담은 error 이벤트를 만듭니다.
+func errorEvent(frame WebSocketMessage, code, reason string) map[string]interface{} {
+	event := map[string]interface{}{
+		"type":    "error",
+		"code":    code,
+		"message": reason,
+	}
+	if frame.Type != "" {
+		event["frameType"] = frame.Type
+	}
+	if frame.RoomId != "" {
+		event["roomId"] = frame.RoomId
+	}
+	if frame.ClientMessageId != "" {
+		event["clientMessageId"] = frame.ClientMessageId
+	}
+	return event
+}
+
+// sendProtocolError는 프레임 검증 오류를 error 이벤트로 보냅니다.
+func (s *ChatServiceImpl) sendProtocolError(c *client, frame WebSocketMessage, err error) {
+	var protocolErr *protocol.Error
+	if !errors.As(err, &protocolErr) {
+		protocolErr = &protocol.Error{Code: protocol.ErrorCodeBadFrame, Message: err.Error()}
+	}
+	s.sendError(c, frame, protocolErr.Code, protocolErr.Message)
+}
+
+// reply는 한 연결에만 프레임을 보냅니다. 송신 큐가 가득 차면 느린 소비자 정책에 따라 연결을 끊습니다.
+func (s *ChatServiceImpl) reply(c *client, msgJSON []byte) {
+	if !c.enqueue(outbound{data: msgJSON}) && c.disconnect(closeSlowConsumer, closeSlowConsumerReason) {
+		log.Println("Disconnected slow consumer:", c.userID, c.sessionID)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"log"
+	"server/internal/broadcast"
 	"server/internal/models/message"
+	"server/internal/protocol"
 	"server/internal/repository"
 	"sync"
 	"time"
@@ -15,18 +17,85 @@ import (
 )
 
 type ChatServiceImpl struct {
-	messageRepo repository.MessageRepository
-
-	connections     map[string]map[string]*websocket.Conn
+	messageRepo  repository.MessageRepository
+	dedupRepo    repository.MessageDedupRepository
+	mentionRepo  repository.MentionRepository
+	summaryRepo  repository.RoomSummaryRepository
+	presenceRepo repository.PresenceRepository
+	typingRepo   repository.TypingRepository
+	limitRepo    repository.RateLimitRepository
+	roomRepo     repository.RoomRepository
+	friendRepo   repository.FriendRepository
+	membership   *membershipCache
+
+	// 다른 노드와 이벤트를 주고받는 버스. nodeID로 자신이 발행한 이벤트를 구분함
+	bus    broadcast.Bus
+	nodeID string
+
+	config ChatConfig
+
+	// 방 ID -> 세션 ID -> 연결. 한 사용자가 여러 기기로 동시에 접속할 수 있고,
+	// 하나의 연결이 여러 방을 구독할 수 있음
+	connections map[string]map[string]*client
+	// 방 ID -> 사용자 ID -> 접속 중인 세션 수
+	userSessions map[string]map[string]int
+	// 세션 ID -> 연결. 구독 중인 방과 관계없이 이 노드의 모든 연결
+	sessions        map[string]*client
 	connectionMutex sync.RWMutex
+
+	// 이 노드가 받은 typing 프레임의 처리 시각과 만료 시각
+	typing      map[typingKey]typingState
+	typingMutex sync.Mutex
+
+	// 아직 옮기지 않은 작성자의 읽음 커서. 방과 작성자별로 마지막으로 보낸 메시지만 둠
+	authorReads     map[authorReadKey]uuid.UUID
+	authorReadMutex sync.Mutex
 }
 
-func NewChatService(messageRepo repository.MessageRepository) ChatService {
-	return &ChatServiceImpl{
-		messageRepo:     messageRepo,
-		connections:     make(map[string]map[string]*websocket.Conn),
+// ChatDeps는 ChatService가 사용하는 저장소와 노드 사이의 이벤트 버스입니다.
+type ChatDeps struct {
+	MessageRepo  repository.MessageRepository
+	DedupRepo    repository.MessageDedupRepository
+	MentionRepo  repository.MentionRepository
+	SummaryRepo  repository.RoomSummaryRepository
+	PresenceRepo repository.PresenceRepository
+	TypingRepo   repository.TypingRepository
+	LimitRepo    repository.RateLimitRepository
+	RoomRepo     repository.RoomRepository
+	FriendRepo   repository.FriendRepository
+	Bus          broadcast.Bus
+}
+
+func NewChatService(deps ChatDeps, config ChatConfig) ChatService {
+	s := &ChatServiceImpl{
+		messageRepo:     deps.MessageRepo,
+		dedupRepo:       deps.DedupRepo,
+		mentionRepo:     deps.MentionRepo,
+		summaryRepo:     deps.SummaryRepo,
+		presenceRepo:    deps.PresenceRepo,
+		typingRepo:      deps.TypingRepo,
+		limitRepo:       deps.LimitRepo,
+		roomRepo:        deps.RoomRepo,
+		friendRepo:      deps.FriendRepo,
+		membership:      newMembershipCache(deps.RoomRepo, membershipCacheTTL, config.Clock),
+		bus:             deps.Bus,
+		nodeID:          uuid.NewString(),
+		config:          config,
+		connections:     make(map[string]map[string]*client),
+		userSessions:    make(map[string]map[string]int),
+		sessions:        make(map[string]*client),
 		connectionMutex: sync.RWMutex{},
+		typing:          make(map[typingKey]typingState),
+		authorReads:     make(map[authorReadKey]uuid.UUID),
 	}
+
+	s.bus.Subscribe(s.handleEnvelope)
+	go s.reapDeadConnections()
+	go s.refreshPresence()
+	go s.expireTyping()
+	go s.flushAuthorReads()
+
+	return s
 }
 
 func (s *ChatServiceImpl) SaveMessage(ctx context.Context, roomID string, msg message.Message) error {
@@ -36,114 +105,361 @@ func (s *ChatServiceImpl) SaveMessage(ctx context.Context, roomID string, msg me
 	}
 
 	s.broadcastMessage(roomID, msg)
+	if msg.Base().ThreadId != nil {
+		s.broadcastThreadUpdated(ctx, roomID, msg)
+	}
+	s.recordMentions(ctx, roomID, msg, nil)
+	s.recordSummary(ctx, roomID, msg)
+	s.clearTyping(ctx, roomID, msg.GetAuthor().Id)
 
 	return nil
 }
 
-func (s *ChatServiceImpl) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	return s.messageRepo.GetMessages(ctx, roomID, lastMessageID)
+// GetMessages는 lastMessageID(밀리초 타임스탬프) 이후의 메시지를 최대 maxCatchUpMessages개 반환합니다.
+// 더 남아 있으면 HasMore와 NextCursor로 이어서 조회할 위치를 알려 줍니다.
+func (s *ChatServiceImpl) GetMessages(ctx context.Context, roomID, userID string, lastMessageID int64) (message.Page, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return message.Page{}, err
+	}
+	page, err := s.messageRepo.GetMessages(ctx, roomID, lastMessageID, maxCatchUpMessages)
+	if err != nil {
+		return message.Page{}, err
+	}
+	page.Messages, err = s.historyFor(ctx, roomID, userID, page.Messages)
+	return page, err
+}
+
+// GetMessagesByUUID는 lastMessageUUID 이후의 메시지를 최대 maxCatchUpMessages개 반환합니다.
+// 더 남아 있으면 HasMore와 NextCursor로 이어서 조회할 위치를 알려 줍니다.
+func (s *ChatServiceImpl) GetMessagesByUUID(ctx context.Context, roomID, userID string, lastMessageUUID uuid.UUID) (message.Page, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return message.Page{}, err
+	}
+	page, err := s.messageRepo.GetMessagesByUUID(ctx, roomID, lastMessageUUID, maxCatchUpMessages)
+	if err != nil {
+		return message.Page{}, err
+	}
+	page.Messages, err = s.historyFor(ctx, roomID, userID, page.Messages)
+	return page, err
+}
+
+const (
+	// 한 번에 조회하는 메시지 수의 기본값과 최댓값
+	defaultMessagePageLimit = 50
+	maxMessagePageLimit     = 100
+	// 커서 이후를 한 번에 따라잡는 조회(이전 버전 조회, 재연결 재전송)의 최댓값
+	maxCatchUpMessages = 1000
+)
+
+// ListMessages는 메시지 기록을 페이지 단위로 조회합니다. Limit이 없으면 기본값을, 최댓값을 넘으면 최댓값을 사용합니다.
+func (s *ChatServiceImpl) ListMessages(ctx context.Context, roomID, userID string, query message.PageQuery) (message.Page, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return message.Page{}, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultMessagePageLimit
+	}
+	if query.Limit > maxMessagePageLimit {
+		query.Limit = maxMessagePageLimit
+	}
+
+	page, err := s.messageRepo.ListMessages(ctx, roomID, query)
+	if err != nil {
+		return message.Page{}, err
+	}
+
+	// 숨긴 메시지를 빼도 다음 커서는 빼기 전 페이지 기준이므로 그대로 이어서 조회할 수 있음
+	page.Messages, err = s.historyFor(ctx, roomID, userID, page.Messages)
+	if err != nil {
+		return message.Page{}, err
+	}
+	return page, nil
+}
+
+// historyFor는 저장소에서 읽은 기록을 사용자에게 보여 줄 형태로 바꿉니다.
+// 사용자가 숨긴 메시지를 빼고, 남은 메시지를 decorate로 채웁니다.
+func (s *ChatServiceImpl) historyFor(ctx context.Context, roomID, userID string, messages []message.Message) ([]message.Message, error) {
+	visible, err := withoutHidden(ctx, s.messageRepo, roomID, userID, messages)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decorate(ctx, roomID, visible); err != nil {
+		return nil, err
+	}
+	return visible, nil
 }
 
-func (s *ChatServiceImpl) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error) {
-	return s.messageRepo.GetMessagesByUUID(ctx, roomID, lastMessageUUID)
+// decorate는 저장되는 본문에 담기지 않는 반응 집계, 인용의 최신 판, 스레드 요약, 읽은 멤버 수를 채웁니다.
+func (s *ChatServiceImpl) decorate(ctx context.Context, roomID string, messages []message.Message) error {
+	if err := s.withReactions(ctx, roomID, messages); err != nil {
+		return err
+	}
+	if err := s.withQuotes(ctx, roomID, messages); err != nil {
+		return err
+	}
+	if err := s.withThreads(ctx, roomID, messages); err != nil {
+		return err
+	}
+	return s.withReadCounts(ctx, roomID, messages)
 }
 
-func (s *ChatServiceImpl) HandleWebSocketConnection(ctx context.Context, roomID, userID string, conn interface{}) error {
+// HandleWebSocketConnection은 연결을 등록하고 읽기/쓰기 고루틴을 시작합니다.
+// 하나의 연결로 여러 채팅방을 구독할 수 있으며, roomID가 주어지면 해당 방을 바로 구독합니다.
+// lastMessageID가 주어지면 그 이후 메시지를 먼저 재전송한 뒤 실시간 전달로 전환합니다.
+func (s *ChatServiceImpl) HandleWebSocketConnection(ctx context.Context, roomID, userID string, lastMessageID uuid.UUID, conn interface{}) error {
 	wsConn, ok := conn.(*websocket.Conn)
 	if !ok {
 		return errors.New("invalid connection type")
 	}
 
-	s.addConnection(roomID, userID, wsConn)
+	if err := s.checkBanned(ctx, userID); err != nil {
+		return err
+	}
+	if roomID != "" {
+		if err := s.membership.check(ctx, roomID, userID); err != nil {
+			return err
+		}
+	}
+
+	c := newClient(wsConn, userID, s.config, s.recordDelivery)
+	s.addSession(c)
+	go c.writePump()
 
-	// 사용자 입장 이벤트 전송
-	s.sendUserJoinedEvent(roomID, userID)
+	if roomID != "" {
+		if err := s.subscribeFrom(ctx, c, roomID, lastMessageID); err != nil {
+			s.unsubscribe(c, roomID)
+			s.removeSession(c)
+			c.close(websocket.CloseInternalServerErr, "failed to replay messages")
+			return err
+		}
+	}
 
-	go s.handleMessages(ctx, roomID, userID, wsConn)
+	go s.handleMessages(ctx, c)
 
 	return nil
 }
 
-func (s *ChatServiceImpl) addConnection(roomID, userID string, conn *websocket.Conn) {
+// addSession은 연결을 이 노드의 세션으로 등록하고 접속 상태에 반영합니다.
+func (s *ChatServiceImpl) addSession(c *client) {
+	s.connectionMutex.Lock()
+	s.sessions[c.sessionID] = c
+	s.connectionMutex.Unlock()
+
+	s.heartbeat(c)
+}
+
+// removeSession은 연결을 세션에서 빼고 접속 상태에 반영합니다.
+func (s *ChatServiceImpl) removeSession(c *client) {
+	s.connectionMutex.Lock()
+	delete(s.sessions, c.sessionID)
+	s.connectionMutex.Unlock()
+
+	s.removePresence(c)
+}
+
+// reapDeadConnections는 pong 응답이나 프레임이 끊긴 연결을 주기적으로 찾아 닫습니다.
+// 연결을 닫으면 읽기 루프가 끝나면서 일반적인 구독 해제와 퇴장 이벤트 경로로 정리됩니다.
+func (s *ChatServiceImpl) reapDeadConnections() {
+	ticker := s.config.Clock.NewTicker(s.config.reapInterval())
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		now := s.config.Clock.Now()
+
+		s.connectionMutex.RLock()
+		var expired []*client
+		for _, c := range s.sessions {
+			if c.expired(now) {
+				expired = append(expired, c)
+			}
+		}
+		s.connectionMutex.RUnlock()
+
+		for _, c := range expired {
+			if c.disconnect(closeTimedOut, closeTimedOutReason) {
+				log.Println("Reaped dead connection:", c.userID, c.sessionID)
+			}
+		}
+	}
+}
+
+// subscribe는 세션을 방에 등록합니다. 사용자의 방 안 첫 번째 세션이면 입장 이벤트를 보냅니다.
+// 멤버십 확인은 호출하는 쪽의 책임입니다.
+func (s *ChatServiceImpl) subscribe(c *client, roomID string) {
 	s.connectionMutex.Lock()
-	defer s.connectionMutex.Unlock()
+
+	if _, ok := c.rooms[roomID]; ok {
+		s.connectionMutex.Unlock()
+		return
+	}
 
 	if _, ok := s.connections[roomID]; !ok {
-		s.connections[roomID] = make(map[string]*websocket.Conn)
+		s.connections[roomID] = make(map[string]*client)
+		s.userSessions[roomID] = make(map[string]int)
 	}
 
-	s.connections[roomID][userID] = conn
+	c.rooms[roomID] = struct{}{}
+	s.connections[roomID][c.sessionID] = c
+	s.userSessions[roomID][c.userID]++
+	first := s.userSessions[roomID][c.userID] == 1
+
+	s.connectionMutex.Unlock()
+
+	if first {
+		s.sendUserJoinedEvent(roomID, c.userID)
+	}
 }
 
-func (s *ChatServiceImpl) removeConnection(roomID, userID string) {
+// unsubscribe는 세션을 방에서 제거합니다. 사용자의 방 안 마지막 세션이었다면 퇴장 이벤트를 보냅니다.
+func (s *ChatServiceImpl) unsubscribe(c *client, roomID string) {
 	s.connectionMutex.Lock()
-	defer s.connectionMutex.Unlock()
 
-	if room, ok := s.connections[roomID]; ok {
-		delete(room, userID)
+	if _, ok := c.rooms[roomID]; !ok {
+		s.connectionMutex.Unlock()
+		return
+	}
 
-		if len(room) == 0 {
-			delete(s.connections, roomID)
-		}
+	delete(c.rooms, roomID)
+	delete(s.connections[roomID], c.sessionID)
+
+	sessions := s.userSessions[roomID]
+	sessions[c.userID]--
+	last := sessions[c.userID] == 0
+	if last {
+		delete(sessions, c.userID)
+	}
+
+	if len(s.connections[roomID]) == 0 {
+		delete(s.connections, roomID)
+		delete(s.userSessions, roomID)
+	}
+
+	s.connectionMutex.Unlock()
+
+	if last {
+		s.clearTyping(context.Background(), roomID, c.userID)
+		s.sendUserLeftEvent(roomID, c.userID)
 	}
 }
 
-func (s *ChatServiceImpl) broadcastMessage(roomID string, msg message.Message) {
+// unsubscribeAll은 연결이 끊길 때 세션이 구독한 모든 방에서 제거합니다.
+func (s *ChatServiceImpl) unsubscribeAll(c *client) {
 	s.connectionMutex.RLock()
-	defer s.connectionMutex.RUnlock()
+	rooms := make([]string, 0, len(c.rooms))
+	for roomID := range c.rooms {
+		rooms = append(rooms, roomID)
+	}
+	s.connectionMutex.RUnlock()
 
-	room, ok := s.connections[roomID]
-	if !ok {
-		return
+	for _, roomID := range rooms {
+		s.unsubscribe(c, roomID)
 	}
+}
 
-	msgJSON := []byte(msg.ToJson())
+// removeMember는 방에서 제거된 사용자의 멤버십 캐시를 지우고, 이 노드에서 그 방을 구독 중인 세션을 구독 해제합니다.
+func (s *ChatServiceImpl) removeMember(roomID, userID string) {
+	s.membership.invalidate(roomID, userID)
 
-	for _, conn := range room {
-		err := conn.WriteMessage(websocket.TextMessage, msgJSON)
-		if err != nil {
-			log.Println("Error sending message:", err)
+	s.connectionMutex.RLock()
+	var removed []*client
+	for _, c := range s.connections[roomID] {
+		if c.userID == userID {
+			removed = append(removed, c)
 		}
 	}
+	s.connectionMutex.RUnlock()
+
+	for _, c := range removed {
+		s.unsubscribe(c, roomID)
+	}
 }
 
-func (s *ChatServiceImpl) broadcastTypingStatus(roomID, userID string, isTyping bool) {
+func (s *ChatServiceImpl) isSubscribed(c *client, roomID string) bool {
 	s.connectionMutex.RLock()
 	defer s.connectionMutex.RUnlock()
 
-	room, ok := s.connections[roomID]
-	if !ok {
-		return
+	_, ok := c.rooms[roomID]
+	return ok
+}
+
+// broadcast는 이 노드의 세션에 이벤트를 바로 전달하고, 다른 노드에 전달되도록 버스에 발행합니다.
+func (s *ChatServiceImpl) broadcast(roomID, exceptUserID string, msgJSON []byte) {
+	s.publish(broadcast.Envelope{
+		RoomID:       roomID,
+		ExceptUserID: exceptUserID,
+		Payload:      msgJSON,
+	})
+}
+
+// publish는 방의 이벤트를 이 노드의 세션에 전달하고 버스에 발행합니다.
+func (s *ChatServiceImpl) publish(env broadcast.Envelope) {
+	env.Origin = s.nodeID
+	s.deliverLocal(env)
+
+	if err := s.bus.Publish(context.Background(), env); err != nil {
+		log.Println("Error publishing event:", err)
 	}
+}
 
-	typingEvent := map[string]interface{}{
-		"type":     "typing",
-		"roomId":   roomID,
-		"userId":   userID,
-		"isTyping": isTyping,
+// handleEnvelope는 버스로 받은 이벤트를 이 노드의 세션에 전달합니다.
+// 자신이 발행한 이벤트는 broadcast에서 이미 전달했으므로 무시합니다.
+func (s *ChatServiceImpl) handleEnvelope(env broadcast.Envelope) {
+	if env.Origin == s.nodeID {
+		return
 	}
 
-	msgJSON, _ := json.Marshal(typingEvent)
+	if env.RemovedUserID != "" {
+		s.removeMember(env.RoomID, env.RemovedUserID)
+		return
+	}
+	if len(env.UserIDs) > 0 {
+		s.deliverToUsers(env.RoomID, env.UserIDs, env.Payload)
+		return
+	}
+	s.deliverLocal(env)
+}
 
-	for id, conn := range room {
-		if id != userID { // 자신에게는 타이핑 상태를 보내지 않음
-			err := conn.WriteMessage(websocket.TextMessage, msgJSON)
-			if err != nil {
-				log.Println("Error sending typing status:", err)
-			}
+// deliverLocal은 이 노드에 연결된 방의 모든 세션(ExceptUserID 사용자의 세션 제외)의 송신 큐에 이벤트를 넣습니다.
+// 큐가 가득 찬 연결은 느린 소비자 정책에 따라 끊습니다.
+func (s *ChatServiceImpl) deliverLocal(env broadcast.Envelope) {
+	item := outbound{data: env.Payload}
+	if env.MessageID != "" {
+		messageID, err := uuid.Parse(env.MessageID)
+		if err == nil {
+			item.delivery = &delivery{roomID: env.RoomID, messageID: messageID, authorID: env.AuthorID}
 		}
 	}
-}
 
-func (s *ChatServiceImpl) sendUserJoinedEvent(roomID, userID string) {
 	s.connectionMutex.RLock()
 	defer s.connectionMutex.RUnlock()
 
-	room, ok := s.connections[roomID]
+	room, ok := s.connections[env.RoomID]
 	if !ok {
 		return
 	}
 
+	for _, c := range room {
+		if c.userID == env.ExceptUserID {
+			continue
+		}
+		if !c.deliver(env.RoomID, item) && c.disconnect(closeSlowConsumer, closeSlowConsumerReason) {
+			log.Println("Disconnected slow consumer:", env.RoomID, c.userID, c.sessionID)
+		}
+	}
+}
+
+// broadcastMessage는 새 메시지를 방에 보냅니다. 받는 세션은 소켓에 쓴 뒤 전달 커서를 옮깁니다.
+func (s *ChatServiceImpl) broadcastMessage(roomID string, msg message.Message) {
+	s.publish(broadcast.Envelope{
+		RoomID:    roomID,
+		MessageID: msg.GetID().String(),
+		AuthorID:  msg.GetAuthor().Id,
+		Payload:   []byte(msg.ToJson()),
+	})
+}
+
+func (s *ChatServiceImpl) sendUserJoinedEvent(roomID, userID string) {
 	joinEvent := map[string]interface{}{
 		"type":      "userJoined",
 		"roomId":    roomID,
@@ -153,25 +469,11 @@ func (s *ChatServiceImpl) sendUserJoinedEvent(roomID, userID string) {
 
 	msgJSON, _ := json.Marshal(joinEvent)
 
-	for id, conn := range room {
-		if id != userID { // 자신에게는 입장 이벤트를 보내지 않음
-			err := conn.WriteMessage(websocket.TextMessage, msgJSON)
-			if err != nil {
-				log.Println("Error sending user joined event:", err)
-			}
-		}
-	}
+	// 자신에게는 입장 이벤트를 보내지 않음
+	s.broadcast(roomID, userID, msgJSON)
 }
 
 func (s *ChatServiceImpl) sendUserLeftEvent(roomID, userID string) {
-	s.connectionMutex.RLock()
-	defer s.connectionMutex.RUnlock()
-
-	room, ok := s.connections[roomID]
-	if !ok {
-		return
-	}
-
 	leftEvent := map[string]interface{}{
 		"type":      "userLeft",
 		"roomId":    roomID,
@@ -181,75 +483,193 @@ func (s *ChatServiceImpl) sendUserLeftEvent(roomID, userID string) {
 
 	msgJSON, _ := json.Marshal(leftEvent)
 
-	for _, conn := range room {
-		err := conn.WriteMessage(websocket.TextMessage, msgJSON)
-		if err != nil {
-			log.Println("Error sending user left event:", err)
-		}
-	}
+	s.broadcast(roomID, "", msgJSON)
 }
 
 // WebSocketMessage는 WebSocket을 통해 주고받는 메시지의 구조를 정의합니다.
+// presence 프레임을 제외한 모든 프레임은 RoomId로 대상 채팅방을 지정합니다.
 type WebSocketMessage struct {
 	Type     string `json:"type"`
 	RoomId   string `json:"roomId"`
 	IsTyping bool   `json:"isTyping,omitempty"`
 	Content  string `json:"content,omitempty"`
+	// subscribe 프레임에서 마지막으로 받은 메시지 ID. 그 이후 메시지를 재전송함
+	LastMessageId string `json:"lastMessageId,omitempty"`
+	// 클라이언트가 메시지마다 만드는 ID. ack/error 프레임에 그대로 담기며 재시도를 구분하는 데 사용함
+	ClientMessageId string `json:"clientMessageId,omitempty"`
+	// message/edit 프레임의 멘션 구간
+	Mentions []message.Mention `json:"mentions,omitempty"`
+	// edit/delete/reaction/read 프레임의 대상 메시지 ID
+	MessageId string `json:"messageId,omitempty"`
+	// delete 프레임에서 모두에게서 삭제할지 여부. false이면 보낸 사용자에게만 숨김
+	ForEveryone bool `json:"forEveryone,omitempty"`
+	// reaction 프레임의 이모지와 동작("add" 또는 "remove")
+	Emoji  string `json:"emoji,omitempty"`
+	Action string `json:"action,omitempty"`
+	// message/image 프레임에서 답장할 메시지 ID
+	ReplyTo string `json:"replyTo,omitempty"`
+	// presence 프레임의 연결 상태("online" 또는 "away")
+	Status string `json:"status,omitempty"`
+}
+
+// serverManagedFields는 서버가 채우는 메시지 공통 필드입니다. 프레임의 replyTo는 인용이 아니라 답장할 메시지 ID입니다.
+var serverManagedFields = []string{"id", "author", "timestamp", "editedAt", "reactions", "replyTo", "threadId", "thread", "readCount"}
+
+// messageFields는 메시지 프레임에서 서버가 채우는 공통 필드를 뺀 JSON을 반환합니다.
+func messageFields(frame []byte) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(frame, &fields); err != nil {
+		return nil, err
+	}
+	for _, name := range serverManagedFields {
+		delete(fields, name)
+	}
+	return json.Marshal(fields)
 }
 
-func (s *ChatServiceImpl) handleMessages(ctx context.Context, roomID, userID string, conn *websocket.Conn) {
+func (s *ChatServiceImpl) handleMessages(ctx context.Context, c *client) {
+	userID := c.userID
+
 	defer func() {
-		conn.Close()
-		s.removeConnection(roomID, userID)
-		// 사용자 퇴장 이벤트 전송
-		s.sendUserLeftEvent(roomID, userID)
+		s.unsubscribeAll(c)
+		s.removeSession(c)
+		c.close(websocket.CloseNormalClosure, "")
 	}()
 
 	for {
-		_, msgBytes, err := conn.ReadMessage()
+		_, msgBytes, err := c.conn.ReadMessage()
 		if err != nil {
+			// 읽기 제한을 넘은 경우 gorilla가 1009 종료 프레임을 보냄
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Println("Error reading message:", err)
 			}
 			break
 		}
+		c.touch()
 
+		// 검증에 실패한 프레임도 읽을 수 있는 필드는 채워 error 이벤트에 담음
 		var baseMsg WebSocketMessage
-		err = json.Unmarshal(msgBytes, &baseMsg)
+		decodeErr := json.Unmarshal(msgBytes, &baseMsg)
+
+		err = protocol.Validate(msgBytes)
 		if err != nil {
-			log.Println("Error parsing message type:", err)
+			s.sendProtocolError(c, baseMsg, err)
+			continue
+		}
+		// 스키마가 정의하지 않은 필드도 형식이 맞지 않으면 일부만 읽힌 프레임이므로 처리하지 않음
+		if decodeErr != nil {
+			s.sendError(c, baseMsg, protocol.ErrorCodeBadFrame, "malformed frame: "+decodeErr.Error())
 			continue
 		}
 
-		switch baseMsg.Type {
-		case "message":
-			textMsg := &message.TextMessage{}
-			textMsg.Id, _ = uuid.NewV7()
-			textMsg.Type = "message"
-			textMsg.Author = message.User{Id: userID}
-			textMsg.RoomId = roomID
-			textMsg.Content = baseMsg.Content
-
-			err = s.SaveMessage(ctx, roomID, textMsg)
+		roomID := baseMsg.RoomId
+
+		if baseMsg.Type == "unsubscribe" {
+			s.unsubscribe(c, roomID)
+			continue
+		}
+
+		// 접속 상태는 채팅방과 무관함
+		if baseMsg.Type == "presence" {
+			s.presenceFromFrame(c, baseMsg)
+			continue
+		}
+
+		// 구독할 때와 프레임마다 멤버십을 확인해 채팅방에서 나간 사용자의 프레임은 처리하지 않음
+		err = s.membership.check(ctx, roomID, userID)
+		var notMemberErr *NotRoomMemberError
+		if errors.As(err, &notMemberErr) {
+			s.sendError(c, baseMsg, protocol.ErrorCodeNotMember, "not a member of the room")
+			continue
+		}
+		if err != nil {
+			log.Println("Error checking room membership:", err)
+			s.sendError(c, baseMsg, protocol.ErrorCodeStorageFailure, "failed to check room membership")
+			continue
+		}
+
+		if baseMsg.Type == "subscribe" {
+			// 스키마에서 UUID 형식을 검증함
+			lastMessageID, _ := uuid.Parse(baseMsg.LastMessageId)
+
+			// 재전송에 실패하면 놓친 메시지가 생기므로 연결을 끊어 클라이언트가 다시 접속하게 함
+			err = s.subscribeFrom(ctx, c, roomID, lastMessageID)
 			if err != nil {
-				log.Println("Error saving message:", err)
+				log.Println("Error replaying messages:", err)
+				c.close(websocket.CloseInternalServerErr, "failed to replay messages")
+				return
 			}
+			continue
+		}
+
+		if !s.isSubscribed(c, roomID) {
+			s.sendError(c, baseMsg, protocol.ErrorCodeNotSubscribed, "not subscribed to the room")
+			continue
+		}
+
+		switch baseMsg.Type {
 		case "typing":
-			s.broadcastTypingStatus(roomID, userID, baseMsg.IsTyping)
-		case "image":
-			imageMsg := &message.ImageMessage{}
-			imageMsg.FromJson(msgBytes)
-			imageMsg.Author = message.User{Id: userID}
-			imageMsg.RoomId = roomID
-			imageMsg.Id, _ = uuid.NewV7()
-
-			err = s.SaveMessage(ctx, roomID, imageMsg)
+			s.typingFromFrame(ctx, c, baseMsg)
+		case "edit":
+			s.editFromFrame(ctx, c, baseMsg)
+		case "delete":
+			s.deleteFromFrame(ctx, c, baseMsg)
+		case "reaction":
+			s.reactionFromFrame(ctx, c, baseMsg)
+		case "read":
+			s.readFromFrame(ctx, c, baseMsg)
+		default:
+			msg, ok := message.New(baseMsg.Type)
+			if !ok {
+				// 스키마에는 있지만 아직 처리하지 않는 프레임
+				s.sendError(c, baseMsg, protocol.ErrorCodeUnknownType, "unsupported frame type: "+baseMsg.Type)
+				continue
+			}
+			// 타입별 필드는 프레임에서 읽고, 공통 필드는 클라이언트가 보낸 값 대신 서버가 채움
+			// 수정 시각, 반응, 인용처럼 서버가 관리하는 필드는 비워 둠
+			fields, err := messageFields(msgBytes)
+			if err == nil {
+				err = msg.FromJson(fields)
+			}
 			if err != nil {
-				log.Println("Error saving message:", err)
+				s.sendError(c, baseMsg, protocol.ErrorCodeBadFrame, "malformed frame: "+err.Error())
+				continue
 			}
-		default:
-			log.Println("Unknown message type:", baseMsg.Type)
-			continue
+			id, _ := uuid.NewV7()
+			*msg.Base() = message.BaseMessage{
+				Id:        id,
+				RoomId:    roomID,
+				Type:      baseMsg.Type,
+				Author:    message.User{Id: userID},
+				Timestamp: s.config.Clock.Now().Format(time.RFC3339),
+			}
+			// 재전송은 전송량 제한에 걸리지 않도록 토큰을 가져가기 전에 중복 여부를 확인함
+			if !s.claimClientMessage(ctx, c, baseMsg, msg) {
+				continue
+			}
+			if !s.allowMessage(ctx, c, baseMsg) {
+				s.releaseClientMessage(ctx, c, baseMsg)
+				continue
+			}
+
+			if baseMsg.ReplyTo != "" {
+				// 스키마에서 UUID 형식을 검증함
+				replyToID, _ := uuid.Parse(baseMsg.ReplyTo)
+				if err := s.attachReply(ctx, roomID, msg, replyToID); err != nil {
+					s.releaseClientMessage(ctx, c, baseMsg)
+					s.sendChangeError(c, baseMsg, err)
+					continue
+				}
+			}
+			if text, ok := msg.(*message.TextMessage); ok {
+				if err := s.validateMentions(ctx, roomID, text.Content, text.Mentions); err != nil {
+					s.releaseClientMessage(ctx, c, baseMsg)
+					s.sendChangeError(c, baseMsg, err)
+					continue
+				}
+			}
+
+			s.sendMessage(ctx, c, baseMsg, msg)
 		}
 	}
 }
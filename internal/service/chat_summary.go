@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"log"
+	"server/internal/models/message"
+
+	"github.com/google/uuid"
+)
+
+// recordSummary는 저장된 메시지를 채팅방 목록의 요약에 반영하고, 작성자의 읽음 커서를 메시지로 옮기도록 모아 둡니다.
+// 자신이 보낸 메시지는 읽지 않은 메시지로 세지 않습니다.
+// 요약과 커서는 목록 표시용이므로 실패해도 메시지 저장은 실패시키지 않습니다.
+func (s *ChatServiceImpl) recordSummary(ctx context.Context, roomID string, msg message.Message) {
+	if err := s.summaryRepo.RecordMessage(ctx, roomID, msg); err != nil {
+		log.Println("Error recording room summary:", err)
+	}
+
+	// UUID가 아닌 방이나 사용자에게는 읽음 커서가 없음
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return
+	}
+	authorUUID, err := uuid.Parse(msg.GetAuthor().Id)
+	if err != nil {
+		return
+	}
+
+	// 커서는 나중에 옮기므로 그 전까지 작성자의 읽지 않은 메시지 수에서 바로 뺌
+	s.excludeFromUnread(ctx, roomID, msg.GetAuthor().Id, msg.GetID())
+	s.deferAuthorRead(roomUUID, authorUUID, msg.GetID())
+}
+
+// refreshSummary는 수정되거나 삭제된 메시지가 방의 마지막 메시지이면 요약을 바꿉니다.
+func (s *ChatServiceImpl) refreshSummary(ctx context.Context, roomID string, msg message.Message) {
+	if err := s.summaryRepo.RefreshLastMessage(ctx, roomID, msg); err != nil {
+		log.Println("Error refreshing room summary:", err)
+	}
+}
+
+// excludeFromUnread는 삭제되거나 숨긴 메시지를 채팅방 목록의 읽지 않은 메시지 수에서 뺍니다.
+// userID가 비어 있으면 모두에게서 삭제된 메시지이고, 아니면 그 사용자가 숨긴 메시지입니다.
+func (s *ChatServiceImpl) excludeFromUnread(ctx context.Context, roomID, userID string, messageID uuid.UUID) {
+	var err error
+	if userID == "" {
+		err = s.summaryRepo.RemoveMessage(ctx, roomID, messageID)
+	} else {
+		err = s.summaryRepo.HideMessage(ctx, roomID, userID, messageID)
+	}
+	if err != nil {
+		log.Println("Error excluding message from unread count:", err)
+	}
+}
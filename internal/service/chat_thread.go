@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"server/internal/broadcast"
+	"server/internal/models/message"
+	"server/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// 답장과 스레드:
+//
+// 답장은 방의 기록에 다른 메시지처럼 남으며, 인용한 메시지의 요약(replyTo)과 스레드 루트 ID(threadId)를 가집니다.
+// 저장소에는 인용한 메시지의 ID만 남고, 요약은 기록을 읽을 때 withQuotes가 채웁니다.
+// 답장에 다시 답장해도 같은 루트의 스레드에 속합니다. 스레드 참여자는 방을 구독하지 않아도
+// 스레드의 새 답장과 답장의 수정, 삭제, 반응 이벤트를 받습니다.
+
+// attachReply는 msg를 replyToID 메시지에 대한 답장으로 만듭니다. 삭제된 메시지에는 답장할 수 없습니다.
+// 스레드의 첫 답장이면 루트 메시지 작성자를 참여자로 등록합니다.
+func (s *ChatServiceImpl) attachReply(ctx context.Context, roomID string, msg message.Message, replyToID uuid.UUID) error {
+	parent, err := s.messageRepo.GetMessage(ctx, roomID, replyToID)
+	if err != nil {
+		return err
+	}
+	if _, ok := parent.(*message.DeletedMessage); ok {
+		return repository.ErrMessageNotFound
+	}
+
+	rootID := parent.GetID()
+	if threadID := parent.Base().ThreadId; threadID != nil {
+		rootID = *threadID
+	} else if err := s.messageRepo.JoinThread(ctx, roomID, rootID, parent.GetAuthor().Id); err != nil {
+		return err
+	}
+
+	msg.Base().ReplyTo = message.NewQuote(parent)
+	msg.Base().ThreadId = &rootID
+	return nil
+}
+
+// threadRoot는 메시지가 속한 스레드의 루트 메시지 ID를 반환합니다. 답장이 아니면 메시지 자신이 루트입니다.
+func threadRoot(msg message.Message) uuid.UUID {
+	if threadID := msg.Base().ThreadId; threadID != nil {
+		return *threadID
+	}
+	return msg.GetID()
+}
+
+// GetThread는 스레드의 루트 메시지와 답장 한 페이지를 반환합니다. 답장을 지정하면 그 답장이 속한 스레드를 조회합니다.
+func (s *ChatServiceImpl) GetThread(ctx context.Context, roomID, userID string, messageID uuid.UUID, query message.PageQuery) (message.Message, message.Page, error) {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return nil, message.Page{}, err
+	}
+
+	root, err := s.threadRootMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, message.Page{}, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultMessagePageLimit
+	}
+	if query.Limit > maxMessagePageLimit {
+		query.Limit = maxMessagePageLimit
+	}
+
+	page, err := s.messageRepo.GetThread(ctx, roomID, root.GetID(), query)
+	if err != nil {
+		return nil, message.Page{}, err
+	}
+
+	// 루트 메시지는 사용자가 숨겼어도 스레드의 머리로 보여 줌
+	if err := s.decorate(ctx, roomID, []message.Message{root}); err != nil {
+		return nil, message.Page{}, err
+	}
+	page.Messages, err = s.historyFor(ctx, roomID, userID, page.Messages)
+	if err != nil {
+		return nil, message.Page{}, err
+	}
+	return root, page, nil
+}
+
+// JoinThread는 사용자를 스레드 참여자로 등록합니다. 참여자는 방을 구독하지 않아도 스레드 이벤트를 받습니다.
+func (s *ChatServiceImpl) JoinThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return err
+	}
+
+	root, err := s.threadRootMessage(ctx, roomID, messageID)
+	if err != nil {
+		return err
+	}
+	return s.messageRepo.JoinThread(ctx, roomID, root.GetID(), userID)
+}
+
+// LeaveThread는 사용자를 스레드 참여자에서 뺍니다. 이후 답장을 보내면 다시 참여자가 됩니다.
+func (s *ChatServiceImpl) LeaveThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	if err := s.membership.check(ctx, roomID, userID); err != nil {
+		return err
+	}
+
+	root, err := s.threadRootMessage(ctx, roomID, messageID)
+	if err != nil {
+		return err
+	}
+	return s.messageRepo.LeaveThread(ctx, roomID, root.GetID(), userID)
+}
+
+// threadRootMessage는 메시지가 속한 스레드의 루트 메시지를 읽습니다.
+func (s *ChatServiceImpl) threadRootMessage(ctx context.Context, roomID string, messageID uuid.UUID) (message.Message, error) {
+	msg, err := s.messageRepo.GetMessage(ctx, roomID, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if rootID := threadRoot(msg); rootID != messageID {
+		return s.messageRepo.GetMessage(ctx, roomID, rootID)
+	}
+	return msg, nil
+}
+
+// withQuotes는 답장의 인용을 인용한 메시지의 최신 판으로 채웁니다.
+// 인용한 메시지가 수정되면 수정된 본문을, 삭제되거나 기록에서 사라졌으면 본문 없는 툼스톤 요약을 보여 줍니다.
+func (s *ChatServiceImpl) withQuotes(ctx context.Context, roomID string, messages []message.Message) error {
+	var parentIDs []uuid.UUID
+	for _, msg := range messages {
+		if replyTo := msg.Base().ReplyTo; replyTo != nil {
+			parentIDs = append(parentIDs, replyTo.Id)
+		}
+	}
+	if len(parentIDs) == 0 {
+		return nil
+	}
+
+	parents, err := s.messageRepo.GetMessagesByID(ctx, roomID, parentIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		replyTo := msg.Base().ReplyTo
+		if replyTo == nil {
+			continue
+		}
+		if parent, ok := parents[replyTo.Id]; ok {
+			msg.Base().ReplyTo = message.NewQuote(parent)
+		} else {
+			msg.Base().ReplyTo = &message.Quote{Id: replyTo.Id, Type: "deleted"}
+		}
+	}
+	return nil
+}
+
+// withThreads는 답장이 달린 메시지마다 스레드 요약을 채웁니다.
+func (s *ChatServiceImpl) withThreads(ctx context.Context, roomID string, messages []message.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(messages))
+	for i, msg := range messages {
+		ids[i] = msg.GetID()
+	}
+
+	summaries, err := s.messageRepo.GetThreadSummaries(ctx, roomID, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages {
+		if summary, ok := summaries[msg.GetID()]; ok {
+			msg.Base().Thread = &summary
+		}
+	}
+	return nil
+}
+
+// broadcastThreadUpdated는 새 답장과 스레드의 새 요약을 방과 방 밖의 스레드 참여자에게 보냅니다.
+func (s *ChatServiceImpl) broadcastThreadUpdated(ctx context.Context, roomID string, reply message.Message) {
+	rootID := threadRoot(reply)
+
+	summaries, err := s.messageRepo.GetThreadSummaries(ctx, roomID, []uuid.UUID{rootID})
+	if err != nil {
+		log.Println("Error reading thread summary:", err)
+		return
+	}
+
+	threadEvent := map[string]interface{}{
+		"type":     "threadUpdated",
+		"roomId":   roomID,
+		"threadId": rootID,
+		"thread":   summaries[rootID],
+		"message":  reply,
+	}
+
+	msgJSON, _ := json.Marshal(threadEvent)
+	s.broadcastChange(ctx, roomID, rootID, msgJSON)
+}
+
+// broadcastChange는 스레드에 속한 메시지의 이벤트를 방의 모든 구독자와 방을 구독하지 않은 스레드 참여자에게 보냅니다.
+// 답장이 없는 메시지면 방에만 보냅니다.
+func (s *ChatServiceImpl) broadcastChange(ctx context.Context, roomID string, rootID uuid.UUID, msgJSON []byte) {
+	s.broadcast(roomID, "", msgJSON)
+
+	participants, err := s.messageRepo.ThreadParticipants(ctx, roomID, rootID)
+	if err != nil {
+		log.Println("Error reading thread participants:", err)
+		return
+	}
+
+	// 방에서 나간 참여자에게는 보내지 않음
+	userIDs := make([]string, 0, len(participants))
+	for _, userID := range participants {
+		if err := s.membership.check(ctx, roomID, userID); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	s.sendToUsers(roomID, userIDs, msgJSON)
+}
+
+// sendToUsers는 모든 노드에 연결된 userIDs 사용자의 세션 중 roomID 방을 구독하지 않은 세션에 이벤트를 보냅니다.
+// roomID가 비어 있으면 구독과 관계없이 사용자의 모든 세션에 보냅니다.
+func (s *ChatServiceImpl) sendToUsers(roomID string, userIDs []string, msgJSON []byte) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	s.deliverToUsers(roomID, userIDs, msgJSON)
+
+	err := s.bus.Publish(context.Background(), broadcast.Envelope{
+		Origin:  s.nodeID,
+		RoomID:  roomID,
+		UserIDs: userIDs,
+		Payload: msgJSON,
+	})
+	if err != nil {
+		log.Println("Error publishing event:", err)
+	}
+}
+
+// deliverToUsers는 이 노드에 연결된 userIDs 사용자의 세션 중 roomID 방을 구독하지 않은 세션에 이벤트를 전달합니다.
+// 방을 구독한 세션은 방으로 보낸 같은 이벤트를 받으므로 건너뜁니다. roomID가 비어 있으면 모든 세션에 전달합니다.
+func (s *ChatServiceImpl) deliverToUsers(roomID string, userIDs []string, msgJSON []byte) {
+	users := make(map[string]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		users[userID] = struct{}{}
+	}
+
+	s.connectionMutex.RLock()
+	defer s.connectionMutex.RUnlock()
+
+	for _, c := range s.sessions {
+		if _, ok := users[c.userID]; !ok {
+			continue
+		}
+		if _, subscribed := c.rooms[roomID]; subscribed {
+			continue
+		}
+		if !c.enqueue(outbound{data: msgJSON}) && c.disconnect(closeSlowConsumer, closeSlowConsumerReason) {
+			log.Println("Disconnected slow consumer:", c.userID, c.sessionID)
+		}
+	}
+}
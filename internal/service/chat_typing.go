@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// 타이핑 상태:
+//
+// 방마다 타이핑 중인 사용자와 만료 시각을 TypingRepository에 두고, 목록이 바뀔 때마다 방에 typing 이벤트로
+// 타이핑 중인 사용자 전체를 보냅니다. typing 프레임을 받은 노드는 사용자별로 처리 시각과 만료 시각을 기억해
+// TypingThrottle 안에 다시 온 시작 프레임을 무시하고, TypingTTL 동안 갱신되지 않은 상태를 지웁니다.
+// 메시지를 보내거나 방의 마지막 연결이 끊기면 타이핑 상태도 지웁니다.
+
+type typingKey struct {
+	roomID string
+	userID string
+}
+
+type typingState struct {
+	// 마지막으로 처리한 시작 프레임의 시각과, 그때 정한 만료 시각
+	acceptedAt time.Time
+	expiresAt  time.Time
+}
+
+// typingFromFrame은 typing 프레임으로 받은 사용자의 타이핑 상태를 기록합니다.
+func (s *ChatServiceImpl) typingFromFrame(ctx context.Context, c *client, frame WebSocketMessage) {
+	if !frame.IsTyping {
+		s.stopTyping(ctx, frame.RoomId, c.userID)
+		return
+	}
+
+	now := s.config.Clock.Now()
+	key := typingKey{roomID: frame.RoomId, userID: c.userID}
+
+	s.typingMutex.Lock()
+	state, ok := s.typing[key]
+	if ok && now.Sub(state.acceptedAt) < s.config.TypingThrottle {
+		s.typingMutex.Unlock()
+		return
+	}
+	state = typingState{acceptedAt: now, expiresAt: now.Add(s.config.TypingTTL)}
+	s.typing[key] = state
+	s.typingMutex.Unlock()
+
+	typing, changed, err := s.typingRepo.StartTyping(ctx, frame.RoomId, c.userID, now, state.expiresAt)
+	if err != nil {
+		log.Println("Error recording typing status:", err)
+		return
+	}
+	if changed {
+		s.broadcastTypingStatus(frame.RoomId, c.userID, true, typing)
+	}
+}
+
+// stopTyping은 사용자가 타이핑을 멈췄음을 기록합니다. 다른 노드를 거쳐 시작한 타이핑도 지웁니다.
+func (s *ChatServiceImpl) stopTyping(ctx context.Context, roomID, userID string) {
+	s.typingMutex.Lock()
+	delete(s.typing, typingKey{roomID: roomID, userID: userID})
+	s.typingMutex.Unlock()
+
+	typing, changed, err := s.typingRepo.StopTyping(ctx, roomID, userID, s.config.Clock.Now())
+	if err != nil {
+		log.Println("Error clearing typing status:", err)
+		return
+	}
+	if changed {
+		s.broadcastTypingStatus(roomID, userID, false, typing)
+	}
+}
+
+// clearTyping은 메시지를 보냈거나 연결이 끊긴 사용자의 타이핑 상태를 지웁니다.
+// 메시지마다 저장소를 조회하지 않도록 이 노드가 타이핑을 받은 경우에만 지우며, 나머지는 만료로 정리됩니다.
+func (s *ChatServiceImpl) clearTyping(ctx context.Context, roomID, userID string) {
+	s.typingMutex.Lock()
+	_, ok := s.typing[typingKey{roomID: roomID, userID: userID}]
+	s.typingMutex.Unlock()
+
+	if ok {
+		s.stopTyping(ctx, roomID, userID)
+	}
+}
+
+// expireTyping은 TypingTTL 동안 갱신되지 않은 타이핑 상태를 주기적으로 찾아 지웁니다.
+func (s *ChatServiceImpl) expireTyping() {
+	ticker := s.config.Clock.NewTicker(s.config.typingSweepInterval())
+	defer ticker.Stop()
+
+	for range ticker.C() {
+		now := s.config.Clock.Now()
+
+		s.typingMutex.Lock()
+		var expired []typingKey
+		for key, state := range s.typing {
+			if !now.Before(state.expiresAt) {
+				expired = append(expired, key)
+				delete(s.typing, key)
+			}
+		}
+		s.typingMutex.Unlock()
+
+		for _, key := range expired {
+			typing, changed, err := s.typingRepo.ExpireTyping(context.Background(), key.roomID, key.userID, now)
+			if err != nil {
+				log.Println("Error expiring typing status:", err)
+				continue
+			}
+			if changed {
+				s.broadcastTypingStatus(key.roomID, key.userID, false, typing)
+			}
+		}
+	}
+}
+
+// broadcastTypingStatus는 userID 사용자의 타이핑 여부와 방에서 타이핑 중인 사용자 전체를 보냅니다.
+func (s *ChatServiceImpl) broadcastTypingStatus(roomID, userID string, isTyping bool, typing []string) {
+	typingEvent := map[string]interface{}{
+		"type":     "typing",
+		"roomId":   roomID,
+		"userId":   userID,
+		"isTyping": isTyping,
+		"userIds":  typing,
+	}
+
+	msgJSON, _ := json.Marshal(typingEvent)
+
+	// 자신에게는 타이핑 상태를 보내지 않음
+	s.broadcast(roomID, userID, msgJSON)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"server/internal/models/message"
 	"server/internal/models/orm"
+	"server/internal/models/presence"
 
 	"github.com/google/uuid"
 )
@@ -33,13 +34,33 @@ type RoomService interface {
 	CreateRoom(ctx context.Context, name string, creatorID uuid.UUID, participantIDs []uuid.UUID) (orm.Room, error)
 	GetRoomByID(ctx context.Context, id uuid.UUID) (orm.Room, error)
 	GetUserRooms(ctx context.Context, userID uuid.UUID) ([]orm.Room, error)
+	ListRooms(ctx context.Context, userID uuid.UUID, query RoomListQuery) (RoomListPage, error)
 	AddUserToRoom(ctx context.Context, roomID, userID uuid.UUID) error
 	RemoveUserFromRoom(ctx context.Context, roomID, userID uuid.UUID) error
 }
 
 type ChatService interface {
 	SaveMessage(ctx context.Context, roomID string, msg message.Message) error
-	GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error)
-	GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error)
-	HandleWebSocketConnection(ctx context.Context, roomID, userID string, conn interface{}) error
+	GetMessages(ctx context.Context, roomID, userID string, lastMessageID int64) (message.Page, error)
+	GetMessagesByUUID(ctx context.Context, roomID, userID string, lastMessageUUID uuid.UUID) (message.Page, error)
+	ListMessages(ctx context.Context, roomID, userID string, query message.PageQuery) (message.Page, error)
+	HandleWebSocketConnection(ctx context.Context, roomID, userID string, lastMessageID uuid.UUID, conn interface{}) error
+	EditMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID, content string, mentions []message.Mention) (message.Message, error)
+	GetMessageRevisions(ctx context.Context, roomID, userID string, messageID uuid.UUID) ([]message.Revision, error)
+	DeleteMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) (message.Message, error)
+	HideMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) error
+	GetThread(ctx context.Context, roomID, userID string, messageID uuid.UUID, query message.PageQuery) (message.Message, message.Page, error)
+	JoinThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error
+	LeaveThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error
+	ListMentions(ctx context.Context, userID string, query message.MentionQuery) (message.MentionPage, error)
+	ReadMentions(ctx context.Context, roomID, userID string) error
+}
+
+type MessageSearchService interface {
+	SearchMessages(ctx context.Context, userID string, query message.SearchQuery) (message.SearchPage, error)
+}
+
+type PresenceService interface {
+	GetPresence(ctx context.Context, viewerID string, userIDs []string) ([]presence.Presence, error)
+	SetHideLastSeen(ctx context.Context, userID string, hide bool) error
 }
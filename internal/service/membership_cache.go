@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"server/internal/repository"
+	"server/pkg/clock"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	membershipCacheTTL = 30 * time.Second
+	// 캐시 항목이 이 수를 넘으면 저장할 때 만료된 항목을 정리
+	membershipCacheSweepSize = 10000
+)
+
+// NotRoomMemberError는 채팅방 멤버가 아닌 사용자가 채팅방에 접근하려 할 때 반환됩니다.
+type NotRoomMemberError struct {
+	RoomID string
+	UserID string
+}
+
+func (e *NotRoomMemberError) Error() string {
+	return fmt.Sprintf("user %s is not a member of room %s", e.UserID, e.RoomID)
+}
+
+// membershipCache는 room_users 조회 결과를 잠시 보관해 프레임마다 Postgres를 조회하지 않도록 합니다.
+// 새로 초대된 사용자가 바로 입장할 수 있도록 멤버인 경우만 캐시하고,
+// 방에서 제거된 사용자의 항목은 버스로 받은 멤버 제거 이벤트에서 invalidate로 지웁니다.
+type membershipCache struct {
+	roomRepo repository.RoomRepository
+	ttl      time.Duration
+	clock    clock.Clock
+
+	mutex   sync.Mutex
+	entries map[string]time.Time
+}
+
+func newMembershipCache(roomRepo repository.RoomRepository, ttl time.Duration, clock clock.Clock) *membershipCache {
+	return &membershipCache{
+		roomRepo: roomRepo,
+		ttl:      ttl,
+		clock:    clock,
+		entries:  make(map[string]time.Time),
+	}
+}
+
+func membershipKey(roomID, userID string) string {
+	return roomID + ":" + userID
+}
+
+func (c *membershipCache) check(ctx context.Context, roomID, userID string) error {
+	key := membershipKey(roomID, userID)
+
+	c.mutex.Lock()
+	expiresAt, ok := c.entries[key]
+	c.mutex.Unlock()
+
+	if ok && c.clock.Now().Before(expiresAt) {
+		return nil
+	}
+
+	roomUUID, err := uuid.Parse(roomID)
+	if err != nil {
+		return &NotRoomMemberError{RoomID: roomID, UserID: userID}
+	}
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return &NotRoomMemberError{RoomID: roomID, UserID: userID}
+	}
+
+	isMember, err := c.roomRepo.IsUserInRoom(ctx, roomUUID, userUUID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		c.mutex.Lock()
+		delete(c.entries, key)
+		c.mutex.Unlock()
+		return &NotRoomMemberError{RoomID: roomID, UserID: userID}
+	}
+
+	c.mutex.Lock()
+	now := c.clock.Now()
+	if len(c.entries) >= membershipCacheSweepSize {
+		for k, exp := range c.entries {
+			if now.After(exp) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = now.Add(c.ttl)
+	c.mutex.Unlock()
+
+	return nil
+}
+
+// invalidate는 방에서 제거된 사용자의 캐시 항목을 지워 다음 확인에서 room_users를 다시 조회하게 합니다.
+func (c *membershipCache) invalidate(roomID, userID string) {
+	c.mutex.Lock()
+	delete(c.entries, membershipKey(roomID, userID))
+	c.mutex.Unlock()
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"html"
+	"server/internal/broadcast"
+	"server/internal/models/message"
+	"server/internal/repository"
+	"server/pkg/clock"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSearchPageLimit = 20
+	maxSearchPageLimit     = 50
+	// snippetLength는 검색 결과에 보여 줄 본문의 최대 글자 수입니다.
+	snippetLength = 80
+	// snippetLeadLength는 첫 일치 부분 앞에 남기는 글자 수입니다.
+	snippetLeadLength = 20
+)
+
+// ErrEmptySearchQuery는 검색어가 비어 있을 때 반환됩니다.
+var ErrEmptySearchQuery = errors.New("search text is empty")
+
+type MessageSearchServiceImpl struct {
+	searchRepo  repository.MessageSearchRepository
+	messageRepo repository.MessageRepository
+	membership  *membershipCache
+}
+
+func NewMessageSearchService(searchRepo repository.MessageSearchRepository, messageRepo repository.MessageRepository, roomRepo repository.RoomRepository, bus broadcast.Bus, clock clock.Clock) MessageSearchService {
+	s := &MessageSearchServiceImpl{
+		searchRepo:  searchRepo,
+		messageRepo: messageRepo,
+		membership:  newMembershipCache(roomRepo, membershipCacheTTL, clock),
+	}
+
+	// 방에서 제거된 사용자는 캐시가 만료되기 전에도 검색할 수 없어야 함
+	bus.Subscribe(func(env broadcast.Envelope) {
+		if env.RemovedUserID != "" {
+			s.membership.invalidate(env.RoomID, env.RemovedUserID)
+		}
+	})
+	return s
+}
+
+// SearchMessages는 사용자가 속한 방의 메시지를 검색합니다. 방을 지정하면 그 방의 멤버여야 합니다.
+// 사용자가 숨긴 메시지는 결과에서 빠지므로 한 페이지의 결과가 query.Limit개보다 적을 수 있습니다.
+func (s *MessageSearchServiceImpl) SearchMessages(ctx context.Context, userID string, query message.SearchQuery) (message.SearchPage, error) {
+	query.Text = strings.TrimSpace(query.Text)
+	if query.Text == "" {
+		return message.SearchPage{}, ErrEmptySearchQuery
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return message.SearchPage{}, err
+	}
+
+	if query.RoomID != "" {
+		if err := s.membership.check(ctx, query.RoomID, userID); err != nil {
+			return message.SearchPage{}, err
+		}
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultSearchPageLimit
+	}
+	if query.Limit > maxSearchPageLimit {
+		query.Limit = maxSearchPageLimit
+	}
+
+	page, err := s.searchRepo.SearchMessages(ctx, userUUID, query)
+	if err != nil {
+		return message.SearchPage{}, err
+	}
+	if page.Results, err = s.withoutHidden(ctx, userID, page.Results); err != nil {
+		return message.SearchPage{}, err
+	}
+
+	terms := strings.Fields(query.Text)
+	for i, result := range page.Results {
+		if text, ok := result.Message.(*message.TextMessage); ok {
+			page.Results[i].Snippet = highlightSnippet(text.Content, terms)
+		}
+	}
+
+	return page, nil
+}
+
+// withoutHidden은 검색 결과에서 사용자가 숨긴 메시지를 뺍니다. 숨긴 메시지는 방마다 조회합니다.
+func (s *MessageSearchServiceImpl) withoutHidden(ctx context.Context, userID string, results []message.SearchResult) ([]message.SearchResult, error) {
+	byRoom := make(map[string][]message.Message)
+	for _, result := range results {
+		roomID := result.Message.GetRoomID()
+		byRoom[roomID] = append(byRoom[roomID], result.Message)
+	}
+
+	visible := make(map[uuid.UUID]bool, len(results))
+	for roomID, messages := range byRoom {
+		messages, err := withoutHidden(ctx, s.messageRepo, roomID, userID, messages)
+		if err != nil {
+			return nil, err
+		}
+		for _, msg := range messages {
+			visible[msg.GetID()] = true
+		}
+	}
+
+	filtered := results[:0]
+	for _, result := range results {
+		if visible[result.Message.GetID()] {
+			filtered = append(filtered, result)
+		}
+	}
+	return filtered, nil
+}
+
+// highlightSnippet은 본문에서 검색어와 일치하는 부분을 <mark>로 감싸고, 첫 일치 부분 근처를 snippetLength 글자로 잘라 반환합니다.
+// 대소문자를 구분하지 않으며, 본문은 HTML 이스케이프합니다.
+func highlightSnippet(content string, terms []string) string {
+	runes := []rune(content)
+	lower := []rune(strings.Map(unicode.ToLower, content))
+
+	// 각 위치에서 가장 긴 일치 검색어의 길이
+	matched := make([]int, len(runes))
+	first := -1
+	for i := range lower {
+		for _, term := range terms {
+			termRunes := []rune(strings.Map(unicode.ToLower, term))
+			if len(termRunes) > matched[i] && i+len(termRunes) <= len(lower) && string(lower[i:i+len(termRunes)]) == string(termRunes) {
+				matched[i] = len(termRunes)
+			}
+		}
+		if matched[i] > 0 && first < 0 {
+			first = i
+		}
+	}
+
+	start := 0
+	if first > snippetLeadLength && len(runes) > snippetLength {
+		start = first - snippetLeadLength
+		if start > len(runes)-snippetLength {
+			start = len(runes) - snippetLength
+		}
+	}
+	end := start + snippetLength
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		if matched[i] > 0 {
+			stop := i + matched[i]
+			if stop > end {
+				stop = end
+			}
+			b.WriteString("<mark>" + html.EscapeString(string(runes[i:stop])) + "</mark>")
+			i = stop
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[i])))
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return b.String()
+}
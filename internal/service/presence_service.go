@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"server/internal/models/presence"
+	"server/internal/repository"
+	"server/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+// ErrPresenceNotVisible은 친구도 아니고 같은 방에도 없는 사용자의 접속 상태를 조회할 때 반환됩니다.
+var ErrPresenceNotVisible = errors.New("presence is not visible")
+
+type PresenceServiceImpl struct {
+	presenceRepo repository.PresenceRepository
+	friendRepo   repository.FriendRepository
+	roomRepo     repository.RoomRepository
+	clock        clock.Clock
+}
+
+func NewPresenceService(presenceRepo repository.PresenceRepository, friendRepo repository.FriendRepository, roomRepo repository.RoomRepository, clock clock.Clock) PresenceService {
+	return &PresenceServiceImpl{
+		presenceRepo: presenceRepo,
+		friendRepo:   friendRepo,
+		roomRepo:     roomRepo,
+		clock:        clock,
+	}
+}
+
+// GetPresence는 userIDs 사용자의 접속 상태를 요청한 순서대로 반환합니다. 중복된 ID는 한 번만 담습니다.
+// 본인, 친구로 추가한 사용자, 같은 방에 속한 사용자만 조회할 수 있으며, 하나라도 아니면 ErrPresenceNotVisible을 반환합니다.
+func (s *PresenceServiceImpl) GetPresence(ctx context.Context, viewerID string, userIDs []string) ([]presence.Presence, error) {
+	unique := make([]string, 0, len(userIDs))
+	seen := make(map[string]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		if _, ok := seen[userID]; ok {
+			continue
+		}
+		seen[userID] = struct{}{}
+		unique = append(unique, userID)
+	}
+
+	if err := s.checkVisible(ctx, viewerID, unique); err != nil {
+		return nil, err
+	}
+
+	records, err := s.presenceRepo.GetPresence(ctx, unique, s.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]presence.Presence, len(unique))
+	for i, userID := range unique {
+		statuses[i] = visiblePresence(userID, records[userID], viewerID)
+	}
+	return statuses, nil
+}
+
+// checkVisible은 viewerID 사용자가 userIDs 사용자의 접속 상태를 볼 수 있는지 확인합니다.
+// 친구 관계를 먼저 보고, 친구가 아닌 사용자만 같은 방에 속했는지 확인합니다.
+func (s *PresenceServiceImpl) checkVisible(ctx context.Context, viewerID string, userIDs []string) error {
+	viewerUUID, err := uuid.Parse(viewerID)
+	if err != nil {
+		return err
+	}
+
+	invisible := make(map[uuid.UUID]bool, len(userIDs))
+	others := make([]uuid.UUID, 0, len(userIDs))
+	for _, userID := range userIDs {
+		userUUID, err := uuid.Parse(userID)
+		if err != nil {
+			return ErrPresenceNotVisible
+		}
+		if userUUID != viewerUUID && !invisible[userUUID] {
+			invisible[userUUID] = true
+			others = append(others, userUUID)
+		}
+	}
+	if len(others) == 0 {
+		return nil
+	}
+
+	friendIDs, err := s.friendRepo.GetAddedFriendIDs(ctx, viewerUUID, others)
+	if err != nil {
+		return err
+	}
+	for _, friendID := range friendIDs {
+		delete(invisible, friendID)
+	}
+	if len(invisible) == 0 {
+		return nil
+	}
+
+	strangers := make([]uuid.UUID, 0, len(invisible))
+	for _, userID := range others {
+		if invisible[userID] {
+			strangers = append(strangers, userID)
+		}
+	}
+	roommateIDs, err := s.roomRepo.GetRoommateIDs(ctx, viewerUUID, strangers)
+	if err != nil {
+		return err
+	}
+	for _, roommateID := range roommateIDs {
+		delete(invisible, roommateID)
+	}
+	if len(invisible) > 0 {
+		return ErrPresenceNotVisible
+	}
+	return nil
+}
+
+func (s *PresenceServiceImpl) SetHideLastSeen(ctx context.Context, userID string, hide bool) error {
+	return s.presenceRepo.SetHideLastSeen(ctx, userID, hide)
+}
+
+// visiblePresence는 viewerID 사용자에게 보여 줄 접속 상태를 만듭니다.
+// 사용자가 마지막 접속 시각을 숨겼으면 본인에게만 보여 줍니다.
+func visiblePresence(userID string, record presence.Record, viewerID string) presence.Presence {
+	status := presence.Presence{UserId: userID, Status: record.Status}
+	if status.Status == "" {
+		status.Status = presence.StatusOffline
+	}
+	if !record.LastSeen.IsZero() && (!record.HideLastSeen || viewerID == userID) {
+		lastSeen := record.LastSeen
+		status.LastSeen = &lastSeen
+	}
+	return status
+}
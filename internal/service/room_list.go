@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"server/internal/models/message"
+	"server/internal/models/orm"
+	"server/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidRoomCursor는 채팅방 목록의 커서를 해석할 수 없을 때 반환됩니다.
+var ErrInvalidRoomCursor = errors.New("invalid room list cursor")
+
+const (
+	// 한 번에 조회하는 채팅방 수의 기본값과 최댓값
+	defaultRoomPageLimit = 20
+	maxRoomPageLimit     = 100
+)
+
+// RoomListQuery는 채팅방 목록의 한 페이지를 지정합니다.
+type RoomListQuery struct {
+	// 이전 페이지의 NextCursor. 비어 있으면 가장 최근에 활동한 방부터 조회
+	Cursor string
+	Limit  int
+}
+
+// RoomListEntry는 채팅방 목록에 보여 줄 방과 그 방의 요약입니다.
+type RoomListEntry struct {
+	orm.Room
+	// 가장 최근 메시지의 요약. 메시지가 없는 방이면 null
+	LastMessage *message.Quote `json:"lastMessage"`
+	// 가장 최근 메시지가 저장된 시각. 메시지가 없는 방이면 방을 만든 시각
+	LastActivityAt time.Time `json:"lastActivityAt"`
+	UnreadCount    int       `json:"unreadCount"`
+	MemberCount    int       `json:"memberCount"`
+}
+
+// RoomListPage는 조회한 채팅방과 다음 페이지 정보입니다. Rooms는 최근 활동 순서입니다.
+type RoomListPage struct {
+	Rooms   []RoomListEntry
+	HasMore bool
+	// 다음 페이지를 조회할 때 Cursor로 사용할 값. HasMore가 false이면 비어 있음
+	NextCursor string
+}
+
+// ListRooms는 사용자가 속한 채팅방을 최근 활동 순서로 페이지 단위로 조회합니다.
+// 활동 시각이 같으면 방 ID의 역순으로 정렬하며, Limit이 없으면 기본값을, 최댓값을 넘으면 최댓값을 사용합니다.
+// 순서는 Redis에 둔 사용자의 채팅방 목록을 따르며, 방 정보와 읽음 커서는 페이지에 든 방만 조회합니다.
+func (s *RoomServiceImpl) ListRooms(ctx context.Context, userID uuid.UUID, query RoomListQuery) (RoomListPage, error) {
+	if query.Limit <= 0 {
+		query.Limit = defaultRoomPageLimit
+	}
+	if query.Limit > maxRoomPageLimit {
+		query.Limit = maxRoomPageLimit
+	}
+
+	var after *message.RoomActivity
+	if query.Cursor != "" {
+		cursor, err := parseRoomCursor(query.Cursor)
+		if err != nil {
+			return RoomListPage{}, err
+		}
+		after = &message.RoomActivity{RoomID: cursor.roomID, LastActivity: time.UnixMilli(cursor.millis)}
+	}
+
+	activities, err := s.summaryRepo.ListRooms(ctx, userID.String(), after, query.Limit+1)
+	if errors.Is(err, repository.ErrRoomListNotFound) {
+		if err := s.buildRoomList(ctx, userID); err != nil {
+			return RoomListPage{}, err
+		}
+		activities, err = s.summaryRepo.ListRooms(ctx, userID.String(), after, query.Limit+1)
+	}
+	// 속한 방이 없으면 목록도 만들어지지 않음
+	if errors.Is(err, repository.ErrRoomListNotFound) {
+		return RoomListPage{Rooms: []RoomListEntry{}}, nil
+	}
+	if err != nil {
+		return RoomListPage{}, err
+	}
+
+	page := RoomListPage{HasMore: len(activities) > query.Limit}
+	if page.HasMore {
+		activities = activities[:query.Limit]
+		last := activities[len(activities)-1]
+		page.NextCursor = roomCursor{millis: last.LastActivity.UnixMilli(), roomID: last.RoomID}.String()
+	}
+
+	roomIDs := make([]uuid.UUID, 0, len(activities))
+	for _, activity := range activities {
+		if roomID, err := uuid.Parse(activity.RoomID); err == nil {
+			roomIDs = append(roomIDs, roomID)
+		}
+	}
+	memberships, err := s.roomRepo.GetUserRoomMembershipsByID(ctx, userID, roomIDs)
+	if err != nil {
+		return RoomListPage{}, err
+	}
+
+	rooms := make(map[string]orm.UserRoom, len(memberships))
+	readCursors := make(map[string]uuid.UUID, len(memberships))
+	for _, room := range memberships {
+		cursor := uuid.Nil
+		if room.LastReadMessageID != nil {
+			cursor = *room.LastReadMessageID
+		}
+		rooms[room.ID.String()] = room
+		readCursors[room.ID.String()] = cursor
+	}
+	summaries, err := s.summaryRepo.GetSummaries(ctx, userID.String(), readCursors)
+	if err != nil {
+		return RoomListPage{}, err
+	}
+
+	page.Rooms = make([]RoomListEntry, 0, len(activities))
+	for _, activity := range activities {
+		room, ok := rooms[activity.RoomID]
+		if !ok {
+			// 목록을 만드는 동안 나간 방처럼 더 이상 속하지 않은 방은 목록에서 정리함
+			if err := s.summaryRepo.RemoveMember(ctx, activity.RoomID, userID.String()); err != nil {
+				log.Println("Error removing stale room from room list:", err)
+			}
+			continue
+		}
+
+		entry := RoomListEntry{Room: room.Room, LastActivityAt: activity.LastActivity, MemberCount: room.MemberCount}
+		if summary, ok := summaries[activity.RoomID]; ok {
+			entry.LastMessage = summary.LastMessage
+			entry.UnreadCount = summary.UnreadCount
+		}
+		page.Rooms = append(page.Rooms, entry)
+	}
+	return page, nil
+}
+
+// buildRoomList는 Postgres의 멤버십으로 사용자의 채팅방 목록을 처음 만듭니다.
+func (s *RoomServiceImpl) buildRoomList(ctx context.Context, userID uuid.UUID) error {
+	memberships, err := s.roomRepo.GetUserRoomMemberships(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	rooms := make(map[string]time.Time, len(memberships))
+	for _, room := range memberships {
+		rooms[room.ID.String()] = room.CreatedAt
+	}
+	return s.summaryRepo.BuildRoomList(ctx, userID.String(), rooms)
+}
+
+// roomCursor는 채팅방 목록에서의 위치입니다. "<활동 시각(밀리초)>:<방 ID>" 문자열로 주고받습니다.
+type roomCursor struct {
+	millis int64
+	roomID string
+}
+
+func parseRoomCursor(value string) (roomCursor, error) {
+	ms, roomID, ok := strings.Cut(value, ":")
+	if !ok {
+		return roomCursor{}, ErrInvalidRoomCursor
+	}
+	millis, err := strconv.ParseInt(ms, 10, 64)
+	if err != nil {
+		return roomCursor{}, ErrInvalidRoomCursor
+	}
+	if _, err := uuid.Parse(roomID); err != nil {
+		return roomCursor{}, ErrInvalidRoomCursor
+	}
+	return roomCursor{millis: millis, roomID: roomID}, nil
+}
+
+func (c roomCursor) String() string {
+	return strconv.FormatInt(c.millis, 10) + ":" + c.roomID
+}
@@ -3,6 +3,8 @@ package service
 import (
 	"context"
 	"errors"
+	"log"
+	"server/internal/broadcast"
 	"server/internal/models/orm"
 	"server/internal/repository"
 
@@ -10,12 +12,17 @@ import (
 )
 
 type RoomServiceImpl struct {
-	roomRepo repository.RoomRepository
+	roomRepo    repository.RoomRepository
+	summaryRepo repository.RoomSummaryRepository
+	// 멤버 제거를 채팅 노드에 알려 멤버십 캐시를 지우게 하는 버스
+	bus broadcast.Bus
 }
 
-func NewRoomService(roomRepo repository.RoomRepository) RoomService {
+func NewRoomService(roomRepo repository.RoomRepository, summaryRepo repository.RoomSummaryRepository, bus broadcast.Bus) RoomService {
 	return &RoomServiceImpl{
-		roomRepo: roomRepo,
+		roomRepo:    roomRepo,
+		summaryRepo: summaryRepo,
+		bus:         bus,
 	}
 }
 
@@ -44,7 +51,14 @@ func (s *RoomServiceImpl) CreateRoom(ctx context.Context, name string, creatorID
 		return orm.Room{}, err
 	}
 
-	return s.roomRepo.FindByID(ctx, roomID)
+	room, err := s.roomRepo.FindByID(ctx, roomID)
+	if err != nil {
+		return orm.Room{}, err
+	}
+	for _, userID := range participantIDs {
+		s.addToRoomList(ctx, room, userID)
+	}
+	return room, nil
 }
 
 func (s *RoomServiceImpl) GetRoomByID(ctx context.Context, id uuid.UUID) (orm.Room, error) {
@@ -57,12 +71,16 @@ func (s *RoomServiceImpl) GetUserRooms(ctx context.Context, userID uuid.UUID) ([
 
 func (s *RoomServiceImpl) AddUserToRoom(ctx context.Context, roomID, userID uuid.UUID) error {
 
-	_, err := s.roomRepo.FindByID(ctx, roomID)
+	room, err := s.roomRepo.FindByID(ctx, roomID)
 	if err != nil {
 		return errors.New("room not found")
 	}
 
-	return s.roomRepo.AddUserToRoom(ctx, roomID, userID)
+	if err := s.roomRepo.AddUserToRoom(ctx, roomID, userID); err != nil {
+		return err
+	}
+	s.addToRoomList(ctx, room, userID)
+	return nil
 }
 
 func (s *RoomServiceImpl) RemoveUserFromRoom(ctx context.Context, roomID, userID uuid.UUID) error {
@@ -72,5 +90,28 @@ func (s *RoomServiceImpl) RemoveUserFromRoom(ctx context.Context, roomID, userID
 		return errors.New("room not found")
 	}
 
-	return s.roomRepo.RemoveUserFromRoom(ctx, roomID, userID)
+	if err := s.roomRepo.RemoveUserFromRoom(ctx, roomID, userID); err != nil {
+		return err
+	}
+	if err := s.summaryRepo.RemoveMember(ctx, roomID.String(), userID.String()); err != nil {
+		log.Println("Error removing room from room list:", err)
+	}
+
+	// 제거는 이미 반영되었으므로 발행에 실패해도 캐시는 membershipCacheTTL 안에 만료됨
+	err = s.bus.Publish(context.Background(), broadcast.Envelope{
+		RoomID:        roomID.String(),
+		RemovedUserID: userID.String(),
+	})
+	if err != nil {
+		log.Println("Error publishing member removal:", err)
+	}
+	return nil
+}
+
+// addToRoomList는 새 멤버의 채팅방 목록에 방을 넣습니다.
+// 목록은 조회할 때 Postgres로 다시 만들 수 있으므로 실패해도 멤버십 변경은 실패시키지 않습니다.
+func (s *RoomServiceImpl) addToRoomList(ctx context.Context, room orm.Room, userID uuid.UUID) {
+	if err := s.summaryRepo.AddMember(ctx, room.ID.String(), userID.String(), room.CreatedAt); err != nil {
+		log.Println("Error adding room to room list:", err)
+	}
 }
@@ -2,11 +2,8 @@ package authenticator
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"os"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type contextKey string
@@ -25,24 +22,12 @@ func JWTMiddleware(next http.Handler) http.Handler {
 
 		tokenString := r.Header.Get("Authorization")
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return hmacSecret, nil
-		})
-
-		if err != nil || !token.Valid {
+		user_id, err := ValidateToken(tokenString)
+		if err != nil {
 			http.Error(w, "Invalid token", http.StatusUnauthorized)
 			return
 		}
 
-		user_id, ok := token.Claims.(jwt.MapClaims)["user_id"]
-		if !ok {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
-		}
 		ctx := context.WithValue(r.Context(), ContextKeyUserID, user_id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,41 @@
+package authenticator
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// ValidateToken은 토큰을 검증하고 클레임에 담긴 user_id를 반환합니다.
+// 만료된 토큰은 ErrTokenExpired, 그 외의 실패는 ErrInvalidToken을 반환합니다.
+func ValidateToken(tokenString string) (string, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return hmacSecret, nil
+	})
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return "", ErrTokenExpired
+		}
+		return "", ErrInvalidToken
+	}
+	if !token.Valid {
+		return "", ErrInvalidToken
+	}
+
+	userID, ok := token.Claims.(jwt.MapClaims)[string(ContextKeyUserID)].(string)
+	if !ok || userID == "" {
+		return "", ErrInvalidToken
+	}
+	return userID, nil
+}
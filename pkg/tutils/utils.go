@@ -31,3 +31,7 @@ func CreateTokenByString(user_id string) string {
 	token, _ := authenticator.CreateToken(user_id, 24)
 	return token
 }
+func CreateExpiredToken(user_id uuid.UUID) string {
+	token, _ := authenticator.CreateToken(user_id.String(), -1)
+	return token
+}
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"context"
+	"server/internal/broadcast"
+	"server/internal/service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// readMessageEvent는 입장/퇴장 같은 다른 이벤트를 건너뛰고 다음 메시지 이벤트를 반환합니다.
+// 버스를 거친 입장 이벤트는 도착 시점이 노드마다 달라질 수 있습니다.
+func readMessageEvent(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	for {
+		event := readEvent(t, conn)
+		if event["type"] == "message" {
+			return event
+		}
+	}
+}
+
+func newNodeChatService(bus broadcast.Bus) service.ChatService {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.Bus = bus
+	return service.NewChatService(deps, service.DefaultChatConfig())
+}
+
+// assertCrossNodeDelivery는 서로 다른 노드에 연결된 두 사용자가 상대 노드에서 저장된 메시지를 정확히 한 번씩 받는지 확인합니다.
+func assertCrossNodeDelivery(t *testing.T, nodeA, nodeB service.ChatService) {
+	serverA, wsURLA := newChatServiceServer(nodeA)
+	defer serverA.Close()
+	serverB, wsURLB := newChatServiceServer(nodeB)
+	defer serverB.Close()
+
+	roomID := uuid.NewString()
+	userA := uuid.NewString()
+	userB := uuid.NewString()
+
+	connA := dialChat(t, wsURLA, roomID, userA)
+	defer connA.Close()
+
+	connB := dialChat(t, wsURLB, roomID, userB)
+	defer connB.Close()
+
+	// 다른 노드의 입장 이벤트도 전달되어야 함
+	joined := readEvent(t, connA)
+	assert.Equal(t, "userJoined", joined["type"])
+	assert.Equal(t, userB, joined["userId"])
+
+	saveTextMessage(t, nodeA, roomID, userA, "first")
+	saveTextMessage(t, nodeA, roomID, userA, "second")
+
+	// 발행한 노드에 중복 전달되지 않고 두 메시지가 순서대로 한 번씩 도착해야 함
+	assert.Equal(t, "first", readMessageEvent(t, connA)["content"])
+	assert.Equal(t, "second", readMessageEvent(t, connA)["content"])
+	assert.Equal(t, "first", readMessageEvent(t, connB)["content"])
+	assert.Equal(t, "second", readMessageEvent(t, connB)["content"])
+
+	// 반대 방향도 동일
+	saveTextMessage(t, nodeB, roomID, userB, "reply")
+	assert.Equal(t, "reply", readMessageEvent(t, connA)["content"])
+	assert.Equal(t, "reply", readMessageEvent(t, connB)["content"])
+}
+
+func TestCrossNodeFanOutWithLocalBus(t *testing.T) {
+	bus := broadcast.NewLocalBus()
+	defer bus.Close()
+
+	assertCrossNodeDelivery(t, newNodeChatService(bus), newNodeChatService(bus))
+}
+
+func TestCrossNodeFanOutWithRedisBus(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	clientA := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer clientA.Close()
+	clientB := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer clientB.Close()
+
+	busA := broadcast.NewRedisBus(clientA)
+	defer busA.Close()
+	busB := broadcast.NewRedisBus(clientB)
+	defer busB.Close()
+
+	assertCrossNodeDelivery(t, newNodeChatService(busA), newNodeChatService(busB))
+}
+
+func TestRedisBusDeliversToEverySubscriber(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	publisher := broadcast.NewRedisBus(client)
+	defer publisher.Close()
+	subscriber := broadcast.NewRedisBus(client)
+	defer subscriber.Close()
+
+	received := make(chan broadcast.Envelope, 2)
+	publisher.Subscribe(func(env broadcast.Envelope) { received <- env })
+	subscriber.Subscribe(func(env broadcast.Envelope) { received <- env })
+
+	err := publisher.Publish(context.Background(), broadcast.Envelope{
+		Origin:  "node-1",
+		RoomID:  "room-1",
+		Payload: []byte(`{"type":"message"}`),
+	})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case env := <-received:
+			assert.Equal(t, "node-1", env.Origin)
+			assert.Equal(t, "room-1", env.RoomID)
+			assert.JSONEq(t, `{"type":"message"}`, string(env.Payload))
+		case <-time.After(2 * time.Second):
+			t.Fatal("이벤트를 받지 못함")
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newDedupChatService(t *testing.T, msgRepo *MessageRepositoryMock) service.ChatService {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.MentionRepo = redisRepo.NewRedisMentionRepository(client)
+	deps.SummaryRepo = redisRepo.NewRedisRoomSummaryRepository(client)
+	return service.NewChatService(deps, service.DefaultChatConfig())
+}
+
+// readFrame은 주어진 타입의 프레임이 올 때까지 읽습니다.
+func readFrame(t *testing.T, conn *websocket.Conn, frameType string) map[string]interface{} {
+	for {
+		event := readEvent(t, conn)
+		if event["type"] == frameType {
+			return event
+		}
+	}
+}
+
+func sendClientMessage(t *testing.T, conn *websocket.Conn, roomID, clientMessageID, content string) {
+	err := conn.WriteJSON(map[string]string{
+		"type":            "message",
+		"roomId":          roomID,
+		"clientMessageId": clientMessageID,
+		"content":         content,
+	})
+	assert.NoError(t, err)
+}
+
+func TestSendMessageIsAcknowledged(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatService := newDedupChatService(t, msgRepo)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	conn := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer conn.Close()
+
+	sendClientMessage(t, conn, roomID, "client-1", "hello")
+
+	broadcasted := readFrame(t, conn, "message")
+	ack := readFrame(t, conn, "ack")
+	assert.Equal(t, roomID, ack["roomId"])
+	assert.Equal(t, "client-1", ack["clientMessageId"])
+	assert.Equal(t, broadcasted["id"], ack["messageId"])
+	assert.Equal(t, broadcasted["timestamp"], ack["timestamp"])
+	assert.NotEmpty(t, ack["timestamp"])
+}
+
+func TestRetriedMessageIsNotSavedTwice(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatService := newDedupChatService(t, msgRepo)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	userID := uuid.NewString()
+
+	conn := dialChat(t, wsURL, roomID, userID)
+	sendClientMessage(t, conn, roomID, "client-1", "hello")
+	firstAck := readFrame(t, conn, "ack")
+	conn.Close()
+
+	// ack를 받지 못했다고 판단한 클라이언트가 다시 접속해 같은 ID로 재시도
+	conn = dialChat(t, wsURL, roomID, userID)
+	defer conn.Close()
+	sendClientMessage(t, conn, roomID, "client-1", "hello")
+
+	retryAck := readFrame(t, conn, "ack")
+	assert.Equal(t, firstAck["messageId"], retryAck["messageId"])
+	assert.Equal(t, firstAck["timestamp"], retryAck["timestamp"])
+	msgRepo.AssertNumberOfCalls(t, "SaveMessage", 1)
+
+	// 다른 클라이언트 메시지 ID는 새 메시지로 저장됨
+	sendClientMessage(t, conn, roomID, "client-2", "world")
+	otherAck := readFrame(t, conn, "ack")
+	assert.NotEqual(t, firstAck["messageId"], otherAck["messageId"])
+	msgRepo.AssertNumberOfCalls(t, "SaveMessage", 2)
+}
+
+func TestFailedSendReturnsErrorAndCanBeRetried(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(errors.New("redis unavailable")).Once()
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatService := newDedupChatService(t, msgRepo)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	conn := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer conn.Close()
+
+	sendClientMessage(t, conn, roomID, "client-1", "hello")
+	errorFrame := readFrame(t, conn, "error")
+	assert.Equal(t, "storage_failure", errorFrame["code"])
+	assert.Equal(t, "client-1", errorFrame["clientMessageId"])
+
+	// 실패한 메시지는 중복으로 취급되지 않고 다시 저장됨
+	sendClientMessage(t, conn, roomID, "client-1", "hello")
+	ack := readFrame(t, conn, "ack")
+	assert.Equal(t, "client-1", ack["clientMessageId"])
+	msgRepo.AssertNumberOfCalls(t, "SaveMessage", 2)
+}
+
+func TestRedisMessageDedupRepositoryExpires(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	repo := redisRepo.NewRedisMessageDedupRepository(client)
+	ctx := context.Background()
+	userID := uuid.NewString()
+
+	first := message.Receipt{MessageId: uuid.New(), Timestamp: "2024-01-01T00:00:00Z"}
+	second := message.Receipt{MessageId: uuid.New(), Timestamp: "2024-01-01T00:00:01Z"}
+
+	original, claimed, err := repo.Claim(ctx, userID, "client-1", first, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, first, original)
+
+	// 저장이 끝나기 전에는 Receipt를 돌려주지 않음
+	_, claimed, err = repo.Claim(ctx, userID, "client-1", second, time.Second)
+	assert.ErrorIs(t, err, repository.ErrClaimPending)
+	assert.False(t, claimed)
+
+	assert.NoError(t, repo.Commit(ctx, userID, "client-1", time.Minute))
+	original, claimed, err = repo.Claim(ctx, userID, "client-1", second, time.Second)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	assert.Equal(t, first, original)
+
+	// 다른 사용자의 같은 클라이언트 ID는 별개로 취급됨
+	_, claimed, err = repo.Claim(ctx, uuid.NewString(), "client-1", second, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// 저장 완료된 선점은 Commit의 기간 동안 유지되고, 지나면 같은 ID도 새 메시지로 취급됨
+	mr.FastForward(30 * time.Second)
+	_, claimed, err = repo.Claim(ctx, userID, "client-1", second, time.Second)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+	mr.FastForward(31 * time.Second)
+	original, claimed, err = repo.Claim(ctx, userID, "client-1", second, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.Equal(t, second, original)
+
+	// 저장 중인 선점은 짧게만 유지되고, 해제된 선점에는 Commit이 아무것도 남기지 않음
+	mr.FastForward(2 * time.Second)
+	_, claimed, err = repo.Claim(ctx, userID, "client-1", first, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+	assert.NoError(t, repo.Release(ctx, userID, "client-1"))
+	assert.NoError(t, repo.Commit(ctx, userID, "client-1", time.Minute))
+	_, claimed, err = repo.Claim(ctx, userID, "client-1", first, time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestRetryWhileSavingIsNotAcknowledged(t *testing.T) {
+	saving := make(chan struct{})
+	release := make(chan struct{})
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		close(saving)
+		<-release
+	}).Return(errors.New("redis unavailable")).Once()
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	chatService := newDedupChatService(t, msgRepo)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	userID := uuid.NewString()
+	first := dialChat(t, wsURL, roomID, userID)
+	defer first.Close()
+	retry := dialChat(t, wsURL, roomID, userID)
+	defer retry.Close()
+
+	// 처음 메시지를 저장하는 동안 다른 연결로 재시도하면 ack 대신 send_pending을 받음
+	sendClientMessage(t, first, roomID, "client-1", "hello")
+	<-saving
+	sendClientMessage(t, retry, roomID, "client-1", "hello")
+	assert.Equal(t, "send_pending", readFrame(t, retry, "error")["code"])
+
+	// 처음 저장이 실패하면 같은 ID로 다시 보내 저장할 수 있음
+	close(release)
+	assert.Equal(t, "storage_failure", readFrame(t, first, "error")["code"])
+	sendClientMessage(t, retry, roomID, "client-1", "hello")
+	assert.Equal(t, "client-1", readFrame(t, retry, "ack")["clientMessageId"])
+	msgRepo.AssertNumberOfCalls(t, "SaveMessage", 2)
+}
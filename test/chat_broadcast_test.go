@@ -0,0 +1,335 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/models/message"
+	"server/internal/service"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newChatServiceServer는 실제 ChatService에 연결을 넘기는 테스트용 WebSocket 서버를 만듭니다.
+// 인증은 핸들러 테스트에서 다루므로 여기서는 roomId, userId, lastMessageId를 쿼리로 받습니다.
+func newChatServiceServer(chatService service.ChatService) (*httptest.Server, string) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		query := r.URL.Query()
+		lastMessageID, _ := uuid.Parse(query.Get("lastMessageId"))
+		err = chatService.HandleWebSocketConnection(context.Background(), query.Get("roomId"), query.Get("userId"), lastMessageID, conn)
+		if err != nil {
+			conn.Close()
+		}
+	}))
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	return server, wsURL
+}
+
+func dialChat(t *testing.T, wsURL, roomID, userID string) *websocket.Conn {
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?roomId="+roomID+"&userId="+userID, nil)
+	if err != nil {
+		t.Fatalf("WebSocket 연결 실패: %v", err)
+	}
+	return conn
+}
+
+func newMemberChatService() (service.ChatService, *MessageRepositoryMock) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	return service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig()), msgRepo
+}
+
+// readUntil은 조건을 만족하는 이벤트가 올 때까지 읽습니다. 시간 안에 오지 않으면 false를 반환합니다.
+func readUntil(t *testing.T, conn *websocket.Conn, timeout time.Duration, match func(event map[string]interface{}) bool) bool {
+	conn.SetReadDeadline(time.Now().Add(timeout))
+	for {
+		var event map[string]interface{}
+		if err := conn.ReadJSON(&event); err != nil {
+			return false
+		}
+		if match(event) {
+			return true
+		}
+	}
+}
+
+func TestConcurrentBroadcastsReachEveryClient(t *testing.T) {
+	chatService, _ := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	const clientCount = 5
+	const senders = 8
+	const messagesPerSender = 25
+
+	conns := make([]*websocket.Conn, clientCount)
+	for i := range conns {
+		conns[i] = dialChat(t, wsURL, roomID, uuid.NewString())
+		defer conns[i].Close()
+	}
+
+	// 첫 번째 클라이언트가 나머지 모두의 입장 이벤트를 받으면 모두 등록된 것
+	joined := 0
+	assert.True(t, readUntil(t, conns[0], 2*time.Second, func(event map[string]interface{}) bool {
+		if event["type"] == "userJoined" {
+			joined++
+		}
+		return joined == clientCount-1
+	}))
+
+	var wg sync.WaitGroup
+	received := make([]int, clientCount)
+	for i, conn := range conns {
+		wg.Add(1)
+		go func(i int, conn *websocket.Conn) {
+			defer wg.Done()
+			readUntil(t, conn, 5*time.Second, func(event map[string]interface{}) bool {
+				if event["type"] == "message" {
+					received[i]++
+				}
+				return received[i] == senders*messagesPerSender
+			})
+		}(i, conn)
+	}
+
+	var senderWg sync.WaitGroup
+	for s := 0; s < senders; s++ {
+		senderWg.Add(1)
+		go func(s int) {
+			defer senderWg.Done()
+			for m := 0; m < messagesPerSender; m++ {
+				msg := &message.TextMessage{
+					BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: "sender"}},
+					Content:     fmt.Sprintf("%d-%d", s, m),
+				}
+				msg.GenerateID()
+				assert.NoError(t, chatService.SaveMessage(context.Background(), roomID, msg))
+			}
+		}(s)
+	}
+	senderWg.Wait()
+	wg.Wait()
+
+	for i := range received {
+		assert.Equal(t, senders*messagesPerSender, received[i], "client %d", i)
+	}
+}
+
+func TestSlowConsumerIsDisconnected(t *testing.T) {
+	chatService, _ := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	fastUserID := uuid.NewString()
+	slowUserID := uuid.NewString()
+
+	fast := dialChat(t, wsURL, roomID, fastUserID)
+	defer fast.Close()
+	slow := dialChat(t, wsURL, roomID, slowUserID)
+	defer slow.Close()
+
+	assert.True(t, readUntil(t, fast, 2*time.Second, func(event map[string]interface{}) bool {
+		return event["type"] == "userJoined" && event["userId"] == slowUserID
+	}))
+
+	// 빠른 클라이언트는 받은 메시지 수를 기록하면서 느린 클라이언트의 퇴장을 기다림
+	var fastReceived atomic.Int64
+	var readerDone atomic.Bool
+	left := make(chan bool, 1)
+	go func() {
+		defer readerDone.Store(true)
+		left <- readUntil(t, fast, 10*time.Second, func(event map[string]interface{}) bool {
+			if event["type"] == "message" {
+				fastReceived.Add(1)
+			}
+			return event["type"] == "userLeft" && event["userId"] == slowUserID
+		})
+	}()
+
+	// 느린 클라이언트는 읽지 않으므로 소켓 버퍼가 차고 송신 큐가 넘치게 됨
+	// 빠른 클라이언트의 큐는 넘치지 않도록 전송 속도를 맞춤
+	content := strings.Repeat("x", 32*1024)
+	for i := 0; i < 2000; i++ {
+		for int64(i)-fastReceived.Load() > 64 && !readerDone.Load() {
+			time.Sleep(time.Millisecond)
+		}
+
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: fastUserID}},
+			Content:     content,
+		}
+		msg.GenerateID()
+		chatService.SaveMessage(context.Background(), roomID, msg)
+
+		select {
+		case ok := <-left:
+			assert.True(t, ok)
+			return
+		default:
+		}
+	}
+
+	assert.True(t, <-left, "느린 소비자의 퇴장 이벤트를 받지 못함")
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) map[string]interface{} {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event map[string]interface{}
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("이벤트 수신 실패: %v", err)
+	}
+	return event
+}
+
+func saveTextMessage(t *testing.T, chatService service.ChatService, roomID, userID, content string) {
+	msg := &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: userID}},
+		Content:     content,
+	}
+	msg.GenerateID()
+	assert.NoError(t, chatService.SaveMessage(context.Background(), roomID, msg))
+}
+
+func TestMultipleDevicesPerUser(t *testing.T) {
+	chatService, _ := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	observerID := uuid.NewString()
+	userID := uuid.NewString()
+
+	observer := dialChat(t, wsURL, roomID, observerID)
+	defer observer.Close()
+
+	phone := dialChat(t, wsURL, roomID, userID)
+	defer phone.Close()
+
+	// 첫 번째 기기 접속 시에만 입장 이벤트가 발생
+	joined := readEvent(t, observer)
+	assert.Equal(t, "userJoined", joined["type"])
+	assert.Equal(t, userID, joined["userId"])
+
+	desktop := dialChat(t, wsURL, roomID, userID)
+	defer desktop.Close()
+
+	// 두 번째 기기가 등록될 시간을 준 뒤 메시지 전송
+	time.Sleep(100 * time.Millisecond)
+	saveTextMessage(t, chatService, roomID, observerID, "hello")
+
+	// 입장 이벤트 없이 바로 메시지가 와야 하고, 사용자의 두 기기 모두 메시지를 받아야 함
+	assert.Equal(t, "message", readEvent(t, observer)["type"])
+	assert.Equal(t, "hello", readEvent(t, phone)["content"])
+	assert.Equal(t, "hello", readEvent(t, desktop)["content"])
+
+	// 한 기기만 끊기면 퇴장 이벤트가 발생하지 않고 남은 기기는 계속 메시지를 받음
+	phone.Close()
+	time.Sleep(100 * time.Millisecond)
+	saveTextMessage(t, chatService, roomID, observerID, "still here?")
+
+	assert.Equal(t, "message", readEvent(t, observer)["type"])
+	assert.Equal(t, "still here?", readEvent(t, desktop)["content"])
+
+	// 마지막 기기가 끊기면 퇴장 이벤트 발생
+	desktop.Close()
+	left := readEvent(t, observer)
+	assert.Equal(t, "userLeft", left["type"])
+	assert.Equal(t, userID, left["userId"])
+}
+
+func TestMultiplexRoomsOverSingleConnection(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
+
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomA := uuid.New()
+	roomB := uuid.New()
+	forbiddenRoom := uuid.New()
+	userID := uuid.New()
+	observerID := uuid.New()
+
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, forbiddenRoom, userID).Return(false, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	observer := dialChat(t, wsURL, roomA.String(), observerID.String())
+	defer observer.Close()
+	assert.NoError(t, observer.WriteJSON(map[string]string{"type": "subscribe", "roomId": roomB.String()}))
+
+	// 방을 지정하지 않고 연결한 뒤 subscribe 프레임으로 두 방을 구독
+	conn := dialChat(t, wsURL, "", userID.String())
+	defer conn.Close()
+
+	for _, roomID := range []uuid.UUID{forbiddenRoom, roomA, roomB} {
+		assert.NoError(t, conn.WriteJSON(map[string]string{"type": "subscribe", "roomId": roomID.String()}))
+	}
+
+	// 멤버가 아닌 방의 구독은 오류로 응답함
+	rejected := readEvent(t, conn)
+	assert.Equal(t, "error", rejected["type"])
+	assert.Equal(t, "not_member", rejected["code"])
+	assert.Equal(t, forbiddenRoom.String(), rejected["roomId"])
+
+	joinedRooms := map[string]bool{}
+	assert.True(t, readUntil(t, observer, 2*time.Second, func(event map[string]interface{}) bool {
+		if event["type"] == "userJoined" && event["userId"] == userID.String() {
+			joinedRooms[event["roomId"].(string)] = true
+		}
+		return len(joinedRooms) == 2
+	}))
+
+	saveTextMessage(t, chatService, forbiddenRoom.String(), observerID.String(), "secret")
+	saveTextMessage(t, chatService, roomA.String(), observerID.String(), "to A")
+	saveTextMessage(t, chatService, roomB.String(), observerID.String(), "to B")
+
+	// 멤버가 아닌 방의 메시지는 받지 않고, 각 이벤트는 roomId로 구분됨
+	eventA := readEvent(t, conn)
+	assert.Equal(t, roomA.String(), eventA["roomId"])
+	assert.Equal(t, "to A", eventA["content"])
+	eventB := readEvent(t, conn)
+	assert.Equal(t, roomB.String(), eventB["roomId"])
+	assert.Equal(t, "to B", eventB["content"])
+
+	// 구독 해제한 방의 메시지는 더 이상 받지 않음
+	assert.NoError(t, conn.WriteJSON(map[string]string{"type": "unsubscribe", "roomId": roomA.String()}))
+	assert.True(t, readUntil(t, observer, 2*time.Second, func(event map[string]interface{}) bool {
+		return event["type"] == "userLeft" && event["roomId"] == roomA.String()
+	}))
+
+	saveTextMessage(t, chatService, roomA.String(), observerID.String(), "to A again")
+	saveTextMessage(t, chatService, roomB.String(), observerID.String(), "to B again")
+
+	next := readEvent(t, conn)
+	assert.Equal(t, roomB.String(), next["roomId"])
+	assert.Equal(t, "to B again", next["content"])
+}
@@ -7,6 +7,9 @@ import (
 	"net/http/httptest"
 	"server/internal/handler/chatting"
 	"server/internal/models/message"
+	"server/internal/repository"
+	"server/internal/service"
+	"server/pkg/authenticator"
 	"testing"
 
 	"github.com/google/uuid"
@@ -24,18 +27,71 @@ func (m *ChatServiceMock) SaveMessage(ctx context.Context, roomID string, msg me
 	return args.Error(0)
 }
 
-func (m *ChatServiceMock) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *ChatServiceMock) GetMessages(ctx context.Context, roomID, userID string, lastMessageID int64) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageID)
+	return args.Get(0).(message.Page), args.Error(1)
 }
 
-func (m *ChatServiceMock) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageUUID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *ChatServiceMock) GetMessagesByUUID(ctx context.Context, roomID, userID string, lastMessageUUID uuid.UUID) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageUUID)
+	return args.Get(0).(message.Page), args.Error(1)
 }
 
-func (m *ChatServiceMock) HandleWebSocketConnection(ctx context.Context, roomID, userID string, conn interface{}) error {
-	args := m.Called(ctx, roomID, userID, conn)
+func (m *ChatServiceMock) ListMessages(ctx context.Context, roomID, userID string, query message.PageQuery) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, query)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *ChatServiceMock) HandleWebSocketConnection(ctx context.Context, roomID, userID string, lastMessageID uuid.UUID, conn interface{}) error {
+	args := m.Called(ctx, roomID, userID, lastMessageID, conn)
+	return args.Error(0)
+}
+
+func (m *ChatServiceMock) EditMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID, content string, mentions []message.Mention) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID, content, mentions)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *ChatServiceMock) GetMessageRevisions(ctx context.Context, roomID, userID string, messageID uuid.UUID) ([]message.Revision, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Get(0).([]message.Revision), args.Error(1)
+}
+
+func (m *ChatServiceMock) DeleteMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *ChatServiceMock) HideMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *ChatServiceMock) GetThread(ctx context.Context, roomID, userID string, messageID uuid.UUID, query message.PageQuery) (message.Message, message.Page, error) {
+	args := m.Called(ctx, roomID, userID, messageID, query)
+	root, _ := args.Get(0).(message.Message)
+	return root, args.Get(1).(message.Page), args.Error(2)
+}
+
+func (m *ChatServiceMock) JoinThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *ChatServiceMock) LeaveThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *ChatServiceMock) ListMentions(ctx context.Context, userID string, query message.MentionQuery) (message.MentionPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(message.MentionPage), args.Error(1)
+}
+
+func (m *ChatServiceMock) ReadMentions(ctx context.Context, roomID, userID string) error {
+	args := m.Called(ctx, roomID, userID)
 	return args.Error(0)
 }
 
@@ -48,6 +104,7 @@ func TestChatHandlerGetMessages(t *testing.T) {
 
 	// 테스트 데이터
 	roomID := "room-123"
+	userID := uuid.New()
 	lastMsgID := int64(100)
 
 	msg1 := &message.BaseMessage{
@@ -65,11 +122,14 @@ func TestChatHandlerGetMessages(t *testing.T) {
 	messages := []message.Message{msg1, msg2}
 
 	// mock 서비스 동작 설정
-	chatService.On("GetMessages", mock.Anything, roomID, lastMsgID).Return(messages, nil)
+	chatService.On("GetMessages", mock.Anything, roomID, userID.String(), lastMsgID).Return(message.Page{Messages: messages}, nil)
 
 	// 테스트 요청 생성
 	req, _ := http.NewRequest("GET", "/messages?roomId="+roomID+"&lastMessageId=100", nil)
 
+	// 컨텍스트에 사용자 ID 추가 (미들웨어에서 수행되는 작업 시뮬레이션)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+
 	// 응답 레코더 생성
 	rr := httptest.NewRecorder()
 
@@ -99,6 +159,7 @@ func TestChatHandlerGetMessagesByUUID(t *testing.T) {
 
 	// 테스트 데이터
 	roomID := "room-123"
+	userID := uuid.New()
 	lastMsgUUID := uuid.New()
 
 	msg1 := &message.BaseMessage{
@@ -114,13 +175,18 @@ func TestChatHandlerGetMessagesByUUID(t *testing.T) {
 	}
 
 	messages := []message.Message{msg1, msg2}
+	nextCursor := uuid.New()
 
 	// mock 서비스 동작 설정
-	chatService.On("GetMessagesByUUID", mock.Anything, roomID, lastMsgUUID).Return(messages, nil)
+	chatService.On("GetMessagesByUUID", mock.Anything, roomID, userID.String(), lastMsgUUID).
+		Return(message.Page{Messages: messages, HasMore: true, NextCursor: nextCursor}, nil)
 
 	// 테스트 요청 생성
 	req, _ := http.NewRequest("GET", "/messages?roomId="+roomID+"&lastMessageId="+lastMsgUUID.String(), nil)
 
+	// 컨텍스트에 사용자 ID 추가 (미들웨어에서 수행되는 작업 시뮬레이션)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+
 	// 응답 레코더 생성
 	rr := httptest.NewRecorder()
 
@@ -136,7 +202,115 @@ func TestChatHandlerGetMessagesByUUID(t *testing.T) {
 
 	assert.Equal(t, true, response["success"])
 	assert.NotNil(t, response["messages"])
+	// 한도를 넘으면 이어서 조회할 커서를 알려 줌
+	assert.Equal(t, true, response["hasMore"])
+	assert.Equal(t, nextCursor.String(), response["nextCursor"])
 
 	// 모의 서비스 호출 확인
 	chatService.AssertExpectations(t)
 }
+
+func TestChatHandlerGetMessagesForbiddenForNonMember(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	handler := chatting.NewChatHandler(chatService)
+
+	roomID := uuid.NewString()
+	userID := uuid.New()
+
+	chatService.On("ListMessages", mock.Anything, roomID, userID.String(), message.PageQuery{}).
+		Return(message.Page{}, &service.NotRoomMemberError{RoomID: roomID, UserID: userID.String()})
+
+	req, _ := http.NewRequest("GET", "/messages?roomId="+roomID, nil)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+	rr := httptest.NewRecorder()
+
+	handler.GetMessages(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestChatHandlerListMessagesPage(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	handler := chatting.NewChatHandler(chatService)
+
+	roomID := uuid.NewString()
+	userID := uuid.New()
+	before, _ := uuid.NewV7()
+	oldest, _ := uuid.NewV7()
+
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{Id: oldest, RoomId: roomID, Type: "message"}, Content: "hi"}
+	chatService.On("ListMessages", mock.Anything, roomID, userID.String(), message.PageQuery{Before: before, Limit: 20}).
+		Return(message.Page{Messages: []message.Message{msg}, HasMore: true, NextCursor: oldest}, nil)
+
+	req, _ := http.NewRequest("GET", "/messages?roomId="+roomID+"&before="+before.String()+"&limit=20", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+	rr := httptest.NewRecorder()
+
+	handler.GetMessages(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, true, response["hasMore"])
+	assert.Equal(t, oldest.String(), response["nextCursor"])
+	assert.Len(t, response["messages"], 1)
+	chatService.AssertExpectations(t)
+}
+
+func TestChatHandlerListMessagesRejectsInvalidQuery(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	handler := chatting.NewChatHandler(chatService)
+
+	roomID := uuid.NewString()
+	cursor := uuid.NewString()
+
+	for _, query := range []string{
+		"&before=" + cursor + "&after=" + cursor,
+		"&before=not-a-uuid",
+		"&after=not-a-uuid",
+		"&limit=0",
+		"&limit=many",
+	} {
+		req, _ := http.NewRequest("GET", "/messages?roomId="+roomID+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, uuid.NewString()))
+		rr := httptest.NewRecorder()
+
+		handler.GetMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+	chatService.AssertNotCalled(t, "ListMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestChatHandlerGetMessagesRejectsNonV7Cursor(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	handler := chatting.NewChatHandler(chatService)
+
+	roomID := uuid.NewString()
+	userID := uuid.New()
+	cursor := uuid.New()
+
+	chatService.On("GetMessagesByUUID", mock.Anything, roomID, userID.String(), cursor).
+		Return(message.Page{}, repository.ErrInvalidMessageID)
+
+	req, _ := http.NewRequest("GET", "/messages?roomId="+roomID+"&lastMessageId="+cursor.String(), nil)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+	rr := httptest.NewRecorder()
+
+	handler.GetMessages(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
+
+func TestChatHandlerGetMessagesRequiresAuth(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	handler := chatting.NewChatHandler(chatService)
+
+	req, _ := http.NewRequest("GET", "/messages?roomId=room-123", nil)
+	rr := httptest.NewRecorder()
+
+	handler.GetMessages(rr, req)
+
+	assert.Equal(t, http.StatusUnauthorized, rr.Code)
+}
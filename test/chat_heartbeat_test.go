@@ -0,0 +1,147 @@
+package test
+
+import (
+	"server/internal/service"
+	"server/pkg/clock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newHeartbeatChatService(fakeClock *clock.FakeClock) service.ChatService {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	config := service.DefaultChatConfig()
+	config.PingInterval = 30 * time.Second
+	config.PongWait = 10 * time.Second
+	config.ReadTimeout = 90 * time.Second
+	config.MaxMessageSize = 1024
+	config.Clock = fakeClock
+
+	return service.NewChatService(chatDeps(msgRepo, roomRepo), config)
+}
+
+// startReading은 연결을 계속 읽어 ping에 pong으로 응답하고, 받은 ping과 이벤트를 채널로 전달합니다.
+func startReading(conn *websocket.Conn) (pings chan struct{}, events chan map[string]interface{}) {
+	pings = make(chan struct{}, 16)
+	events = make(chan map[string]interface{}, 16)
+
+	defaultPingHandler := conn.PingHandler()
+	conn.SetPingHandler(func(data string) error {
+		err := defaultPingHandler(data)
+		pings <- struct{}{}
+		return err
+	})
+
+	go func() {
+		for {
+			var event map[string]interface{}
+			if err := conn.ReadJSON(&event); err != nil {
+				close(events)
+				return
+			}
+			events <- event
+		}
+	}()
+
+	return pings, events
+}
+
+func waitFor[T any](t *testing.T, ch <-chan T, what string) T {
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s 대기 시간 초과", what)
+	}
+	var zero T
+	return zero
+}
+
+func TestUnansweredPingReapsConnection(t *testing.T) {
+	fakeClock := clock.NewFake(time.Now())
+	chatService := newHeartbeatChatService(fakeClock)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	observerID := uuid.NewString()
+	deadUserID := uuid.NewString()
+
+	observer := dialChat(t, wsURL, roomID, observerID)
+	defer observer.Close()
+	pings, events := startReading(observer)
+
+	// 읽지 않는 클라이언트는 ping에 pong으로 응답하지 않음 (반쯤 열린 모바일 연결과 같음)
+	dead := dialChat(t, wsURL, roomID, deadUserID)
+	defer dead.Close()
+
+	joined := waitFor(t, events, "입장 이벤트")
+	assert.Equal(t, "userJoined", joined["type"])
+
+	// ping 주기가 지나면 두 연결 모두 ping을 받고, 관찰자만 pong으로 응답함
+	fakeClock.Advance(30 * time.Second)
+	waitFor(t, pings, "ping")
+	time.Sleep(100 * time.Millisecond)
+
+	// pong 대기 시간이 지나기 전에는 아무도 정리되지 않음
+	fakeClock.Advance(5 * time.Second)
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case event := <-events:
+		t.Fatalf("예상하지 못한 이벤트: %v", event)
+	default:
+	}
+
+	// pong 대기 시간이 지나면 응답하지 않은 연결이 정리되고 퇴장 이벤트가 발생함
+	fakeClock.Advance(6 * time.Second)
+	left := waitFor(t, events, "퇴장 이벤트")
+	assert.Equal(t, "userLeft", left["type"])
+	assert.Equal(t, deadUserID, left["userId"])
+}
+
+func TestRespondingConnectionOutlivesReadTimeout(t *testing.T) {
+	fakeClock := clock.NewFake(time.Now())
+	chatService := newHeartbeatChatService(fakeClock)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	conn := dialChat(t, wsURL, uuid.NewString(), uuid.NewString())
+	defer conn.Close()
+	pings, events := startReading(conn)
+
+	// pong에 응답하는 동안에는 읽기 타임아웃이 지나도 연결이 유지됨
+	for i := 0; i < 4; i++ {
+		fakeClock.Advance(30 * time.Second)
+		waitFor(t, pings, "ping")
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	select {
+	case _, ok := <-events:
+		assert.True(t, ok, "pong에 응답하는 연결이 끊김")
+	default:
+	}
+}
+
+func TestOversizedFrameClosesConnection(t *testing.T) {
+	fakeClock := clock.NewFake(time.Now())
+	chatService := newHeartbeatChatService(fakeClock)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	conn := dialChat(t, wsURL, uuid.NewString(), uuid.NewString())
+	defer conn.Close()
+
+	err := conn.WriteJSON(map[string]string{"type": "message", "content": strings.Repeat("x", 2048)})
+	assert.NoError(t, err)
+
+	assert.Equal(t, websocket.CloseMessageTooBig, readCloseCode(t, conn))
+}
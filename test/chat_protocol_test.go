@@ -0,0 +1,104 @@
+package test
+
+import (
+	"errors"
+	"server/internal/protocol"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFrames(t *testing.T) {
+	roomID := uuid.NewString()
+
+	tests := []struct {
+		name  string
+		frame string
+		code  string
+	}{
+		{"메시지", `{"type":"message","roomId":"` + roomID + `","content":"hi","clientMessageId":"c-1"}`, ""},
+		{"구독", `{"type":"subscribe","roomId":"` + roomID + `","lastMessageId":"` + uuid.NewString() + `"}`, ""},
+		{"구독 해제", `{"type":"unsubscribe","roomId":"` + roomID + `"}`, ""},
+		{"타이핑", `{"type":"typing","roomId":"` + roomID + `","isTyping":true}`, ""},
+		{"이미지", `{"type":"image","roomId":"` + roomID + `","imageUrl":"https://example.com/a.png"}`, ""},
+		{"알 수 없는 필드는 허용", `{"type":"typing","roomId":"` + roomID + `","extra":1}`, ""},
+		{"JSON이 아님", `not json`, protocol.ErrorCodeBadFrame},
+		{"객체가 아님", `["message"]`, protocol.ErrorCodeBadFrame},
+		{"type 없음", `{"roomId":"` + roomID + `"}`, protocol.ErrorCodeBadFrame},
+		{"type이 문자열이 아님", `{"type":1}`, protocol.ErrorCodeBadFrame},
+		{"정의되지 않은 type", `{"type":"dance","roomId":"` + roomID + `"}`, protocol.ErrorCodeUnknownType},
+		{"roomId 없음", `{"type":"message","content":"hi"}`, protocol.ErrorCodeBadFrame},
+		{"빈 roomId", `{"type":"message","roomId":"","content":"hi"}`, protocol.ErrorCodeBadFrame},
+		{"content 없음", `{"type":"message","roomId":"` + roomID + `"}`, protocol.ErrorCodeBadFrame},
+		{"isTyping이 불리언이 아님", `{"type":"typing","roomId":"` + roomID + `","isTyping":"yes"}`, protocol.ErrorCodeBadFrame},
+		{"lastMessageId가 UUID가 아님", `{"type":"subscribe","roomId":"` + roomID + `","lastMessageId":"42"}`, protocol.ErrorCodeBadFrame},
+		{"멘션", `{"type":"message","roomId":"` + roomID + `","content":"@a","mentions":[{"offset":0,"length":2,"type":"all"}]}`, ""},
+		{"mentions가 배열이 아님", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":"notarray"}`, protocol.ErrorCodeBadFrame},
+		{"멘션 항목이 객체가 아님", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":[1]}`, protocol.ErrorCodeBadFrame},
+		{"멘션 offset이 정수가 아님", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":[{"offset":1.5,"length":1,"type":"all"}]}`, protocol.ErrorCodeBadFrame},
+		{"멘션 offset이 음수", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":[{"offset":-1,"length":1,"type":"all"}]}`, protocol.ErrorCodeBadFrame},
+		{"멘션 length가 숫자가 아님", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":[{"offset":0,"length":"x","type":"all"}]}`, protocol.ErrorCodeBadFrame},
+		{"멘션이 너무 많음", `{"type":"message","roomId":"` + roomID + `","content":"hi","mentions":[` + strings.Repeat(`{"offset":0,"length":1,"type":"all"},`, 50) + `{"offset":0,"length":1,"type":"all"}]}`, protocol.ErrorCodeBadFrame},
+		{"너무 긴 clientMessageId", `{"type":"message","roomId":"` + roomID + `","content":"hi","clientMessageId":"` + strings.Repeat("x", 129) + `"}`, protocol.ErrorCodeBadFrame},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := protocol.Validate([]byte(tt.frame))
+			if tt.code == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var protocolErr *protocol.Error
+			if assert.True(t, errors.As(err, &protocolErr)) {
+				assert.Equal(t, tt.code, protocolErr.Code)
+				assert.NotEmpty(t, protocolErr.Message)
+			}
+		})
+	}
+}
+
+func TestInvalidFramesGetErrorEvents(t *testing.T) {
+	chatService, _ := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	otherRoomID := uuid.NewString()
+	conn := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer conn.Close()
+
+	assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	event := readFrame(t, conn, "error")
+	assert.Equal(t, protocol.ErrorCodeBadFrame, event["code"])
+
+	// 오류 이벤트에는 원인이 된 프레임의 type, roomId, clientMessageId가 담김
+	assert.NoError(t, conn.WriteJSON(map[string]string{"type": "dance", "roomId": roomID, "clientMessageId": "c-1"}))
+	event = readFrame(t, conn, "error")
+	assert.Equal(t, protocol.ErrorCodeUnknownType, event["code"])
+	assert.Equal(t, "dance", event["frameType"])
+	assert.Equal(t, roomID, event["roomId"])
+	assert.Equal(t, "c-1", event["clientMessageId"])
+
+	assert.NoError(t, conn.WriteJSON(map[string]string{"type": "message", "roomId": roomID, "clientMessageId": "c-2"}))
+	event = readFrame(t, conn, "error")
+	assert.Equal(t, protocol.ErrorCodeBadFrame, event["code"])
+	assert.Equal(t, "c-2", event["clientMessageId"])
+	assert.Contains(t, event["message"], "content")
+
+	assert.NoError(t, conn.WriteJSON(map[string]string{"type": "message", "roomId": otherRoomID, "content": "hi", "clientMessageId": "c-3"}))
+	event = readFrame(t, conn, "error")
+	assert.Equal(t, protocol.ErrorCodeNotSubscribed, event["code"])
+	assert.Equal(t, otherRoomID, event["roomId"])
+	assert.Equal(t, "c-3", event["clientMessageId"])
+
+	// 스키마가 정의하지 않은 필드라도 프레임 구조와 형식이 맞지 않으면 처리하지 않음
+	assert.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "message", "roomId": roomID, "content": "hi", "clientMessageId": "c-4", "forEveryone": "yes"}))
+	event = readFrame(t, conn, "error")
+	assert.Equal(t, protocol.ErrorCodeBadFrame, event["code"])
+	assert.Equal(t, "c-4", event["clientMessageId"])
+}
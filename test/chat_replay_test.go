@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"server/internal/models/message"
+	"server/internal/service"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTextMessage(roomID, userID, content string) *message.TextMessage {
+	msg := &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: userID}},
+		Content:     content,
+	}
+	msg.GenerateID()
+	return msg
+}
+
+// expectReplayWithLiveTraffic은 저장소 조회 도중 새 메시지가 저장되는 상황을 흉내 냅니다.
+// 조회 결과의 마지막 메시지는 조회 중에 실시간으로도 전달되어 경계에서 중복되고,
+// 그 뒤의 메시지는 조회 결과에 없어 실시간 전달로만 받을 수 있습니다.
+func expectReplayWithLiveTraffic(chatService service.ChatService, msgRepo *MessageRepositoryMock, roomID string, lastMessageID uuid.UUID) {
+	authorID := uuid.NewString()
+	first := newTextMessage(roomID, authorID, "missed-1")
+	second := newTextMessage(roomID, authorID, "missed-2")
+	seam := newTextMessage(roomID, authorID, "seam")
+	live := newTextMessage(roomID, authorID, "live")
+
+	msgRepo.On("GetMessagesByUUID", mock.Anything, roomID, lastMessageID, mock.Anything).
+		Run(func(args mock.Arguments) {
+			chatService.SaveMessage(context.Background(), roomID, seam)
+			chatService.SaveMessage(context.Background(), roomID, live)
+		}).
+		Return(message.Page{Messages: []message.Message{first, second, seam}}, nil)
+}
+
+// assertReplayedInOrder는 재전송과 실시간 메시지가 빠짐없이 한 번씩, 순서대로 도착하는지 확인합니다.
+func assertReplayedInOrder(t *testing.T, conn *websocket.Conn) {
+	for _, expected := range []string{"missed-1", "missed-2", "seam", "live"} {
+		assert.Equal(t, expected, readMessageEvent(t, conn)["content"])
+	}
+
+	// 경계에서 중복된 메시지가 다시 오지 않아야 함
+	assert.False(t, readUntil(t, conn, 200*time.Millisecond, func(event map[string]interface{}) bool {
+		return event["type"] == "message"
+	}))
+}
+
+func TestHandshakeReplaysMissedMessagesWithoutGapOrDuplicate(t *testing.T) {
+	chatService, msgRepo := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	lastMessageID, _ := uuid.NewV7()
+	expectReplayWithLiveTraffic(chatService, msgRepo, roomID, lastMessageID)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?roomId="+roomID+"&userId="+uuid.NewString()+"&lastMessageId="+lastMessageID.String(), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	assertReplayedInOrder(t, conn)
+}
+
+func TestSubscribeFrameReplaysMissedMessages(t *testing.T) {
+	chatService, msgRepo := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	lastMessageID, _ := uuid.NewV7()
+	expectReplayWithLiveTraffic(chatService, msgRepo, roomID, lastMessageID)
+
+	conn := dialChat(t, wsURL, "", uuid.NewString())
+	defer conn.Close()
+
+	err := conn.WriteJSON(map[string]string{"type": "subscribe", "roomId": roomID, "lastMessageId": lastMessageID.String()})
+	assert.NoError(t, err)
+
+	assertReplayedInOrder(t, conn)
+}
+
+func TestReplayFailureClosesConnection(t *testing.T) {
+	chatService, msgRepo := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	lastMessageID, _ := uuid.NewV7()
+	msgRepo.On("GetMessagesByUUID", mock.Anything, roomID, lastMessageID, mock.Anything).
+		Return(message.Page{}, errors.New("redis unavailable"))
+
+	conn := dialChat(t, wsURL, "", uuid.NewString())
+	defer conn.Close()
+
+	err := conn.WriteJSON(map[string]string{"type": "subscribe", "roomId": roomID, "lastMessageId": lastMessageID.String()})
+	assert.NoError(t, err)
+
+	assert.Equal(t, websocket.CloseInternalServerErr, readCloseCode(t, conn))
+}
+
+func TestReplayReportsTruncationAfterReplayedMessages(t *testing.T) {
+	chatService, msgRepo := newMemberChatService()
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	lastMessageID, _ := uuid.NewV7()
+	replayed := newTextMessage(roomID, uuid.NewString(), "missed-1")
+	msgRepo.On("GetMessagesByUUID", mock.Anything, roomID, lastMessageID, mock.Anything).
+		Return(message.Page{Messages: []message.Message{replayed}, HasMore: true, NextCursor: replayed.GetID()}, nil)
+
+	conn := dialChat(t, wsURL, "", uuid.NewString())
+	defer conn.Close()
+
+	err := conn.WriteJSON(map[string]string{"type": "subscribe", "roomId": roomID, "lastMessageId": lastMessageID.String()})
+	assert.NoError(t, err)
+
+	// 재전송한 메시지 뒤에 이어서 조회할 커서를 알려 줌
+	assert.Equal(t, "missed-1", readMessageEvent(t, conn)["content"])
+	truncated := readFrame(t, conn, "replayTruncated")
+	assert.Equal(t, roomID, truncated["roomId"])
+	assert.Equal(t, replayed.GetID().String(), truncated["nextCursor"])
+}
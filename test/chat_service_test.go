@@ -2,10 +2,16 @@ package test
 
 import (
 	"context"
+	"server/internal/broadcast"
 	"server/internal/models/message"
 	"server/internal/models/orm"
+	"server/internal/models/presence"
+	"server/internal/models/ratelimit"
+	"server/internal/repository"
 	"server/internal/service"
+	"server/pkg/clock"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -22,14 +28,142 @@ func (m *MessageRepositoryMock) SaveMessage(ctx context.Context, roomID string,
 	return args.Error(0)
 }
 
-func (m *MessageRepositoryMock) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *MessageRepositoryMock) GetMessages(ctx context.Context, roomID string, lastMessageID int64, limit int) (message.Page, error) {
+	args := m.Called(ctx, roomID, lastMessageID, limit)
+	return args.Get(0).(message.Page), args.Error(1)
 }
 
-func (m *MessageRepositoryMock) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageUUID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *MessageRepositoryMock) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID, limit int) (message.Page, error) {
+	args := m.Called(ctx, roomID, lastMessageUUID, limit)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *MessageRepositoryMock) ListMessages(ctx context.Context, roomID string, query message.PageQuery) (message.Page, error) {
+	args := m.Called(ctx, roomID, query)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *MessageRepositoryMock) GetMessage(ctx context.Context, roomID string, id uuid.UUID) (message.Message, error) {
+	args := m.Called(ctx, roomID, id)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+// GetMessagesByID는 답장이 담긴 기록을 조회할 때마다 호출되므로 기대값 없이 찾지 못한 것으로 응답합니다.
+func (m *MessageRepositoryMock) GetMessagesByID(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.Message, error) {
+	return map[uuid.UUID]message.Message{}, nil
+}
+
+func (m *MessageRepositoryMock) EditMessage(ctx context.Context, roomID string, edited message.Message, previous message.Revision) error {
+	args := m.Called(ctx, roomID, edited, previous)
+	return args.Error(0)
+}
+
+func (m *MessageRepositoryMock) GetRevisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error) {
+	args := m.Called(ctx, roomID, id)
+	return args.Get(0).([]message.Revision), args.Error(1)
+}
+
+func (m *MessageRepositoryMock) DeleteMessage(ctx context.Context, roomID string, tombstone *message.DeletedMessage) error {
+	args := m.Called(ctx, roomID, tombstone)
+	return args.Error(0)
+}
+
+func (m *MessageRepositoryMock) HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, id)
+	return args.Error(0)
+}
+
+// HiddenMessages는 기록을 조회할 때마다 호출되므로 기대값 없이 숨긴 메시지가 없는 것으로 응답합니다.
+func (m *MessageRepositoryMock) HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return map[uuid.UUID]bool{}, nil
+}
+
+func (m *MessageRepositoryMock) AddReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string, limit int) ([]message.Reaction, bool, error) {
+	args := m.Called(ctx, roomID, id, userID, emoji, limit)
+	return args.Get(0).([]message.Reaction), args.Bool(1), args.Error(2)
+}
+
+func (m *MessageRepositoryMock) RemoveReaction(ctx context.Context, roomID string, id uuid.UUID, userID, emoji string) ([]message.Reaction, bool, error) {
+	args := m.Called(ctx, roomID, id, userID, emoji)
+	return args.Get(0).([]message.Reaction), args.Bool(1), args.Error(2)
+}
+
+// GetReactions도 기록을 조회할 때마다 호출되므로 기대값 없이 반응이 없는 것으로 응답합니다.
+func (m *MessageRepositoryMock) GetReactions(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID][]message.Reaction, error) {
+	return map[uuid.UUID][]message.Reaction{}, nil
+}
+
+func (m *MessageRepositoryMock) GetThread(ctx context.Context, roomID string, rootID uuid.UUID, query message.PageQuery) (message.Page, error) {
+	args := m.Called(ctx, roomID, rootID, query)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+// GetThreadSummaries도 기록을 조회할 때마다 호출되므로 기대값 없이 스레드가 없는 것으로 응답합니다.
+func (m *MessageRepositoryMock) GetThreadSummaries(ctx context.Context, roomID string, ids []uuid.UUID) (map[uuid.UUID]message.ThreadSummary, error) {
+	return map[uuid.UUID]message.ThreadSummary{}, nil
+}
+
+func (m *MessageRepositoryMock) JoinThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	args := m.Called(ctx, roomID, rootID, userID)
+	return args.Error(0)
+}
+
+func (m *MessageRepositoryMock) LeaveThread(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	args := m.Called(ctx, roomID, rootID, userID)
+	return args.Error(0)
+}
+
+// ThreadParticipants는 메시지를 바꿀 때마다 호출되므로 기대값 없이 참여자가 없는 것으로 응답합니다.
+func (m *MessageRepositoryMock) ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error) {
+	return nil, nil
+}
+
+// MessageDedupRepositoryMock은 MessageDedupRepository 인터페이스를 구현하는 모의 객체입니다.
+type MessageDedupRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *MessageDedupRepositoryMock) Claim(ctx context.Context, userID, clientMessageID string, receipt message.Receipt, ttl time.Duration) (message.Receipt, bool, error) {
+	args := m.Called(ctx, userID, clientMessageID, receipt, ttl)
+	return args.Get(0).(message.Receipt), args.Bool(1), args.Error(2)
+}
+
+func (m *MessageDedupRepositoryMock) Commit(ctx context.Context, userID, clientMessageID string, ttl time.Duration) error {
+	args := m.Called(ctx, userID, clientMessageID, ttl)
+	return args.Error(0)
+}
+
+func (m *MessageDedupRepositoryMock) Release(ctx context.Context, userID, clientMessageID string) error {
+	args := m.Called(ctx, userID, clientMessageID)
+	return args.Error(0)
+}
+
+// MentionRepositoryMock은 MentionRepository 인터페이스를 구현하는 모의 객체입니다.
+type MentionRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *MentionRepositoryMock) AddMention(ctx context.Context, roomID string, messageID uuid.UUID, userIDs []string) error {
+	args := m.Called(ctx, roomID, messageID, userIDs)
+	return args.Error(0)
+}
+
+func (m *MentionRepositoryMock) ListMentions(ctx context.Context, userID string, before uuid.UUID, limit int) ([]message.MentionRef, error) {
+	args := m.Called(ctx, userID, before, limit)
+	refs, _ := args.Get(0).([]message.MentionRef)
+	return refs, args.Error(1)
+}
+
+func (m *MentionRepositoryMock) UnreadCounts(ctx context.Context, userID string) (map[string]int, error) {
+	args := m.Called(ctx, userID)
+	counts, _ := args.Get(0).(map[string]int)
+	return counts, args.Error(1)
+}
+
+func (m *MentionRepositoryMock) ClearUnread(ctx context.Context, userID, roomID string) error {
+	args := m.Called(ctx, userID, roomID)
+	return args.Error(0)
 }
 
 // RoomRepositoryMock은 RoomRepository 인터페이스를 구현하는 모의 객체입니다.
@@ -67,12 +201,187 @@ func (m *RoomRepositoryMock) CreateRoomWithUsers(ctx context.Context, roomName s
 	return args.Get(0).(uuid.UUID), args.Error(1)
 }
 
+func (m *RoomRepositoryMock) IsUserInRoom(ctx context.Context, roomID, userID uuid.UUID) (bool, error) {
+	args := m.Called(ctx, roomID, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *RoomRepositoryMock) GetRoomUserIDs(ctx context.Context, roomID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, roomID)
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *RoomRepositoryMock) GetRoommateIDs(ctx context.Context, userID uuid.UUID, userIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, userIDs)
+	roommateIDs, _ := args.Get(0).([]uuid.UUID)
+	return roommateIDs, args.Error(1)
+}
+
+// AdvanceReadCursor는 메시지를 보낸 작성자의 커서를 주기적으로 옮기려고 호출되므로 기대값 없이 커서를 옮기지 않은 것으로 응답합니다.
+func (m *RoomRepositoryMock) AdvanceReadCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+// AdvanceDeliveryCursor는 메시지를 소켓에 쓸 때마다 호출되므로 기대값 없이 커서를 옮기지 않은 것으로 응답합니다.
+func (m *RoomRepositoryMock) AdvanceDeliveryCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+// GetReadCursors도 기록을 조회할 때마다 호출되므로 기대값 없이 읽은 멤버가 없는 것으로 응답합니다.
+func (m *RoomRepositoryMock) GetReadCursors(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	return map[uuid.UUID]uuid.UUID{}, nil
+}
+
+func (m *RoomRepositoryMock) GetUserRoomMemberships(ctx context.Context, userID uuid.UUID) ([]orm.UserRoom, error) {
+	args := m.Called(ctx, userID)
+	rooms, _ := args.Get(0).([]orm.UserRoom)
+	return rooms, args.Error(1)
+}
+
+func (m *RoomRepositoryMock) GetUserRoomMembershipsByID(ctx context.Context, userID uuid.UUID, roomIDs []uuid.UUID) ([]orm.UserRoom, error) {
+	args := m.Called(ctx, userID, roomIDs)
+	rooms, _ := args.Get(0).([]orm.UserRoom)
+	return rooms, args.Error(1)
+}
+
+// RoomSummaryRepositoryMock은 RoomSummaryRepository 인터페이스를 구현하는 모의 객체입니다.
+type RoomSummaryRepositoryMock struct {
+	mock.Mock
+}
+
+// RecordMessage는 메시지를 저장할 때마다 호출되므로 기대값 없이 성공으로 응답합니다.
+func (m *RoomSummaryRepositoryMock) RecordMessage(ctx context.Context, roomID string, msg message.Message) error {
+	return nil
+}
+
+// RefreshLastMessage도 메시지를 수정하거나 삭제할 때마다 호출되므로 기대값 없이 성공으로 응답합니다.
+func (m *RoomSummaryRepositoryMock) RefreshLastMessage(ctx context.Context, roomID string, msg message.Message) error {
+	return nil
+}
+
+// RemoveMessage와 HideMessage도 메시지를 삭제하거나 숨길 때마다 호출되므로 기대값 없이 성공으로 응답합니다.
+func (m *RoomSummaryRepositoryMock) RemoveMessage(ctx context.Context, roomID string, id uuid.UUID) error {
+	return nil
+}
+
+func (m *RoomSummaryRepositoryMock) HideMessage(ctx context.Context, roomID, userID string, id uuid.UUID) error {
+	return nil
+}
+
+func (m *RoomSummaryRepositoryMock) GetSummaries(ctx context.Context, userID string, readCursors map[string]uuid.UUID) (map[string]message.RoomSummary, error) {
+	args := m.Called(ctx, userID, readCursors)
+	summaries, _ := args.Get(0).(map[string]message.RoomSummary)
+	return summaries, args.Error(1)
+}
+
+func (m *RoomSummaryRepositoryMock) BuildRoomList(ctx context.Context, userID string, rooms map[string]time.Time) error {
+	args := m.Called(ctx, userID, rooms)
+	return args.Error(0)
+}
+
+// AddMember와 RemoveMember는 멤버십이 바뀔 때마다 호출되므로 기대값 없이 성공으로 응답합니다.
+func (m *RoomSummaryRepositoryMock) AddMember(ctx context.Context, roomID, userID string, createdAt time.Time) error {
+	return nil
+}
+
+func (m *RoomSummaryRepositoryMock) RemoveMember(ctx context.Context, roomID, userID string) error {
+	return nil
+}
+
+func (m *RoomSummaryRepositoryMock) ListRooms(ctx context.Context, userID string, after *message.RoomActivity, limit int) ([]message.RoomActivity, error) {
+	args := m.Called(ctx, userID, after, limit)
+	rooms, _ := args.Get(0).([]message.RoomActivity)
+	return rooms, args.Error(1)
+}
+
+// PresenceRepositoryMock은 PresenceRepository 인터페이스를 구현하는 모의 객체입니다.
+type PresenceRepositoryMock struct {
+	mock.Mock
+}
+
+// Heartbeat는 연결할 때마다 호출되므로 기대값 없이 상태가 바뀌지 않은 것으로 응답합니다.
+func (m *PresenceRepositoryMock) Heartbeat(ctx context.Context, userID, sessionID string, status presence.Status, now time.Time, ttl time.Duration) (presence.Status, presence.Status, error) {
+	return presence.StatusOnline, presence.StatusOnline, nil
+}
+
+// RemoveSession도 연결이 끊길 때마다 호출되므로 기대값 없이 상태가 바뀌지 않은 것으로 응답합니다.
+func (m *PresenceRepositoryMock) RemoveSession(ctx context.Context, userID, sessionID string, now time.Time) (presence.Status, presence.Status, error) {
+	return presence.StatusOffline, presence.StatusOffline, nil
+}
+
+func (m *PresenceRepositoryMock) GetPresence(ctx context.Context, userIDs []string, now time.Time) (map[string]presence.Record, error) {
+	args := m.Called(ctx, userIDs, now)
+	records, _ := args.Get(0).(map[string]presence.Record)
+	return records, args.Error(1)
+}
+
+func (m *PresenceRepositoryMock) SetHideLastSeen(ctx context.Context, userID string, hide bool) error {
+	args := m.Called(ctx, userID, hide)
+	return args.Error(0)
+}
+
+// TypingRepositoryMock은 TypingRepository 인터페이스를 구현하는 모의 객체입니다.
+// 메시지를 보내거나 연결이 끊길 때도 호출될 수 있으므로 기대값 없이 상태가 바뀌지 않은 것으로 응답합니다.
+type TypingRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *TypingRepositoryMock) StartTyping(ctx context.Context, roomID, userID string, now, expiresAt time.Time) ([]string, bool, error) {
+	return []string{}, false, nil
+}
+
+func (m *TypingRepositoryMock) StopTyping(ctx context.Context, roomID, userID string, now time.Time) ([]string, bool, error) {
+	return []string{}, false, nil
+}
+
+func (m *TypingRepositoryMock) ExpireTyping(ctx context.Context, roomID, userID string, now time.Time) ([]string, bool, error) {
+	return []string{}, false, nil
+}
+
+// RateLimitRepositoryMock은 RateLimitRepository 인터페이스를 구현하는 모의 객체입니다.
+type RateLimitRepositoryMock struct {
+	mock.Mock
+}
+
+// TakeMessageToken은 메시지를 보낼 때마다 호출되므로 기대값 없이 허용합니다.
+func (m *RateLimitRepositoryMock) TakeMessageToken(ctx context.Context, userID, roomID string, userLimit, roomLimit ratelimit.Limit, now time.Time) (ratelimit.Result, error) {
+	return ratelimit.Result{Allowed: true}, nil
+}
+
+func (m *RateLimitRepositoryMock) RecordViolation(ctx context.Context, userID string, now time.Time, window time.Duration, strikes int, banFor time.Duration) (bool, error) {
+	args := m.Called(ctx, userID, now, window, strikes, banFor)
+	return args.Bool(0), args.Error(1)
+}
+
+// BannedUntil은 연결할 때마다 호출되므로 기대값 없이 차단되지 않은 것으로 응답합니다.
+func (m *RateLimitRepositoryMock) BannedUntil(ctx context.Context, userID string, now time.Time) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// chatDeps는 메시지와 채팅방 저장소 외의 의존성을 모의 객체로 채웁니다.
+// 실제 저장소가 필요한 테스트는 반환된 값의 필드를 바꿔 사용합니다.
+func chatDeps(msgRepo repository.MessageRepository, roomRepo repository.RoomRepository) service.ChatDeps {
+	return service.ChatDeps{
+		MessageRepo:  msgRepo,
+		DedupRepo:    new(MessageDedupRepositoryMock),
+		MentionRepo:  new(MentionRepositoryMock),
+		SummaryRepo:  new(RoomSummaryRepositoryMock),
+		PresenceRepo: new(PresenceRepositoryMock),
+		TypingRepo:   new(TypingRepositoryMock),
+		LimitRepo:    new(RateLimitRepositoryMock),
+		RoomRepo:     roomRepo,
+		FriendRepo:   new(FriendRepositoryMock),
+		Bus:          broadcast.NewLocalBus(),
+	}
+}
+
 func TestSaveMessage(t *testing.T) {
 	// 모의 리포지토리 생성
 	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
 
 	// 서비스 생성
-	chatService := service.NewChatService(msgRepo)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
 
 	// 테스트 데이터
 	roomID := "room-123"
@@ -103,12 +412,15 @@ func TestSaveMessage(t *testing.T) {
 func TestGetMessagesWithID(t *testing.T) {
 	// 모의 리포지토리 생성
 	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
 
 	// 서비스 생성
-	chatService := service.NewChatService(msgRepo)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
 
 	// 테스트 데이터
-	roomID := "room-123"
+	roomUUID := uuid.New()
+	userUUID := uuid.New()
+	roomID := roomUUID.String()
 	lastMsgID := int64(100)
 
 	msg1 := &message.BaseMessage{
@@ -126,14 +438,15 @@ func TestGetMessagesWithID(t *testing.T) {
 	messages := []message.Message{msg1, msg2}
 
 	// 모의 리포지토리 동작 설정
-	msgRepo.On("GetMessages", mock.Anything, roomID, lastMsgID).Return(messages, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(true, nil)
+	msgRepo.On("GetMessages", mock.Anything, roomID, lastMsgID, mock.Anything).Return(message.Page{Messages: messages}, nil)
 
 	// 테스트 실행
-	result, err := chatService.GetMessages(context.Background(), roomID, lastMsgID)
+	result, err := chatService.GetMessages(context.Background(), roomID, userUUID.String(), lastMsgID)
 
 	// 검증
 	assert.NoError(t, err)
-	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 2, len(result.Messages))
 
 	// 모의 리포지토리 호출 검증
 	msgRepo.AssertExpectations(t)
@@ -142,12 +455,15 @@ func TestGetMessagesWithID(t *testing.T) {
 func TestGetMessagesWithUUID(t *testing.T) {
 	// 모의 리포지토리 생성
 	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
 
 	// 서비스 생성
-	chatService := service.NewChatService(msgRepo)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
 
 	// 테스트 데이터
-	roomID := "room-123"
+	roomUUID := uuid.New()
+	userUUID := uuid.New()
+	roomID := roomUUID.String()
 	lastMsgUUID := uuid.New()
 
 	msg1 := &message.BaseMessage{
@@ -165,15 +481,156 @@ func TestGetMessagesWithUUID(t *testing.T) {
 	messages := []message.Message{msg1, msg2}
 
 	// 모의 리포지토리 동작 설정
-	msgRepo.On("GetMessagesByUUID", mock.Anything, roomID, lastMsgUUID).Return(messages, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(true, nil)
+	msgRepo.On("GetMessagesByUUID", mock.Anything, roomID, lastMsgUUID, mock.Anything).Return(message.Page{Messages: messages}, nil)
 
 	// 테스트 실행
-	result, err := chatService.GetMessagesByUUID(context.Background(), roomID, lastMsgUUID)
+	result, err := chatService.GetMessagesByUUID(context.Background(), roomID, userUUID.String(), lastMsgUUID)
 
 	// 검증
 	assert.NoError(t, err)
-	assert.Equal(t, 2, len(result))
+	assert.Equal(t, 2, len(result.Messages))
 
 	// 모의 리포지토리 호출 검증
 	msgRepo.AssertExpectations(t)
 }
+
+func TestGetMessagesRejectsNonMember(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
+
+	roomUUID := uuid.New()
+	userUUID := uuid.New()
+
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(false, nil)
+
+	_, err := chatService.GetMessages(context.Background(), roomUUID.String(), userUUID.String(), 0)
+
+	var notMemberErr *service.NotRoomMemberError
+	assert.ErrorAs(t, err, &notMemberErr)
+	msgRepo.AssertNotCalled(t, "GetMessages", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMembershipIsCached(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
+
+	roomUUID := uuid.New()
+	userUUID := uuid.New()
+	roomID := roomUUID.String()
+
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(true, nil).Once()
+	msgRepo.On("GetMessages", mock.Anything, roomID, int64(0), mock.Anything).Return(message.Page{}, nil)
+
+	for i := 0; i < 3; i++ {
+		_, err := chatService.GetMessages(context.Background(), roomID, userUUID.String(), 0)
+		assert.NoError(t, err)
+	}
+
+	// 멤버십 조회는 캐시되어 한 번만 일어나야 함
+	roomRepo.AssertNumberOfCalls(t, "IsUserInRoom", 1)
+}
+
+func TestMembershipCacheExpiresWithConfigClock(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	config := service.DefaultChatConfig()
+	fakeClock := clock.NewFake(time.Now())
+	config.Clock = fakeClock
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), config)
+
+	roomUUID := uuid.New()
+	userUUID := uuid.New()
+	roomID := roomUUID.String()
+
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(true, nil)
+	msgRepo.On("GetMessages", mock.Anything, roomID, int64(0), mock.Anything).Return(message.Page{}, nil)
+
+	_, err := chatService.GetMessages(context.Background(), roomID, userUUID.String(), 0)
+	assert.NoError(t, err)
+	fakeClock.Advance(29 * time.Second)
+	_, err = chatService.GetMessages(context.Background(), roomID, userUUID.String(), 0)
+	assert.NoError(t, err)
+	roomRepo.AssertNumberOfCalls(t, "IsUserInRoom", 1)
+
+	// 캐시 만료는 설정의 시계를 따름
+	fakeClock.Advance(2 * time.Second)
+	_, err = chatService.GetMessages(context.Background(), roomID, userUUID.String(), 0)
+	assert.NoError(t, err)
+	roomRepo.AssertNumberOfCalls(t, "IsUserInRoom", 2)
+}
+
+func TestRemovedMemberLosesAccessBeforeCacheExpires(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	deps := chatDeps(msgRepo, roomRepo)
+	chatService := service.NewChatService(deps, service.DefaultChatConfig())
+	roomService := service.NewRoomService(roomRepo, new(RoomSummaryRepositoryMock), deps.Bus)
+	searchRepo := new(MessageSearchRepositoryMock)
+	searchService := service.NewMessageSearchService(searchRepo, msgRepo, roomRepo, deps.Bus, clock.New())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomUUID, userUUID, otherUUID := uuid.New(), uuid.New(), uuid.New()
+	roomID := roomUUID.String()
+
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, otherUUID).Return(true, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(true, nil).Twice()
+	roomRepo.On("IsUserInRoom", mock.Anything, roomUUID, userUUID).Return(false, nil)
+	roomRepo.On("FindByID", mock.Anything, roomUUID).Return(orm.Room{}, nil)
+	roomRepo.On("RemoveUserFromRoom", mock.Anything, roomUUID, userUUID).Return(nil)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	searchRepo.On("SearchMessages", mock.Anything, userUUID, mock.Anything).Return(message.SearchPage{}, nil)
+
+	// 채팅 연결과 검색 서비스가 각각 멤버십을 캐시함
+	removed := dialChat(t, wsURL, roomID, userUUID.String())
+	defer removed.Close()
+	_, err := searchService.SearchMessages(context.Background(), userUUID.String(), message.SearchQuery{Text: "hi", RoomID: roomID})
+	assert.NoError(t, err)
+
+	other := dialChat(t, wsURL, roomID, otherUUID.String())
+	defer other.Close()
+	readFrame(t, removed, "userJoined")
+
+	assert.NoError(t, roomService.RemoveUserFromRoom(context.Background(), roomUUID, userUUID))
+
+	// 제거 이벤트로 캐시가 지워지므로 바로 다시 조회해 거부됨
+	_, err = chatService.GetMessages(context.Background(), roomID, userUUID.String(), 0)
+	var notMemberErr *service.NotRoomMemberError
+	assert.ErrorAs(t, err, &notMemberErr)
+	_, err = searchService.SearchMessages(context.Background(), userUUID.String(), message.SearchQuery{Text: "hi", RoomID: roomID})
+	assert.ErrorAs(t, err, &notMemberErr)
+
+	// 제거된 사용자의 세션은 방 구독에서 빠져 새 메시지를 받지 않음
+	assert.Equal(t, userUUID.String(), readFrame(t, other, "userLeft")["userId"])
+	id, _ := uuid.NewV7()
+	assert.NoError(t, chatService.SaveMessage(context.Background(), roomID, &message.TextMessage{
+		BaseMessage: message.BaseMessage{Id: id, RoomId: roomID, Type: "message", Author: message.User{Id: otherUUID.String()}},
+		Content:     "after removal",
+	}))
+	readFrame(t, other, "message")
+	assert.False(t, readUntil(t, removed, 300*time.Millisecond, func(event map[string]interface{}) bool {
+		return event["type"] == "message"
+	}))
+}
+
+func TestListMessagesClampsLimit(t *testing.T) {
+	msgRepo := new(MessageRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	chatService := service.NewChatService(chatDeps(msgRepo, roomRepo), service.DefaultChatConfig())
+
+	roomID := uuid.New()
+	userID := uuid.New()
+	roomRepo.On("IsUserInRoom", mock.Anything, roomID, userID).Return(true, nil)
+	msgRepo.On("ListMessages", mock.Anything, roomID.String(), message.PageQuery{Limit: 50}).Return(message.Page{}, nil)
+	msgRepo.On("ListMessages", mock.Anything, roomID.String(), message.PageQuery{Limit: 100}).Return(message.Page{}, nil)
+
+	_, err := chatService.ListMessages(context.Background(), roomID.String(), userID.String(), message.PageQuery{})
+	assert.NoError(t, err)
+	_, err = chatService.ListMessages(context.Background(), roomID.String(), userID.String(), message.PageQuery{Limit: 1000})
+	assert.NoError(t, err)
+
+	msgRepo.AssertExpectations(t)
+}
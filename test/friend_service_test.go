@@ -51,6 +51,18 @@ func (m *FriendRepositoryMock) CheckIfFriendExists(ctx context.Context, userID,
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *FriendRepositoryMock) GetAddedByUserIDs(ctx context.Context, friendID uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, friendID)
+	userIDs, _ := args.Get(0).([]uuid.UUID)
+	return userIDs, args.Error(1)
+}
+
+func (m *FriendRepositoryMock) GetAddedFriendIDs(ctx context.Context, userID uuid.UUID, friendIDs []uuid.UUID) ([]uuid.UUID, error) {
+	args := m.Called(ctx, userID, friendIDs)
+	addedIDs, _ := args.Get(0).([]uuid.UUID)
+	return addedIDs, args.Error(1)
+}
+
 // UserRepositoryMock은 UserRepository 인터페이스를 구현하는 모의 객체입니다.
 type UserRepositoryMock struct {
 	mock.Mock
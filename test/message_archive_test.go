@@ -0,0 +1,556 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryMessageArchive는 Postgres 보관소 대신 사용하는 메모리 구현입니다.
+type memoryMessageArchive struct {
+	mutex        sync.Mutex
+	messages     map[string][]repository.StreamMessage
+	checkpoints  map[string]repository.StreamPosition
+	revisions    map[uuid.UUID][]message.Revision
+	hidden       map[string]map[uuid.UUID]bool
+	participants map[uuid.UUID]map[string]bool
+	fail         bool
+	// 보관할 때마다 호출됨. 보관 도중에 일어나는 일을 흉내 낼 때 사용
+	onArchive func()
+}
+
+func newMemoryMessageArchive() *memoryMessageArchive {
+	return &memoryMessageArchive{
+		messages:     make(map[string][]repository.StreamMessage),
+		checkpoints:  make(map[string]repository.StreamPosition),
+		revisions:    make(map[uuid.UUID][]message.Revision),
+		hidden:       make(map[string]map[uuid.UUID]bool),
+		participants: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (a *memoryMessageArchive) Archive(ctx context.Context, roomID string, messages []repository.StreamMessage, checkpoint repository.StreamPosition) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if a.fail {
+		return errors.New("archive unavailable")
+	}
+	if a.onArchive != nil {
+		a.onArchive()
+	}
+
+	stored := a.messages[roomID]
+	for _, entry := range messages {
+		i := sort.Search(len(stored), func(i int) bool { return !stored[i].Position.Less(entry.Position) })
+		if i < len(stored) && stored[i].Position == entry.Position {
+			continue
+		}
+		stored = append(stored, repository.StreamMessage{})
+		copy(stored[i+1:], stored[i:])
+		stored[i] = entry
+	}
+	a.messages[roomID] = stored
+	a.checkpoints[roomID] = checkpoint
+	return nil
+}
+
+func (a *memoryMessageArchive) Checkpoint(ctx context.Context, roomID string) (repository.StreamPosition, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.checkpoints[roomID], nil
+}
+
+func (a *memoryMessageArchive) Position(ctx context.Context, roomID string, id uuid.UUID) (repository.StreamPosition, bool, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, entry := range a.messages[roomID] {
+		if entry.Message.GetID() == id {
+			return entry.Position, true, nil
+		}
+	}
+	return repository.StreamPosition{}, false, nil
+}
+
+func (a *memoryMessageArchive) Find(ctx context.Context, roomID string, ids []uuid.UUID) ([]repository.StreamMessage, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	wanted := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+	var result []repository.StreamMessage
+	for _, entry := range a.messages[roomID] {
+		if wanted[entry.Message.GetID()] {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (a *memoryMessageArchive) ListBefore(ctx context.Context, roomID string, before repository.StreamPosition, limit int) ([]repository.StreamMessage, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var result []repository.StreamMessage
+	stored := a.messages[roomID]
+	for i := len(stored) - 1; i >= 0 && len(result) < limit; i-- {
+		if stored[i].Position.Less(before) {
+			result = append(result, stored[i])
+		}
+	}
+	return result, nil
+}
+
+func (a *memoryMessageArchive) ListAfter(ctx context.Context, roomID string, after repository.StreamPosition, limit int) ([]repository.StreamMessage, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var result []repository.StreamMessage
+	for _, entry := range a.messages[roomID] {
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+		if after.Less(entry.Position) {
+			result = append(result, entry)
+		}
+	}
+	return result, nil
+}
+
+func (a *memoryMessageArchive) UpdateMessage(ctx context.Context, roomID string, msg message.Message) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for i, entry := range a.messages[roomID] {
+		if entry.Message.GetID() != msg.GetID() {
+			continue
+		}
+		if entry.Message.GetType() == "deleted" {
+			return repository.ErrMessageNotFound
+		}
+		a.messages[roomID][i].Message = msg
+		if msg.GetType() == "deleted" {
+			delete(a.revisions, msg.GetID())
+		}
+	}
+	return nil
+}
+
+func (a *memoryMessageArchive) AppendRevisions(ctx context.Context, roomID string, id uuid.UUID, revisions []message.Revision) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	existing := a.revisions[id]
+	if len(existing) >= len(revisions) {
+		tail := existing[len(existing)-len(revisions):]
+		if reflect.DeepEqual(tail, revisions) {
+			return nil
+		}
+	}
+	a.revisions[id] = append(existing, revisions...)
+	return nil
+}
+
+func (a *memoryMessageArchive) Revisions(ctx context.Context, roomID string, id uuid.UUID) ([]message.Revision, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]message.Revision(nil), a.revisions[id]...), nil
+}
+
+func (a *memoryMessageArchive) HideMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.hidden[userID] == nil {
+		a.hidden[userID] = make(map[uuid.UUID]bool)
+	}
+	for _, id := range ids {
+		a.hidden[userID][id] = true
+	}
+	return nil
+}
+
+func (a *memoryMessageArchive) HiddenMessages(ctx context.Context, roomID, userID string, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	hidden := make(map[uuid.UUID]bool)
+	for _, id := range ids {
+		if a.hidden[userID][id] {
+			hidden[id] = true
+		}
+	}
+	return hidden, nil
+}
+
+// threadReplies는 before보다 앞선 위치에 보관된 rootID 스레드의 답장을 ID 순서로 반환합니다.
+func (a *memoryMessageArchive) threadReplies(roomID string, rootID uuid.UUID, before repository.StreamPosition) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, entry := range a.messages[roomID] {
+		if threadID := entry.Message.Base().ThreadId; threadID != nil && *threadID == rootID && entry.Position.Less(before) {
+			ids = append(ids, entry.Message.GetID())
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
+func (a *memoryMessageArchive) ThreadReplies(ctx context.Context, roomID string, rootID uuid.UUID, before repository.StreamPosition, query message.PageQuery) ([]uuid.UUID, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	all := a.threadReplies(roomID, rootID, before)
+	var ids []uuid.UUID
+	if query.After != uuid.Nil {
+		for _, id := range all {
+			if id.String() > query.After.String() && len(ids) < query.Limit {
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+	for i := len(all) - 1; i >= 0 && len(ids) < query.Limit; i-- {
+		if query.Before == uuid.Nil || all[i].String() < query.Before.String() {
+			ids = append(ids, all[i])
+		}
+	}
+	return ids, nil
+}
+
+func (a *memoryMessageArchive) ThreadCounts(ctx context.Context, roomID string, rootIDs []uuid.UUID, before repository.StreamPosition) (map[uuid.UUID]repository.ThreadCount, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	counts := make(map[uuid.UUID]repository.ThreadCount)
+	for _, rootID := range rootIDs {
+		if ids := a.threadReplies(roomID, rootID, before); len(ids) > 0 {
+			counts[rootID] = repository.ThreadCount{Count: len(ids), LastReply: ids[len(ids)-1]}
+		}
+	}
+	return counts, nil
+}
+
+func (a *memoryMessageArchive) AddThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID, userIDs []string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.participants[rootID] == nil {
+		a.participants[rootID] = make(map[string]bool)
+	}
+	for _, userID := range userIDs {
+		a.participants[rootID][userID] = true
+	}
+	return nil
+}
+
+func (a *memoryMessageArchive) RemoveThreadParticipant(ctx context.Context, roomID string, rootID uuid.UUID, userID string) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	delete(a.participants[rootID], userID)
+	return nil
+}
+
+func (a *memoryMessageArchive) ThreadParticipants(ctx context.Context, roomID string, rootID uuid.UUID) ([]string, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	var userIDs []string
+	for userID := range a.participants[rootID] {
+		userIDs = append(userIDs, userID)
+	}
+	return userIDs, nil
+}
+
+func (a *memoryMessageArchive) count(roomID string) int {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return len(a.messages[roomID])
+}
+
+// archiveStreamLength는 스트림에 캐시로 남는 항목 수입니다.
+const archiveStreamLength = 5000
+
+// saveNumberedMessages는 "m0"부터 번호를 붙인 메시지를 1밀리초 간격으로 저장하고 ID를 반환합니다.
+func saveNumberedMessages(t *testing.T, repo repository.MessageRepository, roomID string, n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuidAt(int64(1000 + i))
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: ids[i], RoomId: roomID, Type: "message"},
+			Content:     "m" + strconv.Itoa(i),
+		}
+		if !assert.NoError(t, repo.SaveMessage(context.Background(), roomID, msg)) {
+			t.FailNow()
+		}
+	}
+	return ids
+}
+
+func numberedContents(from, to int) []string {
+	contents := make([]string, 0, to-from)
+	for i := from; i < to; i++ {
+		contents = append(contents, "m"+strconv.Itoa(i))
+	}
+	return contents
+}
+
+func TestMessageArchiverTrimsOnlyArchivedEntries(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+	key := "stream:room:" + roomID + ":messages"
+
+	total := archiveStreamLength + 10
+	saveNumberedMessages(t, repo, roomID, total)
+
+	// 저장할 때는 자르지 않음
+	length, _ := client.XLen(ctx, key).Result()
+	assert.Equal(t, int64(total), length)
+
+	// 보관에 실패하면 스트림을 자르지 않고 다음 주기에 다시 시도함
+	archive.fail = true
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	length, _ = client.XLen(ctx, key).Result()
+	assert.Equal(t, int64(total), length)
+	assert.True(t, mr.Exists("stream:rooms:pending-archive"))
+
+	archive.fail = false
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	assert.Equal(t, total, archive.count(roomID))
+	length, _ = client.XLen(ctx, key).Result()
+	assert.Equal(t, int64(archiveStreamLength), length)
+
+	first, _ := client.XRangeN(ctx, key, "-", "+", 1).Result()
+	assert.Equal(t, strconv.Itoa(1000+10)+"-0", first[0].ID)
+
+	// 모두 보관되면 대기 목록에서 빠지고, 새 메시지가 오면 다시 들어감
+	isPending, _ := client.SIsMember(ctx, "stream:rooms:pending-archive", roomID).Result()
+	assert.False(t, isPending)
+
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message"}, Content: "new"}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	isPending, _ = client.SIsMember(ctx, "stream:rooms:pending-archive", roomID).Result()
+	assert.True(t, isPending)
+
+	// 스트림이 maxStreamLength개를 넘어도 보관 전에는 자르지 않음
+	length, _ = client.XLen(ctx, key).Result()
+	assert.Equal(t, int64(archiveStreamLength+1), length)
+}
+
+func TestMessageArchiverKeepsLockTakenByAnotherNode(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+	lockKey := "stream:room:" + roomID + ":messages:archive-lock"
+	saveNumberedMessages(t, repo, roomID, 3)
+
+	// 다른 노드가 잠금을 가지고 있으면 보관하지 않음
+	assert.NoError(t, client.Set(ctx, lockKey, "other", time.Minute).Err())
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	assert.Equal(t, 0, archive.count(roomID))
+	assert.NoError(t, client.Del(ctx, lockKey).Err())
+
+	// 보관하는 동안 잠금이 만료되어 다른 노드가 가져가면 그 잠금은 지우지 않음
+	archive.onArchive = func() {
+		client.Set(ctx, lockKey, "other", time.Minute)
+	}
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	assert.Equal(t, 3, archive.count(roomID))
+	owner, err := client.Get(ctx, lockKey).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, "other", owner)
+
+	// 자신의 잠금은 보관이 끝나면 해제함
+	archive.onArchive = nil
+	assert.NoError(t, client.Del(ctx, lockKey).Err())
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message"}, Content: "new"}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	assert.Equal(t, 4, archive.count(roomID))
+	assert.False(t, mr.Exists(lockKey))
+}
+
+func TestTieredHistoryFallsThroughToArchive(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+
+	total := archiveStreamLength + 10
+	ids := saveNumberedMessages(t, repo, roomID, total)
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+
+	// 전체 기록은 보관소와 스트림을 이어서 빠짐없이 반환함
+	all, err := repo.GetMessagesByUUID(ctx, roomID, uuid.Nil, total)
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(0, total), messageContents(all.Messages))
+	assert.False(t, all.HasMore)
+
+	// 한도까지만 읽고 보관소에서 멈춘 자리를 다음 커서로 줌
+	capped, err := repo.GetMessagesByUUID(ctx, roomID, uuid.Nil, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(0, 5), messageContents(capped.Messages))
+	assert.True(t, capped.HasMore)
+	assert.Equal(t, ids[4], capped.NextCursor)
+
+	// 잘려 나간 메시지를 커서로 아래로 스크롤하면 스트림까지 이어짐
+	page, err := repo.ListMessages(ctx, roomID, message.PageQuery{After: ids[5], Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(6, 16), messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+
+	recent, err := repo.GetMessagesByUUID(ctx, roomID, ids[total-3], total)
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(total-2, total), messageContents(recent.Messages))
+
+	// ID로 한꺼번에 읽으면 잘려 나간 메시지는 보관소에서 찾음
+	byID, err := repo.GetMessagesByID(ctx, roomID, []uuid.UUID{ids[0], ids[total-1]})
+	assert.NoError(t, err)
+	assert.Len(t, byID, 2)
+	assert.Equal(t, numberedContents(0, 1), messageContents([]message.Message{byID[ids[0]]}))
+	assert.Equal(t, numberedContents(total-1, total), messageContents([]message.Message{byID[ids[total-1]]}))
+
+	// 스트림의 첫 항목을 넘어 위로 스크롤하면 보관소에서 이어서 읽음
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: ids[15], Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(5, 15), messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: page.NextCursor, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(0, 5), messageContents(page.Messages))
+	assert.False(t, page.HasMore)
+
+	// 잘려 나간 메시지가 커서여도 보관소에서 위치를 찾음
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: ids[3], Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, numberedContents(0, 3), messageContents(page.Messages))
+}
+
+func TestMessageArchiverMovesStateOfTrimmedMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+	prefix := "stream:room:" + roomID
+
+	// ids[4]는 ids[3]의 답장이고, 마지막 메시지는 그 앞 메시지의 답장임
+	total := archiveStreamLength + 10
+	ids := make([]uuid.UUID, total)
+	for i := range ids {
+		ids[i] = uuidAt(int64(1000 + i))
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: ids[i], RoomId: roomID, Type: "message", Author: message.User{Id: "author"}},
+			Content:     "m" + strconv.Itoa(i),
+		}
+		if i == 4 || i == total-1 {
+			msg.ThreadId = &ids[i-1]
+		}
+		if !assert.NoError(t, repo.SaveMessage(ctx, roomID, msg)) {
+			t.FailNow()
+		}
+	}
+
+	// edit는 읽어 둔 메시지의 본문을 바꿔 저장함
+	edit := func(msg message.Message, content string) error {
+		edited := *msg.(*message.TextMessage)
+		previous := message.Revision{Content: edited.Content}
+		edited.Content = content
+		return repo.EditMessage(ctx, roomID, &edited, previous)
+	}
+	first, _ := repo.GetMessage(ctx, roomID, ids[0])
+	assert.NoError(t, edit(first, "m0 (edited)"))
+	stale, _ := repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, repo.DeleteMessage(ctx, roomID, message.NewTombstone(stale, "2024-01-01T00:01:00Z")))
+	assert.NoError(t, repo.HideMessage(ctx, roomID, "reader", ids[2]))
+	assert.NoError(t, repo.JoinThread(ctx, roomID, ids[3], "follower"))
+
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+
+	// 잘려 나간 메시지의 상태는 Redis에서 지워짐
+	assert.False(t, mr.Exists(prefix+":edits"))
+	assert.False(t, mr.Exists(prefix+":revisions:"+ids[0].String()))
+	assert.False(t, mr.Exists(prefix+":hidden:reader"))
+	assert.False(t, mr.Exists(prefix+":hidden-users"))
+	assert.False(t, mr.Exists(prefix+":thread:"+ids[3].String()))
+	assert.False(t, mr.Exists(prefix+":thread:"+ids[3].String()+":participants"))
+	roots, _ := client.SMembers(ctx, prefix+":threads").Result()
+	assert.Equal(t, []string{ids[total-2].String()}, roots)
+
+	// 상태는 보관소에서 그대로 읽힘
+	msg, err := repo.GetMessage(ctx, roomID, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, "m0 (edited)", msg.(*message.TextMessage).Content)
+	revisions, err := repo.GetRevisions(ctx, roomID, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Revision{{Content: "m0"}}, revisions)
+
+	hidden, err := repo.HiddenMessages(ctx, roomID, "reader", []uuid.UUID{ids[2], ids[3]})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]bool{ids[2]: true}, hidden)
+
+	thread, err := repo.GetThread(ctx, roomID, ids[3], message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m4"}, messageContents(thread.Messages))
+	participants, err := repo.ThreadParticipants(ctx, roomID, ids[3])
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"author", "follower"}, participants)
+
+	// 툼스톤이 정리된 메시지도 수정으로 되살아나지 않음
+	assert.ErrorIs(t, edit(stale, "m1 (resurrected)"), repository.ErrMessageNotFound)
+	msg, err = repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", msg.GetType())
+
+	// 정리한 뒤에 바뀐 상태는 Redis에 쌓이고, 보관소의 상태와 함께 읽힘
+	msg, _ = repo.GetMessage(ctx, roomID, ids[0])
+	assert.NoError(t, edit(msg, "m0 (edited twice)"))
+	revisions, err = repo.GetRevisions(ctx, roomID, ids[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Revision{{Content: "m0"}, {Content: "m0 (edited)"}}, revisions)
+
+	reply := &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: "late"}, ThreadId: &ids[3]},
+		Content:     "late reply",
+	}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, reply))
+	thread, err = repo.GetThread(ctx, roomID, ids[3], message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m4", "late reply"}, messageContents(thread.Messages))
+	summaries, err := repo.GetThreadSummaries(ctx, roomID, []uuid.UUID{ids[3], ids[total-2]})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, summaries[ids[3]].ReplyCount)
+	assert.Equal(t, reply.GetID(), summaries[ids[3]].LastReply.Id)
+	assert.Equal(t, 1, summaries[ids[total-2]].ReplyCount)
+
+	assert.NoError(t, repo.LeaveThread(ctx, roomID, ids[3], "follower"))
+	participants, err = repo.ThreadParticipants(ctx, roomID, ids[3])
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"author", "late"}, participants)
+}
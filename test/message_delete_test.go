@@ -0,0 +1,200 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// deleteForEveryone은 저장소에서 메시지를 툼스톤으로 바꿉니다.
+func deleteForEveryone(t *testing.T, repo repository.MessageRepository, roomID string, id uuid.UUID) {
+	msg, err := repo.GetMessage(context.Background(), roomID, id)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NoError(t, repo.DeleteMessage(context.Background(), roomID, message.NewTombstone(msg, "2024-01-01T00:00:00Z")))
+}
+
+func TestDeletedMessagesLeaveTombstones(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+	key := "stream:room:" + roomID + ":messages"
+
+	ids := saveNumberedMessages(t, repo, roomID, 5)
+
+	// 수정 기록이 있는 메시지와 마지막 메시지를 삭제
+	original, _ := repo.GetMessage(ctx, roomID, ids[1])
+	edited := *original.(*message.TextMessage)
+	edited.Content = "m1 (edited)"
+	assert.NoError(t, repo.EditMessage(ctx, roomID, &edited, message.Revision{Content: "m1"}))
+	deleteForEveryone(t, repo, roomID, ids[1])
+	deleteForEveryone(t, repo, roomID, ids[4])
+
+	// 원래 본문과 수정 전 판은 Redis에 남지 않음
+	entries, _ := client.XRange(ctx, key, "-", "+").Result()
+	assert.Len(t, entries, 3)
+	for _, entry := range entries {
+		assert.NotContains(t, entry.Values["message"], "m1")
+	}
+	revisions, err := repo.GetRevisions(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	assert.Empty(t, revisions)
+
+	// 기록에는 원래 자리에 툼스톤이 남고, 페이지 경계도 툼스톤을 건너뛰지 않음
+	all, err := repo.ListMessages(ctx, roomID, message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, all.Messages, 5)
+	for i, msg := range all.Messages {
+		assert.Equal(t, ids[i], msg.GetID())
+	}
+	assert.IsType(t, &message.DeletedMessage{}, all.Messages[1])
+	assert.IsType(t, &message.DeletedMessage{}, all.Messages[4])
+
+	latest, err := repo.ListMessages(ctx, roomID, message.PageQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ids[3], ids[4]}, []uuid.UUID{latest.Messages[0].GetID(), latest.Messages[1].GetID()})
+
+	older, err := repo.ListMessages(ctx, roomID, message.PageQuery{Before: latest.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{ids[1], ids[2]}, []uuid.UUID{older.Messages[0].GetID(), older.Messages[1].GetID()})
+
+	after, err := repo.ListMessages(ctx, roomID, message.PageQuery{After: ids[1], Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, after.Messages, 3)
+
+	// 마지막 항목이 삭제되어도 다음 메시지는 그 뒤에 저장됨
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{Id: uuidAt(1004), RoomId: roomID, Type: "message"}, Content: "m5"}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	replay, err := repo.GetMessagesByUUID(ctx, roomID, ids[3], 10)
+	assert.NoError(t, err)
+	assert.Len(t, replay.Messages, 2)
+	assert.Equal(t, msg.GetID(), replay.Messages[1].GetID())
+}
+
+func TestDeletedMessagesAreArchivedAsTombstones(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+
+	ids := saveNumberedMessages(t, repo, roomID, 4)
+
+	// 보관된 메시지는 보관소의 본문도 툼스톤으로 바뀜
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	deleteForEveryone(t, repo, roomID, ids[0])
+
+	// 보관 전에 삭제된 메시지는 툼스톤으로 보관됨
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{Id: uuidAt(2000), RoomId: roomID, Type: "message"}, Content: "secret"}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	deleteForEveryone(t, repo, roomID, msg.GetID())
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+
+	archived, err := archive.ListAfter(ctx, roomID, repository.StreamPosition{}, 0)
+	assert.NoError(t, err)
+	assert.Len(t, archived, 5)
+	assert.IsType(t, &message.DeletedMessage{}, archived[0].Message)
+	assert.IsType(t, &message.TextMessage{}, archived[3].Message)
+	assert.IsType(t, &message.DeletedMessage{}, archived[4].Message)
+	assert.Equal(t, msg.GetID(), archived[4].Message.GetID())
+}
+
+func TestDeleteAndHideMessage(t *testing.T) {
+	sentAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFake(sentAt.Add(time.Minute))
+	chatService, msgRepo := newEditChatService(t, fakeClock)
+
+	ctx := context.Background()
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+	otherID := uuid.NewString()
+
+	first := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "first",
+	}, sentAt)
+	second := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "second",
+	}, sentAt.Add(time.Millisecond))
+
+	_, err := chatService.DeleteMessage(ctx, roomID, otherID, first)
+	assert.ErrorIs(t, err, service.ErrNotMessageAuthor)
+
+	tombstone, err := chatService.DeleteMessage(ctx, roomID, authorID, first)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeClock.Now().Format(time.RFC3339), tombstone.(*message.DeletedMessage).DeletedAt)
+
+	// 다른 사용자는 자신에게만 메시지를 숨길 수 있음
+	assert.NoError(t, chatService.HideMessage(ctx, roomID, otherID, second))
+
+	page, err := chatService.ListMessages(ctx, roomID, otherID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.IsType(t, &message.DeletedMessage{}, page.Messages[0])
+
+	page, err = chatService.ListMessages(ctx, roomID, authorID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 2)
+
+	// 삭제된 메시지는 수정할 수 없고, 삭제 가능 기간이 지나면 삭제할 수 없음
+	_, err = chatService.EditMessage(ctx, roomID, authorID, first, "again", nil)
+	assert.ErrorIs(t, err, service.ErrMessageNotEditable)
+
+	fakeClock.Advance(time.Hour)
+	_, err = chatService.DeleteMessage(ctx, roomID, authorID, second)
+	assert.ErrorIs(t, err, service.ErrDeleteWindowExpired)
+}
+
+func TestDeleteFrameBroadcastsMessageDeleted(t *testing.T) {
+	chatService, msgRepo := newEditChatService(t, clock.New())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+	messageID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "hello",
+	}, time.Now())
+
+	author := dialChat(t, wsURL, roomID, authorID)
+	defer author.Close()
+	other := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer other.Close()
+	readFrame(t, author, "userJoined")
+
+	assert.NoError(t, author.WriteJSON(map[string]interface{}{
+		"type":            "delete",
+		"roomId":          roomID,
+		"messageId":       messageID.String(),
+		"clientMessageId": "delete-1",
+		"forEveryone":     true,
+	}))
+
+	ack := readFrame(t, author, "ack")
+	assert.Equal(t, "delete-1", ack["clientMessageId"])
+
+	deleted := readFrame(t, other, "messageDeleted")
+	assert.Equal(t, messageID.String(), deleted["messageId"])
+	assert.Equal(t, ack["timestamp"], deleted["deletedAt"])
+}
@@ -0,0 +1,239 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/handler/chatting"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/authenticator"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedisMessageRepositoryAppliesEdits(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	archive := newMemoryMessageArchive()
+	repo := redisRepo.NewTieredMessageRepository(client, archive)
+	archiver := redisRepo.NewMessageArchiver(client, archive, redisRepo.DefaultArchiveInterval)
+	roomID := uuid.NewString()
+
+	ids := saveNumberedMessages(t, repo, roomID, 3)
+
+	original, err := repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	edited := *original.(*message.TextMessage)
+	edited.Content = "m1 (edited)"
+	edited.EditedAt = "2024-01-01T00:00:00Z"
+	assert.NoError(t, repo.EditMessage(ctx, roomID, &edited, message.Revision{Content: "m1"}))
+
+	// 기록과 단건 조회 모두 최신 판을 반환함
+	page, err := repo.ListMessages(ctx, roomID, message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m0", "m1 (edited)", "m2"}, messageContents(page.Messages))
+	assert.Equal(t, "2024-01-01T00:00:00Z", page.Messages[1].Base().EditedAt)
+
+	msg, err := repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "m1 (edited)", msg.(*message.TextMessage).Content)
+
+	revisions, err := repo.GetRevisions(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Revision{{Content: "m1"}}, revisions)
+
+	// 보관소에는 수정된 본문이 저장됨
+	assert.NoError(t, archiver.ArchiveOnce(ctx))
+	archived, err := archive.ListAfter(ctx, roomID, repository.StreamPosition{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "m1 (edited)", archived[1].Message.(*message.TextMessage).Content)
+
+	_, err = repo.GetMessage(ctx, roomID, uuidAt(5000))
+	assert.ErrorIs(t, err, repository.ErrMessageNotFound)
+
+	// 수정하려고 읽은 뒤 삭제된 메시지는 수정으로 되살아나지 않음
+	stale := *msg.(*message.TextMessage)
+	assert.NoError(t, repo.DeleteMessage(ctx, roomID, message.NewTombstone(msg, "2024-01-01T00:01:00Z")))
+	stale.Content = "m1 (resurrected)"
+	err = repo.EditMessage(ctx, roomID, &stale, message.Revision{Content: "m1 (edited)"})
+	assert.ErrorIs(t, err, repository.ErrMessageNotFound)
+
+	msg, err = repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", msg.GetType())
+	archived, err = archive.ListAfter(ctx, roomID, repository.StreamPosition{}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", archived[1].Message.GetType())
+}
+
+func newEditChatService(t *testing.T, fakeClock clock.Clock) (service.ChatService, repository.MessageRepository) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	config := service.DefaultChatConfig()
+	config.Clock = fakeClock
+
+	msgRepo := redisRepo.NewRedisMessageRepository(client)
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.MentionRepo = redisRepo.NewRedisMentionRepository(client)
+	deps.SummaryRepo = redisRepo.NewRedisRoomSummaryRepository(client)
+	chatService := service.NewChatService(deps, config)
+	return chatService, msgRepo
+}
+
+// saveAuthoredMessage는 sentAt에 보낸 것으로 ID를 만든 메시지를 저장하고 ID를 반환합니다.
+func saveAuthoredMessage(t *testing.T, repo repository.MessageRepository, msg message.Message, sentAt time.Time) uuid.UUID {
+	msg.Base().Id = uuidAt(sentAt.UnixMilli())
+	assert.NoError(t, repo.SaveMessage(context.Background(), msg.Base().RoomId, msg))
+	return msg.GetID()
+}
+
+func TestEditMessageChecksAuthorAndWindow(t *testing.T) {
+	sentAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFake(sentAt.Add(time.Minute))
+	chatService, msgRepo := newEditChatService(t, fakeClock)
+
+	ctx := context.Background()
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+
+	textID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}, Timestamp: sentAt.Format(time.RFC3339)},
+		Content:     "hello",
+	}, sentAt)
+	imageID := saveAuthoredMessage(t, msgRepo, &message.ImageMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "image", Author: message.User{Id: authorID}},
+		ImageURL:    "https://example.com/a.png",
+	}, sentAt.Add(time.Millisecond))
+
+	_, err := chatService.EditMessage(ctx, roomID, uuid.NewString(), textID, "hijacked", nil)
+	assert.ErrorIs(t, err, service.ErrNotMessageAuthor)
+
+	_, err = chatService.EditMessage(ctx, roomID, authorID, imageID, "caption", nil)
+	assert.ErrorIs(t, err, service.ErrMessageNotEditable)
+
+	edited, err := chatService.EditMessage(ctx, roomID, authorID, textID, "hello, world", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, fakeClock.Now().Format(time.RFC3339), edited.Base().EditedAt)
+
+	revisions, err := chatService.GetMessageRevisions(ctx, roomID, authorID, textID)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Revision{
+		{Content: "hello", Timestamp: sentAt.Format(time.RFC3339)},
+		{Content: "hello, world", Timestamp: edited.Base().EditedAt},
+	}, revisions)
+
+	// 수정 가능 기간은 수정 시각이 아니라 보낸 시각부터 계산함
+	fakeClock.Advance(15 * time.Minute)
+	_, err = chatService.EditMessage(ctx, roomID, authorID, textID, "too late", nil)
+	assert.ErrorIs(t, err, service.ErrEditWindowExpired)
+}
+
+func TestEditFrameBroadcastsMessageEdited(t *testing.T) {
+	chatService, msgRepo := newEditChatService(t, clock.New())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+	messageID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "hello",
+	}, time.Now())
+
+	author := dialChat(t, wsURL, roomID, authorID)
+	defer author.Close()
+	other := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer other.Close()
+	readFrame(t, author, "userJoined")
+
+	assert.NoError(t, author.WriteJSON(map[string]string{
+		"type":            "edit",
+		"roomId":          roomID,
+		"messageId":       messageID.String(),
+		"clientMessageId": "edit-1",
+		"content":         "hello, world",
+	}))
+
+	ack := readFrame(t, author, "ack")
+	assert.Equal(t, "edit-1", ack["clientMessageId"])
+	assert.Equal(t, messageID.String(), ack["messageId"])
+
+	edited := readFrame(t, other, "messageEdited")
+	assert.Equal(t, messageID.String(), edited["messageId"])
+	assert.Equal(t, "hello, world", edited["content"])
+	assert.Equal(t, ack["timestamp"], edited["editedAt"])
+
+	// 다른 사용자의 수정 시도는 error 이벤트로 거절됨
+	assert.NoError(t, other.WriteJSON(map[string]string{
+		"type":            "edit",
+		"roomId":          roomID,
+		"messageId":       messageID.String(),
+		"clientMessageId": "edit-2",
+		"content":         "hijacked",
+	}))
+	errorFrame := readFrame(t, other, "error")
+	assert.Equal(t, "not_author", errorFrame["code"])
+	assert.Equal(t, "edit-2", errorFrame["clientMessageId"])
+}
+
+func TestChatHandlerEditMessageStatus(t *testing.T) {
+	userID := uuid.New()
+	messageID := uuid.New()
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"수정 성공", nil, http.StatusOK},
+		{"작성자가 아님", service.ErrNotMessageAuthor, http.StatusForbidden},
+		{"메시지 없음", repository.ErrMessageNotFound, http.StatusNotFound},
+		{"수정 기간 지남", service.ErrEditWindowExpired, http.StatusConflict},
+		{"수정할 수 없는 타입", service.ErrMessageNotEditable, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatService := new(ChatServiceMock)
+			handler := chatting.NewChatHandler(chatService)
+
+			var edited message.Message
+			if tt.err == nil {
+				edited = &message.TextMessage{BaseMessage: message.BaseMessage{Id: messageID}, Content: "hello, world"}
+			}
+			chatService.On("EditMessage", mock.Anything, "room-123", userID.String(), messageID, "hello, world", []message.Mention(nil)).Return(edited, tt.err)
+
+			body := bytes.NewBufferString(`{"roomId": "room-123", "content": "hello, world"}`)
+			req, _ := http.NewRequest("PATCH", "/messages/"+messageID.String(), body)
+			req = mux.SetURLVars(req, map[string]string{"messageId": messageID.String()})
+			req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+
+			rr := httptest.NewRecorder()
+			handler.EditMessage(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+			chatService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedisMentionRepository(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMentionRepository(client)
+	userID := uuid.NewString()
+	roomA, roomB := uuid.NewString(), uuid.NewString()
+
+	ids := make([]uuid.UUID, 3)
+	for i, roomID := range []string{roomA, roomB, roomA} {
+		ids[i] = uuidAt(int64(1000 + i))
+		assert.NoError(t, repo.AddMention(ctx, roomID, ids[i], []string{userID}))
+	}
+
+	// 여러 방의 멘션이 최신 순으로 조회됨
+	latest, err := repo.ListMentions(ctx, userID, uuid.Nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.MentionRef{{RoomId: roomA, MessageId: ids[2]}, {RoomId: roomB, MessageId: ids[1]}}, latest)
+
+	older, err := repo.ListMentions(ctx, userID, ids[1], 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.MentionRef{{RoomId: roomA, MessageId: ids[0]}}, older)
+
+	counts, err := repo.UnreadCounts(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{roomA: 2, roomB: 1}, counts)
+
+	assert.NoError(t, repo.ClearUnread(ctx, userID, roomA))
+	counts, err = repo.UnreadCounts(ctx, userID)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{roomB: 1}, counts)
+}
+
+func newMentionChatService(t *testing.T, roomID string, members []uuid.UUID, outsiderID string) (service.ChatService, repository.MessageRepository) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	roomUUID := uuid.MustParse(roomID)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, uuid.MustParse(outsiderID)).Return(false, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	roomRepo.On("GetRoomUserIDs", mock.Anything, roomUUID).Return(members, nil)
+
+	msgRepo := redisRepo.NewRedisMessageRepository(client)
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.MentionRepo = redisRepo.NewRedisMentionRepository(client)
+	deps.SummaryRepo = redisRepo.NewRedisRoomSummaryRepository(client)
+	chatService := service.NewChatService(deps, service.DefaultChatConfig())
+	return chatService, msgRepo
+}
+
+func sendMention(t *testing.T, conn *websocket.Conn, roomID, clientMessageID, content string, mentions []message.Mention) {
+	err := conn.WriteJSON(map[string]interface{}{
+		"type":            "message",
+		"roomId":          roomID,
+		"clientMessageId": clientMessageID,
+		"content":         content,
+		"mentions":        mentions,
+	})
+	assert.NoError(t, err)
+}
+
+func TestMentionsNotifyMentionedUsersAcrossRooms(t *testing.T) {
+	roomID := uuid.NewString()
+	authorID, mentionedID, otherID, outsiderID := uuid.New(), uuid.New(), uuid.New(), uuid.NewString()
+	chatService, _ := newMentionChatService(t, roomID, []uuid.UUID{authorID, mentionedID, otherID}, outsiderID)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	ctx := context.Background()
+
+	// 멘션된 사용자는 방을 구독하지 않아도 mention 이벤트를 받음
+	mentioned := dialChat(t, wsURL, "", mentionedID.String())
+	defer mentioned.Close()
+	other := dialChat(t, wsURL, "", otherID.String())
+	defer other.Close()
+
+	author := dialChat(t, wsURL, roomID, authorID.String())
+	defer author.Close()
+
+	sendMention(t, author, roomID, "mention-1", "hi @bob", []message.Mention{
+		{Offset: 3, Length: 4, Type: message.MentionTypeUser, UserId: mentionedID.String()},
+	})
+	ack := readFrame(t, author, "ack")
+	first, _ := uuid.Parse(ack["messageId"].(string))
+
+	event := readFrame(t, mentioned, "mention")
+	assert.Equal(t, roomID, event["roomId"])
+	assert.Equal(t, first.String(), event["messageId"])
+	mentions := event["message"].(map[string]interface{})["mentions"].([]interface{})
+	assert.Equal(t, mentionedID.String(), mentions[0].(map[string]interface{})["userId"])
+
+	// @all은 작성자를 뺀 모든 멤버에게 알림
+	sendMention(t, author, roomID, "mention-2", "@all 공지", []message.Mention{
+		{Offset: 0, Length: 4, Type: message.MentionTypeAll},
+	})
+	readFrame(t, author, "ack")
+	readFrame(t, mentioned, "mention")
+	readFrame(t, other, "mention")
+
+	// 멤버가 아닌 사용자나 본문과 맞지 않는 구간은 멘션할 수 없음
+	sendMention(t, author, roomID, "mention-3", "hi @eve", []message.Mention{
+		{Offset: 3, Length: 4, Type: message.MentionTypeUser, UserId: outsiderID},
+	})
+	assert.Equal(t, "invalid_mention", readFrame(t, author, "error")["code"])
+
+	sendMention(t, author, roomID, "mention-4", "hi bob", []message.Mention{
+		{Offset: 3, Length: 3, Type: message.MentionTypeUser, UserId: mentionedID.String()},
+	})
+	assert.Equal(t, "invalid_mention", readFrame(t, author, "error")["code"])
+
+	page, err := chatService.ListMentions(ctx, mentionedID.String(), message.MentionQuery{Limit: 1})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.Equal(t, "@all 공지", page.Messages[0].(*message.TextMessage).Content)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, map[string]int{roomID: 2}, page.UnreadCounts)
+
+	page, err = chatService.ListMentions(ctx, mentionedID.String(), message.MentionQuery{Before: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 1)
+	assert.Equal(t, first, page.Messages[0].GetID())
+	assert.False(t, page.HasMore)
+
+	assert.NoError(t, chatService.ReadMentions(ctx, roomID, mentionedID.String()))
+	page, err = chatService.ListMentions(ctx, mentionedID.String(), message.MentionQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 2)
+	assert.Empty(t, page.UnreadCounts)
+}
+
+func TestEditNotifiesOnlyNewlyMentionedUsers(t *testing.T) {
+	roomID := uuid.NewString()
+	authorID, firstID, secondID := uuid.New(), uuid.New(), uuid.New()
+	chatService, msgRepo := newMentionChatService(t, roomID, []uuid.UUID{authorID, firstID, secondID}, uuid.NewString())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	ctx := context.Background()
+	first := dialChat(t, wsURL, "", firstID.String())
+	defer first.Close()
+	second := dialChat(t, wsURL, "", secondID.String())
+	defer second.Close()
+
+	messageID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID.String()}},
+		Content:     "hello",
+	}, time.Now())
+
+	_, err := chatService.EditMessage(ctx, roomID, authorID.String(), messageID, "hello @a", []message.Mention{
+		{Offset: 6, Length: 2, Type: message.MentionTypeUser, UserId: firstID.String()},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, messageID.String(), readFrame(t, first, "mention")["messageId"])
+
+	// 이미 멘션된 사용자에게는 다시 알리지 않음
+	edited, err := chatService.EditMessage(ctx, roomID, authorID.String(), messageID, "hello @a @b", []message.Mention{
+		{Offset: 6, Length: 2, Type: message.MentionTypeUser, UserId: firstID.String()},
+		{Offset: 9, Length: 2, Type: message.MentionTypeUser, UserId: secondID.String()},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, edited.(*message.TextMessage).Mentions, 2)
+	readFrame(t, second, "mention")
+
+	page, err := chatService.ListMentions(ctx, firstID.String(), message.MentionQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{roomID: 1}, page.UnreadCounts)
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisMessageRepositoryReactions(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+	ids := saveNumberedMessages(t, repo, roomID, 2)
+
+	_, changed, err := repo.AddReaction(ctx, roomID, ids[0], "user-1", "👍", 2)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	_, _, err = repo.AddReaction(ctx, roomID, ids[0], "user-2", "🎉", 2)
+	assert.NoError(t, err)
+	reactions, _, err := repo.AddReaction(ctx, roomID, ids[0], "user-2", "👍", 2)
+	assert.NoError(t, err)
+
+	// 이모지는 처음 반응한 순서로, 사용자는 반응한 순서로 집계됨
+	assert.Equal(t, []message.Reaction{
+		{Emoji: "👍", Count: 2, UserIds: []string{"user-1", "user-2"}},
+		{Emoji: "🎉", Count: 1, UserIds: []string{"user-2"}},
+	}, reactions)
+
+	// 같은 반응을 다시 남기면 바뀌지 않고, 서로 다른 반응 수 제한을 넘으면 거절됨
+	_, changed, err = repo.AddReaction(ctx, roomID, ids[0], "user-1", "👍", 2)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	_, _, err = repo.AddReaction(ctx, roomID, ids[0], "user-2", "❤️", 2)
+	assert.ErrorIs(t, err, repository.ErrTooManyReactions)
+
+	reactions, changed, err = repo.RemoveReaction(ctx, roomID, ids[0], "user-2", "🎉")
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, []message.Reaction{{Emoji: "👍", Count: 2, UserIds: []string{"user-1", "user-2"}}}, reactions)
+
+	all, err := repo.GetReactions(ctx, roomID, ids)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, reactions, all[ids[0]])
+
+	// 모두에게서 삭제한 메시지의 반응은 함께 지워짐
+	deleteForEveryone(t, repo, roomID, ids[0])
+	all, err = repo.GetReactions(ctx, roomID, ids)
+	assert.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func sendReaction(t *testing.T, conn *websocket.Conn, roomID string, messageID uuid.UUID, clientMessageID, emoji, action string) {
+	err := conn.WriteJSON(map[string]string{
+		"type":            "reaction",
+		"roomId":          roomID,
+		"messageId":       messageID.String(),
+		"clientMessageId": clientMessageID,
+		"emoji":           emoji,
+		"action":          action,
+	})
+	assert.NoError(t, err)
+}
+
+func TestReactionFrameBroadcastsAndAppearsInHistory(t *testing.T) {
+	chatService, msgRepo := newEditChatService(t, clock.New())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+	reactorID := uuid.NewString()
+	messageID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "hello",
+	}, time.Now())
+
+	author := dialChat(t, wsURL, roomID, authorID)
+	defer author.Close()
+	reactor := dialChat(t, wsURL, roomID, reactorID)
+	defer reactor.Close()
+	readFrame(t, author, "userJoined")
+
+	sendReaction(t, reactor, roomID, messageID, "react-1", "👍", "add")
+	ack := readFrame(t, reactor, "ack")
+	assert.Equal(t, "react-1", ack["clientMessageId"])
+
+	event := readFrame(t, author, "reaction")
+	assert.Equal(t, messageID.String(), event["messageId"])
+	assert.Equal(t, reactorID, event["userId"])
+	assert.Equal(t, "add", event["action"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"emoji": "👍", "count": float64(1), "userIds": []interface{}{reactorID}},
+	}, event["reactions"])
+
+	// 기본 제한(3개)을 넘는 서로 다른 반응은 error 이벤트로 거절됨
+	for _, emoji := range []string{"🎉", "❤️"} {
+		sendReaction(t, reactor, roomID, messageID, "react-"+emoji, emoji, "add")
+		readFrame(t, reactor, "ack")
+	}
+	sendReaction(t, reactor, roomID, messageID, "react-4", "😂", "add")
+	errorFrame := readFrame(t, reactor, "error")
+	assert.Equal(t, "too_many_reactions", errorFrame["code"])
+
+	// 기록 조회에 반응 집계가 담김
+	history, err := chatService.GetMessages(context.Background(), roomID, authorID, 0)
+	assert.NoError(t, err)
+	assert.Len(t, history.Messages, 1)
+	assert.Len(t, history.Messages[0].Base().Reactions, 3)
+
+	page, err := chatService.ListMessages(context.Background(), roomID, authorID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, "👍", page.Messages[0].Base().Reactions[0].Emoji)
+}
@@ -0,0 +1,230 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// cursorRoomRepository는 멤버십은 RoomRepositoryMock으로 확인하고, 읽음/전달 커서는 메모리에 둡니다.
+type cursorRoomRepository struct {
+	*RoomRepositoryMock
+
+	mutex         sync.Mutex
+	read          map[uuid.UUID]uuid.UUID
+	delivered     map[uuid.UUID]uuid.UUID
+	deliveryCalls int
+}
+
+func newCursorRoomRepository() *cursorRoomRepository {
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	return &cursorRoomRepository{
+		RoomRepositoryMock: roomRepo,
+		read:               make(map[uuid.UUID]uuid.UUID),
+		delivered:          make(map[uuid.UUID]uuid.UUID),
+	}
+}
+
+func (r *cursorRoomRepository) advance(cursors map[uuid.UUID]uuid.UUID, userID, messageID uuid.UUID) bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cursor, ok := cursors[userID]
+	if ok && bytes.Compare(cursor[:], messageID[:]) >= 0 {
+		return false
+	}
+	cursors[userID] = messageID
+	return true
+}
+
+func (r *cursorRoomRepository) AdvanceReadCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	return r.advance(r.read, userID, messageID), nil
+}
+
+func (r *cursorRoomRepository) AdvanceDeliveryCursor(ctx context.Context, roomID, userID, messageID uuid.UUID) (bool, error) {
+	r.mutex.Lock()
+	r.deliveryCalls++
+	r.mutex.Unlock()
+	return r.advance(r.delivered, userID, messageID), nil
+}
+
+func (r *cursorRoomRepository) DeliveryCalls() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.deliveryCalls
+}
+
+func (r *cursorRoomRepository) ReadCursor(userID uuid.UUID) uuid.UUID {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.read[userID]
+}
+
+func (r *cursorRoomRepository) GetReadCursors(ctx context.Context, roomID uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	cursors := make(map[uuid.UUID]uuid.UUID, len(r.read))
+	for userID, cursor := range r.read {
+		cursors[userID] = cursor
+	}
+	return cursors, nil
+}
+
+func newReceiptChatService(t *testing.T) (service.ChatService, repository.MessageRepository, *cursorRoomRepository) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	msgRepo := redisRepo.NewRedisMessageRepository(client)
+	roomRepo := newCursorRoomRepository()
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.MentionRepo = redisRepo.NewRedisMentionRepository(client)
+	deps.SummaryRepo = redisRepo.NewRedisRoomSummaryRepository(client)
+	chatService := service.NewChatService(deps, service.DefaultChatConfig())
+	return chatService, msgRepo, roomRepo
+}
+
+func sendRead(t *testing.T, conn *websocket.Conn, roomID, clientMessageID string, messageID uuid.UUID) {
+	err := conn.WriteJSON(map[string]string{
+		"type":            "read",
+		"roomId":          roomID,
+		"clientMessageId": clientMessageID,
+		"messageId":       messageID.String(),
+	})
+	assert.NoError(t, err)
+}
+
+// readFrames는 frameTypes의 프레임을 도착 순서와 관계없이 하나씩 읽어 종류별로 반환합니다. 다른 프레임은 버립니다.
+func readFrames(t *testing.T, conn *websocket.Conn, frameTypes ...string) map[string]map[string]interface{} {
+	frames := make(map[string]map[string]interface{}, len(frameTypes))
+	for len(frames) < len(frameTypes) {
+		event := readEvent(t, conn)
+		frameType, _ := event["type"].(string)
+		for _, want := range frameTypes {
+			if frameType == want && frames[want] == nil {
+				frames[want] = event
+			}
+		}
+	}
+	return frames
+}
+
+func TestReadAndDeliveryReceipts(t *testing.T) {
+	chatService, msgRepo, roomRepo := newReceiptChatService(t)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	ctx := context.Background()
+	roomID := uuid.NewString()
+	authorID, readerID, idleID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+
+	author := dialChat(t, wsURL, roomID, authorID)
+	defer author.Close()
+	reader := dialChat(t, wsURL, roomID, readerID)
+	defer reader.Close()
+	readFrame(t, author, "userJoined")
+
+	// 메시지가 다른 멤버의 소켓에 쓰이면 작성자에게 전달 확인이 나감
+	sendClientMessage(t, author, roomID, "msg-1", "first")
+	frames := readFrames(t, author, "ack", "deliveryReceipt")
+	first, _ := uuid.Parse(frames["ack"]["messageId"].(string))
+	assert.Equal(t, readerID, frames["deliveryReceipt"]["userId"])
+	assert.Equal(t, first.String(), frames["deliveryReceipt"]["messageId"])
+
+	sendClientMessage(t, author, roomID, "msg-2", "second")
+	frames = readFrames(t, author, "ack", "deliveryReceipt")
+	second, _ := uuid.Parse(frames["ack"]["messageId"].(string))
+	assert.Equal(t, second.String(), frames["deliveryReceipt"]["messageId"])
+
+	// 보낸 사람의 읽음 커서는 읽음 확인 없이 마지막으로 보낸 메시지로 모아서 옮겨짐
+	assert.Eventually(t, func() bool {
+		return roomRepo.ReadCursor(uuid.MustParse(authorID)) == second
+	}, 3*time.Second, 50*time.Millisecond)
+
+	sendRead(t, reader, roomID, "read-1", first)
+	readFrame(t, reader, "ack")
+	receipt := readFrame(t, author, "readReceipt")
+	assert.Equal(t, readerID, receipt["userId"])
+	assert.Equal(t, first.String(), receipt["messageId"])
+
+	// 커서는 앞으로만 움직이므로 이전 메시지를 다시 읽으면 ack만 옴
+	sendRead(t, reader, roomID, "read-2", second)
+	readFrame(t, reader, "ack")
+	readFrame(t, author, "readReceipt")
+	sendRead(t, reader, roomID, "read-3", first)
+	readFrame(t, reader, "ack")
+
+	// 없는 메시지는 읽을 수 없음
+	sendRead(t, reader, roomID, "read-4", uuidAt(time.Now().UnixMilli()))
+	assert.Equal(t, "message_not_found", readFrame(t, reader, "error")["code"])
+
+	// 기록의 readCount는 작성자를 뺀, 그 메시지까지 읽은 멤버 수
+	third := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: readerID}},
+		Content:     "third",
+	}, time.Now().Add(time.Second))
+	page, err := chatService.ListMessages(ctx, roomID, idleID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 3)
+	assert.Equal(t, 1, page.Messages[0].Base().ReadCount)
+	assert.Equal(t, 1, page.Messages[1].Base().ReadCount)
+	assert.Equal(t, third, page.Messages[2].GetID())
+	assert.Equal(t, 0, page.Messages[2].Base().ReadCount)
+
+	// 전달 확인은 작성자에게만 가고, 보낸 사람의 읽음 확인은 나가지 않음
+	assert.False(t, readUntil(t, reader, 700*time.Millisecond, func(event map[string]interface{}) bool {
+		return event["type"] == "deliveryReceipt" || event["type"] == "readReceipt" && event["userId"] == authorID
+	}))
+}
+
+func TestDeliveryCursorsAreBatchedPerClient(t *testing.T) {
+	chatService, _, roomRepo := newReceiptChatService(t)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID := uuid.NewString()
+	authorID, readerID := uuid.NewString(), uuid.NewString()
+
+	author := dialChat(t, wsURL, roomID, authorID)
+	defer author.Close()
+	reader := dialChat(t, wsURL, roomID, readerID)
+	defer reader.Close()
+	readFrame(t, author, "userJoined")
+
+	// 짧은 시간에 보낸 메시지는 한 번에 반영되어 마지막 메시지의 전달 확인만 옴
+	const messageCount = 10
+	var last string
+	for i := 0; i < messageCount; i++ {
+		sendClientMessage(t, author, roomID, "msg-"+strconv.Itoa(i), "hello")
+		last = readFrame(t, author, "ack")["messageId"].(string)
+	}
+
+	receipts := 0
+	assert.True(t, readUntil(t, author, 2*time.Second, func(event map[string]interface{}) bool {
+		if event["type"] != "deliveryReceipt" {
+			return false
+		}
+		receipts++
+		assert.Equal(t, readerID, event["userId"])
+		return event["messageId"] == last
+	}))
+	assert.Less(t, receipts, messageCount)
+	assert.Less(t, roomRepo.DeliveryCalls(), messageCount)
+}
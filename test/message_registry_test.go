@@ -0,0 +1,150 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"server/internal/models/message"
+	redisRepo "server/internal/repository/redis"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// registeredMessageFixtures는 등록된 모든 메시지 타입의 예시입니다. 타입을 등록하면 여기에도 추가해야 합니다.
+func registeredMessageFixtures() map[string]message.Message {
+	base := func(messageType string) message.BaseMessage {
+		id, _ := uuid.NewV7()
+		return message.BaseMessage{
+			Id:        id,
+			RoomId:    uuid.NewString(),
+			Type:      messageType,
+			Author:    message.User{Id: uuid.NewString()},
+			Timestamp: "2024-01-01T00:00:00Z",
+		}
+	}
+
+	return map[string]message.Message{
+		"message": &message.TextMessage{BaseMessage: base("message"), Content: "안녕하세요"},
+		"image":   &message.ImageMessage{BaseMessage: base("image"), ImageURL: "https://example.com/a.png"},
+		"deleted": &message.DeletedMessage{BaseMessage: base("deleted"), DeletedAt: "2024-01-01T00:01:00Z"},
+	}
+}
+
+func TestRegisteredMessageTypesRoundTrip(t *testing.T) {
+	fixtures := registeredMessageFixtures()
+
+	for _, messageType := range message.Types() {
+		t.Run(messageType, func(t *testing.T) {
+			original, ok := fixtures[messageType]
+			if !assert.True(t, ok, "%s 타입의 예시가 없음", messageType) {
+				return
+			}
+
+			data, err := json.Marshal(original)
+			assert.NoError(t, err)
+
+			decoded, err := message.Decode(data)
+			assert.NoError(t, err)
+			assert.IsType(t, original, decoded)
+			assert.Equal(t, original, decoded)
+
+			// ToJson과 FromJson도 같은 결과를 냄
+			fromJson, _ := message.New(messageType)
+			assert.NoError(t, fromJson.FromJson(json.RawMessage(original.ToJson())))
+			assert.Equal(t, original, fromJson)
+		})
+	}
+}
+
+func TestUnknownMessageTypeIsPreserved(t *testing.T) {
+	id, _ := uuid.NewV7()
+	data := []byte(`{"id":"` + id.String() + `","roomId":"room-1","type":"poll","author":{"id":"user-1"},"options":["a","b"]}`)
+
+	decoded, err := message.Decode(data)
+	assert.NoError(t, err)
+	assert.IsType(t, &message.RawMessage{}, decoded)
+	assert.Equal(t, id, decoded.GetID())
+	assert.Equal(t, "poll", decoded.GetType())
+	assert.Equal(t, "user-1", decoded.GetAuthor().Id)
+
+	encoded, err := json.Marshal(decoded)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(data), string(encoded))
+	assert.JSONEq(t, string(data), decoded.ToJson())
+}
+
+func TestDecoratedUnknownMessageKeepsCommonFields(t *testing.T) {
+	id, _ := uuid.NewV7()
+	quoted, _ := uuid.NewV7()
+	data := []byte(`{"id":"` + id.String() + `","roomId":"room-1","type":"poll","author":{"id":"user-1"},` +
+		`"replyTo":{"id":"` + quoted.String() + `"},"options":["a","b"]}`)
+
+	decoded, err := message.Decode(data)
+	assert.NoError(t, err)
+
+	// 기록을 조회할 때처럼 공통 필드를 채움
+	base := decoded.Base()
+	base.Reactions = []message.Reaction{{Emoji: "👍", Count: 1, UserIds: []string{"user-2"}}}
+	base.ReadCount = 2
+	base.Thread = &message.ThreadSummary{ReplyCount: 3}
+	base.ReplyTo = &message.Quote{Id: quoted, Author: &message.User{Id: "user-2"}, Type: "message", Preview: "안녕하세요"}
+	base.EditedAt = "2024-01-01T00:01:00Z"
+
+	for _, encoded := range [][]byte{mustMarshal(t, decoded), []byte(decoded.ToJson())} {
+		var fields map[string]interface{}
+		assert.NoError(t, json.Unmarshal(encoded, &fields))
+		assert.Equal(t, []interface{}{"a", "b"}, fields["options"])
+
+		roundTrip, err := message.Decode(encoded)
+		assert.NoError(t, err)
+		assert.IsType(t, &message.RawMessage{}, roundTrip)
+		assert.Equal(t, *base, *roundTrip.Base())
+	}
+
+	// 비운 공통 필드는 받은 JSON에서 되살아나지 않음
+	base.ReplyTo = nil
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(mustMarshal(t, decoded), &fields))
+	assert.NotContains(t, fields, "replyTo")
+}
+
+func mustMarshal(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return data
+}
+
+func TestRedisHistoryKeepsMessageFields(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	roomID := uuid.NewString()
+	fixtures := registeredMessageFixtures()
+	unknown := `{"id":"` + uuid.NewString() + `","roomId":"` + roomID + `","type":"poll","author":{"id":"user-1"},"options":["a","b"]}`
+
+	stored := []string{fixtures["message"].ToJson(), fixtures["image"].ToJson(), unknown}
+	for _, msgJSON := range stored {
+		err := client.XAdd(ctx, &redis.XAddArgs{
+			Stream: "stream:room:" + roomID + ":messages",
+			Values: map[string]interface{}{"message": msgJSON},
+		}).Err()
+		assert.NoError(t, err)
+	}
+
+	repo := redisRepo.NewRedisMessageRepository(client)
+	page, err := repo.GetMessages(ctx, roomID, 0, 10)
+	assert.NoError(t, err)
+	messages := page.Messages
+	if !assert.Len(t, messages, 3) {
+		return
+	}
+
+	assert.Equal(t, fixtures["message"], messages[0])
+	assert.Equal(t, fixtures["image"], messages[1])
+	assert.JSONEq(t, unknown, messages[2].ToJson())
+}
@@ -0,0 +1,241 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/message"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// uuidAt은 밀리초 타임스탬프가 ms인 UUIDv7을 만듭니다.
+func uuidAt(ms int64) uuid.UUID {
+	id, _ := uuid.NewV7()
+	for i := 5; i >= 0; i-- {
+		id[i] = byte(ms)
+		ms >>= 8
+	}
+	return id
+}
+
+func messageContents(messages []message.Message) []string {
+	contents := make([]string, len(messages))
+	for i, msg := range messages {
+		contents[i] = msg.(*message.TextMessage).Content
+	}
+	return contents
+}
+
+func TestRedisMessageRepositoryStreamIDs(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+
+	// 같은 밀리초에 저장된 메시지와, 시계가 늦은 노드에서 저장된 메시지를 포함
+	millis := []int64{1000, 1000, 1001, 999, 1005}
+	contents := []string{"m1", "m2", "m3", "m4", "m5"}
+	ids := make([]uuid.UUID, len(millis))
+	for i, ms := range millis {
+		ids[i] = uuidAt(ms)
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: ids[i], RoomId: roomID, Type: "message", Author: message.User{Id: "user-1"}},
+			Content:     contents[i],
+		}
+		assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	}
+
+	entries, err := client.XRange(ctx, "stream:room:"+roomID+":messages", "-", "+").Result()
+	assert.NoError(t, err)
+	entryIDs := make([]string, len(entries))
+	for i, entry := range entries {
+		entryIDs[i] = entry.ID
+	}
+	assert.Equal(t, []string{"1000-0", "1000-1", "1001-0", "1001-1", "1005-0"}, entryIDs)
+
+	// UUID 커서: 커서 메시지 다음부터 저장 순서대로
+	all, err := repo.GetMessagesByUUID(ctx, roomID, uuid.Nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, contents, messageContents(all.Messages))
+	assert.False(t, all.HasMore)
+
+	for i, id := range ids {
+		page, err := repo.GetMessagesByUUID(ctx, roomID, id, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, contents[i+1:], messageContents(page.Messages), "커서 %s", contents[i])
+	}
+
+	// 숫자 커서: 해당 밀리초 이후에 저장된 메시지
+	page, err := repo.GetMessages(ctx, roomID, 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, contents, messageContents(page.Messages))
+
+	page, err = repo.GetMessages(ctx, roomID, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4", "m5"}, messageContents(page.Messages))
+
+	page, err = repo.GetMessages(ctx, roomID, 1001, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m5"}, messageContents(page.Messages))
+
+	// 한도를 넘는 메시지는 남은 것으로 알리고 마지막으로 반환한 메시지를 다음 커서로 줌
+	page, err = repo.GetMessagesByUUID(ctx, roomID, ids[0], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+	assert.Equal(t, ids[2], page.NextCursor)
+
+	page, err = repo.GetMessages(ctx, roomID, 0, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, contents[:4], messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+
+	page, err = repo.GetMessagesByUUID(ctx, roomID, ids[2], 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m4", "m5"}, messageContents(page.Messages))
+	assert.False(t, page.HasMore)
+	assert.Equal(t, uuid.Nil, page.NextCursor)
+}
+
+func TestRedisMessageRepositoryCursorEdgeCases(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+
+	for i, ms := range []int64{1000, 1001, 1002} {
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: uuidAt(ms), RoomId: roomID, Type: "message"},
+			Content:     []string{"m1", "m2", "m3"}[i],
+		}
+		assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	}
+
+	// 스트림에 없는 메시지(잘려 나간 경우 등)가 커서면 해당 밀리초부터 반환
+	page, err := repo.GetMessagesByUUID(ctx, roomID, uuidAt(1001), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, messageContents(page.Messages))
+
+	// UUIDv7이 아닌 커서는 위치를 정할 수 없음
+	_, err = repo.GetMessagesByUUID(ctx, roomID, uuid.New(), 10)
+	assert.ErrorIs(t, err, repository.ErrInvalidMessageID)
+
+	// ID가 없는 메시지는 저장할 때 UUIDv7을 발급함
+	msg := &message.TextMessage{BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message"}, Content: "m4"}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	assert.Equal(t, uuid.Version(7), msg.Id.Version())
+
+	page, err = repo.GetMessagesByUUID(ctx, roomID, uuidAt(1002), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m3", "m4"}, messageContents(page.Messages))
+}
+
+func TestRedisMessageRepositoryGetMessagesByID(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+
+	// 같은 밀리초에 저장된 메시지가 많아도 찾음
+	ids := make([]uuid.UUID, 12)
+	for i := range ids {
+		ids[i] = uuidAt(1000)
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: ids[i], RoomId: roomID, Type: "message", Author: message.User{Id: "user-1"}},
+			Content:     "m" + strconv.Itoa(i),
+		}
+		assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	}
+
+	edited, err := repo.GetMessage(ctx, roomID, ids[1])
+	assert.NoError(t, err)
+	edited.(*message.TextMessage).Content = "m1 (edited)"
+	assert.NoError(t, repo.EditMessage(ctx, roomID, edited, message.Revision{Content: "m1"}))
+	deleteForEveryone(t, repo, roomID, ids[2])
+
+	missing := uuidAt(1000)
+	found, err := repo.GetMessagesByID(ctx, roomID, []uuid.UUID{ids[0], ids[1], ids[2], ids[11], ids[0], missing, uuid.New()})
+	assert.NoError(t, err)
+	assert.Len(t, found, 4)
+	assert.Equal(t, "m0", found[ids[0]].(*message.TextMessage).Content)
+	assert.Equal(t, "m1 (edited)", found[ids[1]].(*message.TextMessage).Content)
+	assert.IsType(t, &message.DeletedMessage{}, found[ids[2]])
+	assert.Equal(t, "m11", found[ids[11]].(*message.TextMessage).Content)
+	assert.NotContains(t, found, missing)
+}
+
+func TestRedisMessageRepositoryListMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+
+	// m0 ~ m9, 일부는 같은 밀리초
+	ids := make([]uuid.UUID, 10)
+	for i := range ids {
+		ids[i] = uuidAt(int64(1000 + i/2))
+		msg := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: ids[i], RoomId: roomID, Type: "message"},
+			Content:     "m" + string(rune('0'+i)),
+		}
+		assert.NoError(t, repo.SaveMessage(ctx, roomID, msg))
+	}
+
+	// 커서가 없으면 가장 최근 메시지를 오래된 순서로
+	page, err := repo.ListMessages(ctx, roomID, message.PageQuery{Limit: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m6", "m7", "m8", "m9"}, messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+	assert.Equal(t, ids[6], page.NextCursor)
+
+	// before로 위로 스크롤
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: page.NextCursor, Limit: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3", "m4", "m5"}, messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: page.NextCursor, Limit: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m0", "m1"}, messageContents(page.Messages))
+	assert.False(t, page.HasMore)
+	assert.Equal(t, uuid.Nil, page.NextCursor)
+
+	// after로 아래로 스크롤
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{After: ids[1], Limit: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3", "m4", "m5", "m6"}, messageContents(page.Messages))
+	assert.True(t, page.HasMore)
+	assert.Equal(t, ids[6], page.NextCursor)
+
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{After: page.NextCursor, Limit: 5})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m7", "m8", "m9"}, messageContents(page.Messages))
+	assert.False(t, page.HasMore)
+
+	// 정확히 limit개가 남은 경우 더 이상 없음
+	page, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: ids[3], Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"m0", "m1", "m2"}, messageContents(page.Messages))
+	assert.False(t, page.HasMore)
+
+	_, err = repo.ListMessages(ctx, roomID, message.PageQuery{Before: uuid.New(), Limit: 3})
+	assert.ErrorIs(t, err, repository.ErrInvalidMessageID)
+}
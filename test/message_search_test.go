@@ -0,0 +1,200 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"server/internal/broadcast"
+	"server/internal/handler/chatting"
+	"server/internal/models/message"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/authenticator"
+	"server/pkg/clock"
+	"strings"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MessageSearchRepositoryMock은 MessageSearchRepository 인터페이스를 구현하는 모의 객체입니다.
+type MessageSearchRepositoryMock struct {
+	mock.Mock
+}
+
+func (m *MessageSearchRepositoryMock) SearchMessages(ctx context.Context, userID uuid.UUID, query message.SearchQuery) (message.SearchPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(message.SearchPage), args.Error(1)
+}
+
+// MessageSearchServiceMock은 MessageSearchService 인터페이스를 구현하는 모의 객체입니다.
+type MessageSearchServiceMock struct {
+	mock.Mock
+}
+
+func (m *MessageSearchServiceMock) SearchMessages(ctx context.Context, userID string, query message.SearchQuery) (message.SearchPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(message.SearchPage), args.Error(1)
+}
+
+func searchResultOf(content string) message.SearchResult {
+	id, _ := uuid.NewV7()
+	return message.SearchResult{Message: &message.TextMessage{
+		BaseMessage: message.BaseMessage{Id: id, Type: "message"},
+		Content:     content,
+	}}
+}
+
+func TestSearchMessagesHighlightsSnippets(t *testing.T) {
+	searchRepo := new(MessageSearchRepositoryMock)
+	searchService := service.NewMessageSearchService(searchRepo, new(MessageRepositoryMock), new(RoomRepositoryMock), broadcast.NewLocalBus(), clock.New())
+	userID := uuid.New()
+
+	filler := strings.Repeat("가나다라마바사아자차카타파하 ", 5)
+	long := filler + "내일 회의는 3시입니다 " + filler
+	searchRepo.On("SearchMessages", mock.Anything, userID, message.SearchQuery{Text: "회의", Limit: 20}).
+		Return(message.SearchPage{Results: []message.SearchResult{
+			searchResultOf("오늘 회의 자료 <b>공유</b>"),
+			searchResultOf("Meeting 회의록 MEETING"),
+			searchResultOf(long),
+		}}, nil)
+
+	page, err := searchService.SearchMessages(context.Background(), userID.String(), message.SearchQuery{Text: "  회의 "})
+	assert.NoError(t, err)
+	if !assert.Len(t, page.Results, 3) {
+		return
+	}
+
+	// 일치한 부분은 <mark>로 감싸고 본문은 이스케이프함
+	assert.Equal(t, "오늘 <mark>회의</mark> 자료 &lt;b&gt;공유&lt;/b&gt;", page.Results[0].Snippet)
+	assert.Equal(t, "Meeting <mark>회의</mark>록 MEETING", page.Results[1].Snippet)
+
+	// 긴 본문은 첫 일치 부분 근처만 남김
+	snippet := page.Results[2].Snippet
+	assert.Contains(t, snippet, "<mark>회의</mark>는 3시입니다")
+	assert.True(t, strings.HasPrefix(snippet, "…"))
+	assert.True(t, strings.HasSuffix(snippet, "…"))
+	assert.Len(t, []rune(strings.NewReplacer("<mark>", "", "</mark>", "", "…", "").Replace(snippet)), 80)
+
+	// 대소문자를 구분하지 않고 여러 검색어를 표시함
+	searchRepo.On("SearchMessages", mock.Anything, userID, message.SearchQuery{Text: "meeting 회의록", Limit: 20}).
+		Return(message.SearchPage{Results: []message.SearchResult{searchResultOf("Meeting 회의록 MEETING")}}, nil)
+	page, err = searchService.SearchMessages(context.Background(), userID.String(), message.SearchQuery{Text: "meeting 회의록"})
+	assert.NoError(t, err)
+	assert.Equal(t, "<mark>Meeting</mark> <mark>회의록</mark> <mark>MEETING</mark>", page.Results[0].Snippet)
+}
+
+func TestSearchMessagesSkipsHiddenMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	searchRepo := new(MessageSearchRepositoryMock)
+	msgRepo := redisRepo.NewRedisMessageRepository(client)
+	searchService := service.NewMessageSearchService(searchRepo, msgRepo, new(RoomRepositoryMock), broadcast.NewLocalBus(), clock.New())
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// 숨긴 메시지는 그 메시지가 있는 방에서만 빠짐
+	roomID, otherRoomID := uuid.NewString(), uuid.NewString()
+	hidden, kept, other := searchResultOf("회의 1"), searchResultOf("회의 2"), searchResultOf("회의 3")
+	hidden.Message.Base().RoomId = roomID
+	kept.Message.Base().RoomId = roomID
+	other.Message.Base().RoomId = otherRoomID
+	assert.NoError(t, msgRepo.HideMessage(ctx, roomID, userID.String(), hidden.Message.GetID()))
+	assert.NoError(t, msgRepo.HideMessage(ctx, roomID, userID.String(), other.Message.GetID()))
+
+	searchRepo.On("SearchMessages", mock.Anything, userID, message.SearchQuery{Text: "회의", Limit: 20}).
+		Return(message.SearchPage{Results: []message.SearchResult{hidden, kept, other}, HasMore: true, NextCursor: other.Message.GetID()}, nil)
+
+	page, err := searchService.SearchMessages(ctx, userID.String(), message.SearchQuery{Text: "회의"})
+	assert.NoError(t, err)
+	if assert.Len(t, page.Results, 2) {
+		assert.Equal(t, kept.Message.GetID(), page.Results[0].Message.GetID())
+		assert.Equal(t, other.Message.GetID(), page.Results[1].Message.GetID())
+	}
+	assert.True(t, page.HasMore)
+	assert.Equal(t, other.Message.GetID(), page.NextCursor)
+}
+
+func TestSearchMessagesChecksRoomMembershipAndLimit(t *testing.T) {
+	searchRepo := new(MessageSearchRepositoryMock)
+	roomRepo := new(RoomRepositoryMock)
+	searchService := service.NewMessageSearchService(searchRepo, new(MessageRepositoryMock), roomRepo, broadcast.NewLocalBus(), clock.New())
+
+	userID := uuid.New()
+	roomID := uuid.New()
+	otherRoomID := uuid.New()
+
+	roomRepo.On("IsUserInRoom", mock.Anything, roomID, userID).Return(true, nil)
+	roomRepo.On("IsUserInRoom", mock.Anything, otherRoomID, userID).Return(false, nil)
+	searchRepo.On("SearchMessages", mock.Anything, userID, message.SearchQuery{Text: "hi", RoomID: roomID.String(), Limit: 50}).
+		Return(message.SearchPage{}, nil)
+
+	_, err := searchService.SearchMessages(context.Background(), userID.String(), message.SearchQuery{Text: "hi", RoomID: roomID.String(), Limit: 1000})
+	assert.NoError(t, err)
+
+	_, err = searchService.SearchMessages(context.Background(), userID.String(), message.SearchQuery{Text: "hi", RoomID: otherRoomID.String()})
+	var notMemberErr *service.NotRoomMemberError
+	assert.ErrorAs(t, err, &notMemberErr)
+
+	_, err = searchService.SearchMessages(context.Background(), userID.String(), message.SearchQuery{Text: "   "})
+	assert.ErrorIs(t, err, service.ErrEmptySearchQuery)
+
+	searchRepo.AssertNumberOfCalls(t, "SearchMessages", 1)
+}
+
+func TestSearchHandler(t *testing.T) {
+	searchService := new(MessageSearchServiceMock)
+	handler := chatting.NewSearchHandler(searchService)
+
+	userID := uuid.New()
+	roomID := uuid.NewString()
+	cursor, _ := uuid.NewV7()
+	result := searchResultOf("hello world")
+	result.Snippet = "<mark>hello</mark> world"
+
+	searchService.On("SearchMessages", mock.Anything, userID.String(), mock.MatchedBy(func(query message.SearchQuery) bool {
+		return query.Text == "hello" && query.RoomID == roomID && query.AuthorID == "user-1" &&
+			query.From.Year() == 2024 && query.To.Year() == 2025 && query.Before == cursor && query.Limit == 10
+	})).Return(message.SearchPage{Results: []message.SearchResult{result}, HasMore: true, NextCursor: result.Message.GetID()}, nil)
+
+	req, _ := http.NewRequest("GET", "/messages/search?q=hello&roomId="+roomID+"&senderId=user-1&from=2024-01-01T00:00:00Z&to=2025-01-01T00:00:00Z&before="+cursor.String()+"&limit=10", nil)
+	req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+	rr := httptest.NewRecorder()
+
+	handler.SearchMessages(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	var response map[string]interface{}
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+	assert.Equal(t, true, response["hasMore"])
+	assert.Equal(t, result.Message.GetID().String(), response["nextCursor"])
+	if results, ok := response["results"].([]interface{}); assert.True(t, ok) && assert.Len(t, results, 1) {
+		assert.Equal(t, "<mark>hello</mark> world", results[0].(map[string]interface{})["snippet"])
+	}
+	searchService.AssertExpectations(t)
+
+	for _, query := range []string{
+		"",
+		"?q=hi&roomId=not-a-uuid",
+		"?q=hi&from=yesterday",
+		"?q=hi&from=2025-01-01T00:00:00Z&to=2024-01-01T00:00:00Z",
+		"?q=hi&before=not-a-uuid",
+		"?q=hi&limit=0",
+	} {
+		req, _ := http.NewRequest("GET", "/messages/search"+query, nil)
+		req = req.WithContext(context.WithValue(req.Context(), authenticator.ContextKeyUserID, userID.String()))
+		rr := httptest.NewRecorder()
+
+		handler.SearchMessages(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
@@ -0,0 +1,199 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/message"
+	redisRepo "server/internal/repository/redis"
+	"server/pkg/clock"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisMessageRepositoryThreads(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+	ids := saveNumberedMessages(t, repo, roomID, 2)
+	rootID := ids[0]
+
+	replies := make([]uuid.UUID, 3)
+	for i := range replies {
+		replies[i] = uuidAt(int64(2000 + i))
+		reply := &message.TextMessage{
+			BaseMessage: message.BaseMessage{Id: replies[i], RoomId: roomID, Type: "message", Author: message.User{Id: "user-" + strconv.Itoa(i%2)}, ThreadId: &rootID},
+			Content:     "r" + strconv.Itoa(i),
+		}
+		assert.NoError(t, repo.SaveMessage(ctx, roomID, reply))
+	}
+
+	// 답장도 방의 기록에 남음
+	all, err := repo.ListMessages(ctx, roomID, message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, all.Messages, 5)
+
+	latest, err := repo.GetThread(ctx, roomID, rootID, message.PageQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, messageContents(latest.Messages))
+	assert.True(t, latest.HasMore)
+	assert.Equal(t, replies[1], latest.NextCursor)
+
+	older, err := repo.GetThread(ctx, roomID, rootID, message.PageQuery{Before: latest.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r0"}, messageContents(older.Messages))
+	assert.False(t, older.HasMore)
+
+	newer, err := repo.GetThread(ctx, roomID, rootID, message.PageQuery{After: replies[0], Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"r1", "r2"}, messageContents(newer.Messages))
+
+	summaries, err := repo.GetThreadSummaries(ctx, roomID, ids)
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, 3, summaries[rootID].ReplyCount)
+	assert.Equal(t, "r2", summaries[rootID].LastReply.Preview)
+
+	participants, err := repo.ThreadParticipants(ctx, roomID, rootID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"user-0", "user-1"}, participants)
+
+	// 삭제된 마지막 답장은 본문 없이 요약되고, 답장 수에는 계속 포함됨
+	deleteForEveryone(t, repo, roomID, replies[2])
+	summaries, err = repo.GetThreadSummaries(ctx, roomID, []uuid.UUID{rootID})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summaries[rootID].ReplyCount)
+	assert.Equal(t, "deleted", summaries[rootID].LastReply.Type)
+	assert.Empty(t, summaries[rootID].LastReply.Preview)
+
+	latest, err = repo.GetThread(ctx, roomID, rootID, message.PageQuery{Limit: 10})
+	assert.NoError(t, err)
+	assert.Len(t, latest.Messages, 3)
+	assert.IsType(t, &message.DeletedMessage{}, latest.Messages[2])
+	assert.Equal(t, rootID, *latest.Messages[2].Base().ThreadId)
+}
+
+func TestRepliesStoreOnlyQuotedMessageID(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisMessageRepository(client)
+	roomID := uuid.NewString()
+	parent := &message.TextMessage{
+		BaseMessage: message.BaseMessage{Id: uuidAt(1000), RoomId: roomID, Type: "message", Author: message.User{Id: "user-1"}},
+		Content:     "secret",
+	}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, parent))
+
+	reply := &message.TextMessage{
+		BaseMessage: message.BaseMessage{Id: uuidAt(1001), RoomId: roomID, Type: "message", Author: message.User{Id: "user-2"}, ReplyTo: message.NewQuote(parent), ThreadId: &parent.Id},
+		Content:     "reply",
+	}
+	assert.NoError(t, repo.SaveMessage(ctx, roomID, reply))
+
+	// 보내는 메시지의 인용은 그대로 두고, 저장된 답장에는 인용한 메시지의 본문이 없음
+	assert.Equal(t, "secret", reply.ReplyTo.Preview)
+	entries, err := client.XRange(ctx, "stream:room:"+roomID+":messages", "-", "+").Result()
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.NotContains(t, entries[1].Values["message"], "secret")
+
+	stored, err := repo.GetMessage(ctx, roomID, reply.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, message.QuoteOf(parent.Id), stored.Base().ReplyTo)
+}
+
+func sendReply(t *testing.T, conn *websocket.Conn, roomID, clientMessageID, content string, replyTo uuid.UUID) {
+	err := conn.WriteJSON(map[string]string{
+		"type":            "message",
+		"roomId":          roomID,
+		"clientMessageId": clientMessageID,
+		"content":         content,
+		"replyTo":         replyTo.String(),
+	})
+	assert.NoError(t, err)
+}
+
+func TestRepliesFormThreadAndReachThreadParticipants(t *testing.T) {
+	chatService, msgRepo := newEditChatService(t, clock.New())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	ctx := context.Background()
+	roomID := uuid.NewString()
+	authorID := uuid.NewString()
+	followerID := uuid.NewString()
+	rootID := saveAuthoredMessage(t, msgRepo, &message.TextMessage{
+		BaseMessage: message.BaseMessage{RoomId: roomID, Type: "message", Author: message.User{Id: authorID}},
+		Content:     "root",
+	}, time.Now().Add(-time.Second))
+
+	// 스레드에만 참여한 사용자는 방을 구독하지 않아도 스레드 이벤트를 받음
+	assert.NoError(t, chatService.JoinThread(ctx, roomID, followerID, rootID))
+	follower := dialChat(t, wsURL, "", followerID)
+	defer follower.Close()
+
+	replier := dialChat(t, wsURL, roomID, uuid.NewString())
+	defer replier.Close()
+
+	sendReply(t, replier, roomID, "reply-1", "first", rootID)
+	ack := readFrame(t, replier, "ack")
+	firstReply, _ := uuid.Parse(ack["messageId"].(string))
+
+	event := readFrame(t, follower, "threadUpdated")
+	assert.Equal(t, rootID.String(), event["threadId"])
+	assert.Equal(t, float64(1), event["thread"].(map[string]interface{})["replyCount"])
+	reply := event["message"].(map[string]interface{})
+	assert.Equal(t, "root", reply["replyTo"].(map[string]interface{})["preview"])
+
+	// 답장에 답장해도 같은 루트의 스레드에 속함
+	sendReply(t, replier, roomID, "reply-2", "second", firstReply)
+	readFrame(t, replier, "ack")
+	event = readFrame(t, follower, "threadUpdated")
+	assert.Equal(t, float64(2), event["thread"].(map[string]interface{})["replyCount"])
+	reply = event["message"].(map[string]interface{})
+	assert.Equal(t, rootID.String(), reply["threadId"])
+	assert.Equal(t, firstReply.String(), reply["replyTo"].(map[string]interface{})["id"])
+
+	// 루트 메시지의 수정도 스레드 참여자에게 전달되고, 기록의 인용은 최신 판을 보여 줌
+	_, err := chatService.EditMessage(ctx, roomID, authorID, rootID, "root (edited)", nil)
+	assert.NoError(t, err)
+	edited := readFrame(t, follower, "messageEdited")
+	assert.Equal(t, rootID.String(), edited["messageId"])
+
+	page, err := chatService.ListMessages(ctx, roomID, authorID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Messages, 3)
+	assert.Equal(t, 2, page.Messages[0].Base().Thread.ReplyCount)
+	assert.Equal(t, "second", page.Messages[0].Base().Thread.LastReply.Preview)
+	assert.Equal(t, "root (edited)", page.Messages[1].Base().ReplyTo.Preview)
+
+	root, thread, err := chatService.GetThread(ctx, roomID, authorID, firstReply, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, rootID, root.GetID())
+	assert.Equal(t, []string{"first", "second"}, messageContents(thread.Messages))
+
+	// 없는 메시지에는 답장할 수 없음
+	sendReply(t, replier, roomID, "reply-3", "lost", uuidAt(time.Now().UnixMilli()))
+	errorFrame := readFrame(t, replier, "error")
+	assert.Equal(t, "message_not_found", errorFrame["code"])
+
+	// 루트 메시지를 삭제하면 답장의 인용에도 본문이 남지 않음
+	_, err = chatService.DeleteMessage(ctx, roomID, authorID, rootID)
+	assert.NoError(t, err)
+	page, err = chatService.ListMessages(ctx, roomID, authorID, message.PageQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, "deleted", page.Messages[1].Base().ReplyTo.Type)
+	assert.Empty(t, page.Messages[1].Base().ReplyTo.Preview)
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/presence"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedisPresenceRepository(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisPresenceRepository(client)
+	userID := uuid.NewString()
+	now := time.UnixMilli(1_700_000_000_000).UTC()
+	ttl := time.Minute
+
+	assertChange := func(wantBefore, wantAfter presence.Status) func(presence.Status, presence.Status, error) {
+		return func(before, after presence.Status, err error) {
+			assert.NoError(t, err)
+			assert.Equal(t, wantBefore, before)
+			assert.Equal(t, wantAfter, after)
+		}
+	}
+
+	// 하나라도 online인 연결이 있으면 online, 모두 away이면 away
+	assertChange(presence.StatusOffline, presence.StatusOnline)(repo.Heartbeat(ctx, userID, "s1", presence.StatusOnline, now, ttl))
+	assertChange(presence.StatusOnline, presence.StatusOnline)(repo.Heartbeat(ctx, userID, "s2", presence.StatusAway, now, ttl))
+	assertChange(presence.StatusOnline, presence.StatusAway)(repo.Heartbeat(ctx, userID, "s1", presence.StatusAway, now, ttl))
+	assertChange(presence.StatusAway, presence.StatusAway)(repo.RemoveSession(ctx, userID, "s1", now))
+	assertChange(presence.StatusAway, presence.StatusOffline)(repo.RemoveSession(ctx, userID, "s2", now.Add(time.Second)))
+
+	records, err := repo.GetPresence(ctx, []string{userID}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, presence.Record{Status: presence.StatusOffline, LastSeen: now.Add(time.Second)}, records[userID])
+
+	// 하트비트가 갱신되지 않은 연결은 ttl이 지나면 끊긴 것으로 봄
+	assertChange(presence.StatusOffline, presence.StatusOnline)(repo.Heartbeat(ctx, userID, "s3", presence.StatusOnline, now, ttl))
+	records, err = repo.GetPresence(ctx, []string{userID}, now.Add(ttl-time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, presence.StatusOnline, records[userID].Status)
+
+	assert.NoError(t, repo.SetHideLastSeen(ctx, userID, true))
+	otherID := uuid.NewString()
+	records, err = repo.GetPresence(ctx, []string{userID, otherID}, now.Add(ttl+time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, presence.Record{Status: presence.StatusOffline, LastSeen: now, HideLastSeen: true}, records[userID])
+	assert.Equal(t, presence.Record{Status: presence.StatusOffline}, records[otherID])
+}
+
+func TestPresenceServiceHidesLastSeenFromOthers(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	now := time.UnixMilli(1_700_000_000_000).UTC()
+	repo := redisRepo.NewRedisPresenceRepository(client)
+	userID, viewerID := uuid.NewString(), uuid.NewString()
+	friendRepo := new(FriendRepositoryMock)
+	friendRepo.On("GetAddedFriendIDs", mock.Anything, uuid.MustParse(viewerID), mock.Anything).Return([]uuid.UUID{uuid.MustParse(userID)}, nil)
+	presenceService := service.NewPresenceService(repo, friendRepo, new(RoomRepositoryMock), clock.NewFake(now))
+
+	_, _, err := repo.Heartbeat(ctx, userID, "s1", presence.StatusOnline, now, time.Minute)
+	assert.NoError(t, err)
+
+	statuses, err := presenceService.GetPresence(ctx, viewerID, []string{userID, viewerID, userID})
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, presence.StatusOnline, statuses[0].Status)
+	assert.NotNil(t, statuses[0].LastSeen)
+	assert.Equal(t, presence.Presence{UserId: viewerID, Status: presence.StatusOffline}, statuses[1])
+
+	assert.NoError(t, presenceService.SetHideLastSeen(ctx, userID, true))
+	statuses, err = presenceService.GetPresence(ctx, viewerID, []string{userID})
+	assert.NoError(t, err)
+	assert.Nil(t, statuses[0].LastSeen)
+
+	// 본인에게는 숨긴 마지막 접속 시각도 보임
+	statuses, err = presenceService.GetPresence(ctx, userID, []string{userID})
+	assert.NoError(t, err)
+	assert.NotNil(t, statuses[0].LastSeen)
+}
+
+func TestPresenceIsVisibleOnlyToFriendsAndRoommates(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	viewerID, friendID, roommateID, strangerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	friendRepo := new(FriendRepositoryMock)
+	friendRepo.On("GetAddedFriendIDs", mock.Anything, viewerID, mock.Anything).Return([]uuid.UUID{friendID}, nil)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("GetRoommateIDs", mock.Anything, viewerID, mock.Anything).Return([]uuid.UUID{roommateID}, nil)
+	presenceService := service.NewPresenceService(redisRepo.NewRedisPresenceRepository(client), friendRepo, roomRepo, clock.NewFake(time.Now()))
+
+	statuses, err := presenceService.GetPresence(ctx, viewerID.String(), []string{friendID.String(), roommateID.String(), viewerID.String()})
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+
+	// 친구가 아닌 사용자만 같은 방에 속했는지 확인함
+	roomRepo.AssertCalled(t, "GetRoommateIDs", mock.Anything, viewerID, []uuid.UUID{roommateID})
+
+	// 친구도 아니고 같은 방에도 없는 사용자가 하나라도 있으면 거부함
+	_, err = presenceService.GetPresence(ctx, viewerID.String(), []string{friendID.String(), strangerID.String()})
+	assert.ErrorIs(t, err, service.ErrPresenceNotVisible)
+
+	// 본인만 조회하면 친구나 방을 확인하지 않음
+	_, err = presenceService.GetPresence(ctx, strangerID.String(), []string{strangerID.String()})
+	assert.NoError(t, err)
+	friendRepo.AssertNotCalled(t, "GetAddedFriendIDs", mock.Anything, strangerID, mock.Anything)
+}
+
+func TestPresenceChangesFanOutToFriends(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	userID, watcherID := uuid.New(), uuid.New()
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	friendRepo := new(FriendRepositoryMock)
+	friendRepo.On("GetAddedByUserIDs", mock.Anything, userID).Return([]uuid.UUID{watcherID}, nil)
+	friendRepo.On("GetAddedByUserIDs", mock.Anything, mock.Anything).Return([]uuid.UUID{}, nil)
+
+	presenceRepo := redisRepo.NewRedisPresenceRepository(client)
+	msgRepo := redisRepo.NewRedisMessageRepository(client)
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.MentionRepo = redisRepo.NewRedisMentionRepository(client)
+	deps.SummaryRepo = redisRepo.NewRedisRoomSummaryRepository(client)
+	deps.PresenceRepo = presenceRepo
+	deps.FriendRepo = friendRepo
+	chatService := service.NewChatService(deps, service.DefaultChatConfig())
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	// 친구는 채팅방을 구독하지 않아도 presence 이벤트를 받음
+	watcher := dialChat(t, wsURL, "", watcherID.String())
+	defer watcher.Close()
+
+	user := dialChat(t, wsURL, "", userID.String())
+	event := readFrame(t, watcher, "presence")
+	assert.Equal(t, userID.String(), event["userId"])
+	assert.Equal(t, "online", event["status"])
+	assert.NotEmpty(t, event["lastSeen"])
+
+	assert.NoError(t, user.WriteJSON(map[string]string{"type": "presence", "status": "away"}))
+	assert.Equal(t, "away", readFrame(t, watcher, "presence")["status"])
+
+	// 마지막 접속 시각을 숨기면 이벤트에도 담기지 않음
+	assert.NoError(t, presenceRepo.SetHideLastSeen(context.Background(), userID.String(), true))
+	user.Close()
+	event = readFrame(t, watcher, "presence")
+	assert.Equal(t, "offline", event["status"])
+	assert.NotContains(t, event, "lastSeen")
+}
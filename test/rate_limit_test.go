@@ -0,0 +1,180 @@
+package test
+
+import (
+	"context"
+	"server/internal/models/ratelimit"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedisRateLimitRepository(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisRateLimitRepository(client)
+	userID, otherID, roomID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	now := time.UnixMilli(1_700_000_000_000).UTC()
+	userLimit := ratelimit.Limit{Burst: 2, Rate: 1}
+	roomLimit := ratelimit.Limit{Burst: 3, Rate: 10}
+
+	take := func(userID string, at time.Time) ratelimit.Result {
+		result, err := repo.TakeMessageToken(ctx, userID, roomID, userLimit, roomLimit, at)
+		assert.NoError(t, err)
+		return result
+	}
+
+	// 버킷 크기만큼 한꺼번에 보낸 뒤에는 초당 채워지는 만큼만 보낼 수 있음
+	assert.True(t, take(userID, now).Allowed)
+	assert.True(t, take(userID, now).Allowed)
+	assert.Equal(t, ratelimit.Result{Reason: ratelimit.ReasonUser, RetryAfter: time.Second}, take(userID, now))
+	assert.Equal(t, ratelimit.Result{Reason: ratelimit.ReasonUser, RetryAfter: 400 * time.Millisecond}, take(userID, now.Add(600*time.Millisecond)))
+
+	// 사용자 버킷에서 제한된 요청은 채팅방 버킷의 토큰을 쓰지 않음
+	assert.True(t, take(otherID, now).Allowed)
+	assert.Equal(t, ratelimit.Result{Reason: ratelimit.ReasonRoom, RetryAfter: 100 * time.Millisecond}, take(otherID, now))
+	assert.True(t, take(userID, now.Add(time.Second)).Allowed)
+
+	// 제한을 strikes번 넘기면 차단되고, 차단이 끝나면 다시 보낼 수 있음
+	banned, err := repo.RecordViolation(ctx, userID, now, time.Minute, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, banned)
+	banned, err = repo.RecordViolation(ctx, userID, now.Add(time.Second), time.Minute, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, banned)
+
+	until, err := repo.BannedUntil(ctx, userID, now.Add(time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute+time.Second), until)
+	assert.Equal(t, ratelimit.Result{Reason: ratelimit.ReasonBanned, RetryAfter: 30 * time.Second}, take(userID, now.Add(31*time.Second)))
+
+	until, err = repo.BannedUntil(ctx, userID, now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.True(t, until.IsZero())
+	assert.True(t, take(userID, now.Add(2*time.Minute)).Allowed)
+
+	// 기간이 지난 횟수는 초기화됨
+	banned, err = repo.RecordViolation(ctx, otherID, now, time.Minute, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, banned)
+	banned, err = repo.RecordViolation(ctx, otherID, now.Add(time.Minute), time.Minute, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, banned)
+}
+
+func TestFloodingUserIsRateLimitedAndDisconnected(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	fakeClock := clock.NewFake(time.Now())
+	config := service.DefaultChatConfig()
+	config.UserMessageLimit = ratelimit.Limit{Burst: 2, Rate: 1}
+	config.RateLimitStrikes = 2
+	config.RateLimitBan = time.Minute
+	config.Clock = fakeClock
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.LimitRepo = redisRepo.NewRedisRateLimitRepository(client)
+	chatService := service.NewChatService(deps, config)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID, userID := uuid.NewString(), uuid.NewString()
+	conn := dialChat(t, wsURL, roomID, userID)
+	defer conn.Close()
+
+	sendClientMessage(t, conn, roomID, "c-1", "one")
+	sendClientMessage(t, conn, roomID, "c-2", "two")
+	readFrame(t, conn, "ack")
+	readFrame(t, conn, "ack")
+
+	sendClientMessage(t, conn, roomID, "c-3", "three")
+	limited := readFrame(t, conn, "error")
+	assert.Equal(t, "rate_limited", limited["code"])
+	assert.Equal(t, "c-3", limited["clientMessageId"])
+	assert.Equal(t, float64(1000), limited["retryAfterMs"])
+
+	// 두 번째로 제한되면 오류 대신 연결이 끊김
+	sendClientMessage(t, conn, roomID, "c-4", "four")
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var err error
+	for err == nil {
+		_, _, err = conn.ReadMessage()
+	}
+	assert.True(t, websocket.IsCloseError(err, 4029), "예상하지 못한 오류: %v", err)
+
+	// 차단 기간에는 다시 접속할 수 없음
+	rejected := dialChat(t, wsURL, roomID, userID)
+	defer rejected.Close()
+	rejected.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = rejected.ReadMessage()
+	assert.Error(t, err)
+
+	fakeClock.Advance(time.Minute)
+	conn = dialChat(t, wsURL, roomID, userID)
+	defer conn.Close()
+	sendClientMessage(t, conn, roomID, "c-5", "five")
+	assert.Equal(t, "c-5", readFrame(t, conn, "ack")["clientMessageId"])
+}
+
+func TestRetriedMessagesAreNotRateLimited(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	fakeClock := clock.NewFake(time.Now())
+	config := service.DefaultChatConfig()
+	config.UserMessageLimit = ratelimit.Limit{Burst: 2, Rate: 1}
+	config.Clock = fakeClock
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.LimitRepo = redisRepo.NewRedisRateLimitRepository(client)
+	chatService := service.NewChatService(deps, config)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID, userID := uuid.NewString(), uuid.NewString()
+	conn := dialChat(t, wsURL, roomID, userID)
+	defer conn.Close()
+
+	sendClientMessage(t, conn, roomID, "c-1", "one")
+	first := readFrame(t, conn, "ack")
+
+	// 재전송은 토큰을 쓰지 않으므로 버킷 크기보다 많이 보내도 제한이나 차단 없이 처음 ack를 받음
+	for i := 0; i < 3; i++ {
+		sendClientMessage(t, conn, roomID, "c-1", "one")
+		assert.Equal(t, first["messageId"], readFrame(t, conn, "ack")["messageId"])
+	}
+	sendClientMessage(t, conn, roomID, "c-2", "two")
+	assert.Equal(t, "c-2", readFrame(t, conn, "ack")["clientMessageId"])
+
+	// 제한된 메시지는 선점하지 않은 것으로 처리되어 같은 ID로 다시 보낼 수 있음
+	sendClientMessage(t, conn, roomID, "c-3", "three")
+	assert.Equal(t, "rate_limited", readFrame(t, conn, "error")["code"])
+	fakeClock.Advance(time.Second)
+	sendClientMessage(t, conn, roomID, "c-3", "three")
+	assert.Equal(t, "c-3", readFrame(t, conn, "ack")["clientMessageId"])
+	msgRepo.AssertNumberOfCalls(t, "SaveMessage", 3)
+}
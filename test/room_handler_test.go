@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"server/internal/models/orm"
+	"server/internal/service"
 	"testing"
 
 	"github.com/google/uuid"
@@ -35,6 +36,11 @@ func (m *RoomServiceMock) GetUserRooms(ctx context.Context, userID uuid.UUID) ([
 	return args.Get(0).([]orm.Room), args.Error(1)
 }
 
+func (m *RoomServiceMock) ListRooms(ctx context.Context, userID uuid.UUID, query service.RoomListQuery) (service.RoomListPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(service.RoomListPage), args.Error(1)
+}
+
 func (m *RoomServiceMock) AddUserToRoom(ctx context.Context, roomID, userID uuid.UUID) error {
 	args := m.Called(ctx, roomID, userID)
 	return args.Error(0)
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"context"
+	"server/internal/broadcast"
+	"server/internal/models/message"
+	"server/internal/models/orm"
+	"server/internal/repository"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func summaryMessage(roomID string, id uuid.UUID, content string) *message.TextMessage {
+	return &message.TextMessage{
+		BaseMessage: message.BaseMessage{Id: id, RoomId: roomID, Type: "message", Author: message.User{Id: uuid.NewString()}},
+		Content:     content,
+	}
+}
+
+func TestRedisRoomSummaryRepository(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisRoomSummaryRepository(client)
+	roomA, roomB := uuid.NewString(), uuid.NewString()
+	userID, otherID := uuid.NewString(), uuid.NewString()
+
+	ids := []uuid.UUID{uuidAt(1000), uuidAt(1001), uuidAt(1002)}
+	for i, id := range ids {
+		assert.NoError(t, repo.RecordMessage(ctx, roomA, summaryMessage(roomA, id, []string{"one", "two", "three"}[i])))
+	}
+	// 늦게 도착한 이전 메시지는 마지막 메시지를 바꾸지 않음
+	late := uuidAt(999)
+	assert.NoError(t, repo.RecordMessage(ctx, roomA, summaryMessage(roomA, late, "late")))
+
+	summaries, err := repo.GetSummaries(ctx, userID, map[string]uuid.UUID{roomA: ids[0], roomB: uuid.Nil})
+	assert.NoError(t, err)
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, ids[2], summaries[roomA].LastMessage.Id)
+	assert.Equal(t, "three", summaries[roomA].LastMessage.Preview)
+	assert.Equal(t, int64(1002), summaries[roomA].LastActivity.UnixMilli())
+	assert.Equal(t, 2, summaries[roomA].UnreadCount)
+
+	// 마지막 메시지가 수정되었을 때만 요약이 바뀜
+	assert.NoError(t, repo.RefreshLastMessage(ctx, roomA, summaryMessage(roomA, ids[1], "two (edited)")))
+	assert.NoError(t, repo.RefreshLastMessage(ctx, roomA, summaryMessage(roomA, ids[2], "three (edited)")))
+
+	summaries, err = repo.GetSummaries(ctx, userID, map[string]uuid.UUID{roomA: uuid.Nil})
+	assert.NoError(t, err)
+	assert.Equal(t, "three (edited)", summaries[roomA].LastMessage.Preview)
+	assert.Equal(t, 4, summaries[roomA].UnreadCount)
+
+	// 삭제된 메시지는 모두에게서, 숨긴 메시지는 숨긴 사용자에게서만 빠짐
+	assert.NoError(t, repo.RemoveMessage(ctx, roomA, ids[1]))
+	assert.NoError(t, repo.HideMessage(ctx, roomA, userID, ids[2]))
+	// 이미 삭제된 메시지를 숨겨도 두 번 빼지 않고, 읽음 커서 이전에 숨긴 메시지는 세지 않음
+	assert.NoError(t, repo.HideMessage(ctx, roomA, userID, ids[1]))
+	assert.NoError(t, repo.HideMessage(ctx, roomA, userID, late))
+
+	summaries, err = repo.GetSummaries(ctx, userID, map[string]uuid.UUID{roomA: ids[0]})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summaries[roomA].UnreadCount)
+	summaries, err = repo.GetSummaries(ctx, userID, map[string]uuid.UUID{roomA: uuid.Nil})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summaries[roomA].UnreadCount)
+	summaries, err = repo.GetSummaries(ctx, otherID, map[string]uuid.UUID{roomA: uuid.Nil})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, summaries[roomA].UnreadCount)
+
+	assert.ErrorIs(t, repo.RecordMessage(ctx, roomA, summaryMessage(roomA, uuid.New(), "v4")), repository.ErrInvalidMessageID)
+}
+
+func TestListRoomsSortsAndPagesByActivity(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	summaryRepo := redisRepo.NewRedisRoomSummaryRepository(client)
+	userID := uuid.New()
+	createdAt := time.UnixMilli(1000).UTC()
+
+	room := func(name string, createdAt time.Time) orm.Room {
+		return orm.Room{UUIDv7BaseModel: orm.UUIDv7BaseModel{ID: uuid.New()}, RoomName: name, CreatedAt: createdAt}
+	}
+	quiet := room("quiet", createdAt.Add(2*time.Second))
+	busy := room("busy", createdAt)
+	read := room("read", createdAt)
+
+	// busy는 두 메시지 중 첫 번째까지, read는 마지막 메시지까지 읽음
+	busyIDs := []uuid.UUID{uuidAt(2000), uuidAt(5000)}
+	for _, id := range busyIDs {
+		assert.NoError(t, summaryRepo.RecordMessage(ctx, busy.ID.String(), summaryMessage(busy.ID.String(), id, "busy")))
+	}
+	readID := uuidAt(4000)
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, read.ID.String(), summaryMessage(read.ID.String(), readID, "read")))
+
+	quietRoom := orm.UserRoom{Room: quiet, MemberCount: 2}
+	busyRoom := orm.UserRoom{Room: busy, LastReadMessageID: &busyIDs[0], MemberCount: 3}
+	readRoom := orm.UserRoom{Room: read, LastReadMessageID: &readID, MemberCount: 4}
+
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("GetUserRoomMemberships", mock.Anything, userID).Return([]orm.UserRoom{quietRoom, busyRoom, readRoom}, nil).Once()
+	// 방 정보는 페이지에 든 방만 조회함
+	roomRepo.On("GetUserRoomMembershipsByID", mock.Anything, userID, []uuid.UUID{busy.ID, read.ID}).Return([]orm.UserRoom{busyRoom, readRoom}, nil)
+	roomRepo.On("GetUserRoomMembershipsByID", mock.Anything, userID, []uuid.UUID{quiet.ID}).Return([]orm.UserRoom{quietRoom}, nil)
+	roomService := service.NewRoomService(roomRepo, summaryRepo, broadcast.NewLocalBus())
+
+	page, err := roomService.ListRooms(ctx, userID, service.RoomListQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Rooms, 2)
+	assert.Equal(t, busy.ID, page.Rooms[0].ID)
+	assert.Equal(t, busyIDs[1], page.Rooms[0].LastMessage.Id)
+	assert.Equal(t, 1, page.Rooms[0].UnreadCount)
+	assert.Equal(t, 3, page.Rooms[0].MemberCount)
+	assert.Equal(t, read.ID, page.Rooms[1].ID)
+	assert.Equal(t, 0, page.Rooms[1].UnreadCount)
+
+	// 메시지가 없는 방은 만든 시각을 활동 시각으로 사용
+	page, err = roomService.ListRooms(ctx, userID, service.RoomListQuery{Cursor: page.NextCursor, Limit: 2})
+	assert.NoError(t, err)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.Len(t, page.Rooms, 1)
+	assert.Equal(t, quiet.ID, page.Rooms[0].ID)
+	assert.Nil(t, page.Rooms[0].LastMessage)
+	assert.Equal(t, quiet.CreatedAt, page.Rooms[0].LastActivityAt)
+
+	_, err = roomService.ListRooms(ctx, userID, service.RoomListQuery{Cursor: "not-a-cursor"})
+	assert.ErrorIs(t, err, service.ErrInvalidRoomCursor)
+
+	// 목록은 처음 조회할 때 한 번만 만듦
+	roomRepo.AssertNumberOfCalls(t, "GetUserRoomMemberships", 1)
+}
+
+func TestRoomListFollowsMessagesAndMembership(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	summaryRepo := redisRepo.NewRedisRoomSummaryRepository(client)
+	userID := uuid.New()
+	createdAt := time.UnixMilli(1000).UTC()
+
+	rooms := make([]orm.UserRoom, 4)
+	for i := range rooms {
+		rooms[i] = orm.UserRoom{Room: orm.Room{UUIDv7BaseModel: orm.UUIDv7BaseModel{ID: uuid.New()}, CreatedAt: createdAt}}
+	}
+	first, second, added, newcomer := rooms[0], rooms[1], rooms[2], rooms[3]
+
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("GetUserRoomMemberships", mock.Anything, userID).Return([]orm.UserRoom{first, second}, nil).Once()
+	roomRepo.On("GetUserRoomMembershipsByID", mock.Anything, userID, mock.Anything).Return(rooms[:3], nil)
+	roomRepo.On("FindByID", mock.Anything, added.ID).Return(added.Room, nil)
+	roomRepo.On("AddUserToRoom", mock.Anything, added.ID, userID).Return(nil)
+	roomRepo.On("FindByID", mock.Anything, second.ID).Return(second.Room, nil)
+	roomRepo.On("RemoveUserFromRoom", mock.Anything, second.ID, userID).Return(nil)
+	roomService := service.NewRoomService(roomRepo, summaryRepo, broadcast.NewLocalBus())
+
+	listedIDs := func() []uuid.UUID {
+		page, err := roomService.ListRooms(ctx, userID, service.RoomListQuery{})
+		assert.NoError(t, err)
+		ids := make([]uuid.UUID, len(page.Rooms))
+		for i, room := range page.Rooms {
+			ids[i] = room.ID
+		}
+		return ids
+	}
+
+	// 목록이 만들어지기 전에 추가된 방은 목록을 만들 때 Postgres에서 읽음
+	assert.NoError(t, summaryRepo.AddMember(ctx, newcomer.ID.String(), userID.String(), createdAt))
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, first.ID.String(), summaryMessage(first.ID.String(), uuidAt(2000), "first")))
+	assert.Equal(t, []uuid.UUID{first.ID, second.ID}, listedIDs())
+
+	// 새 메시지는 목록을 다시 만들지 않고 순서에 반영됨
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, second.ID.String(), summaryMessage(second.ID.String(), uuidAt(3000), "second")))
+	assert.Equal(t, []uuid.UUID{second.ID, first.ID}, listedIDs())
+	// 늦게 도착한 이전 메시지는 활동 시각을 되돌리지 않음
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, second.ID.String(), summaryMessage(second.ID.String(), uuidAt(1500), "late")))
+	assert.Equal(t, []uuid.UUID{second.ID, first.ID}, listedIDs())
+
+	// 들어온 방은 바로 목록에 보이고, 나간 방은 빠짐
+	assert.NoError(t, roomService.AddUserToRoom(ctx, added.ID, userID))
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, added.ID.String(), summaryMessage(added.ID.String(), uuidAt(4000), "added")))
+	assert.Equal(t, []uuid.UUID{added.ID, second.ID, first.ID}, listedIDs())
+
+	assert.NoError(t, roomService.RemoveUserFromRoom(ctx, second.ID, userID))
+	assert.NoError(t, summaryRepo.RecordMessage(ctx, second.ID.String(), summaryMessage(second.ID.String(), uuidAt(5000), "after leaving")))
+	assert.Equal(t, []uuid.UUID{added.ID, first.ID}, listedIDs())
+
+	roomRepo.AssertNumberOfCalls(t, "GetUserRoomMemberships", 1)
+}
+
+func TestRedisRoomListPagesThroughTies(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisRoomSummaryRepository(client)
+	userID := uuid.NewString()
+
+	_, err := repo.ListRooms(ctx, userID, nil, 10)
+	assert.ErrorIs(t, err, repository.ErrRoomListNotFound)
+
+	// 활동 시각이 모두 같으면 방 ID의 역순
+	createdAt := time.UnixMilli(1000).UTC()
+	rooms := make(map[string]time.Time)
+	var expected []string
+	for i := 0; i < 5; i++ {
+		roomID := uuid.NewString()
+		rooms[roomID] = createdAt
+		expected = append(expected, roomID)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(expected)))
+	assert.NoError(t, repo.BuildRoomList(ctx, userID, rooms))
+
+	var listed []string
+	var after *message.RoomActivity
+	for {
+		page, err := repo.ListRooms(ctx, userID, after, 2)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, room := range page {
+			assert.Equal(t, createdAt, room.LastActivity)
+			listed = append(listed, room.RoomID)
+		}
+		after = &page[len(page)-1]
+	}
+	assert.Equal(t, expected, listed)
+}
+
+func TestListRoomsWithoutRooms(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	userID := uuid.New()
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("GetUserRoomMemberships", mock.Anything, userID).Return([]orm.UserRoom{}, nil)
+	roomService := service.NewRoomService(roomRepo, redisRepo.NewRedisRoomSummaryRepository(client), broadcast.NewLocalBus())
+
+	page, err := roomService.ListRooms(context.Background(), userID, service.RoomListQuery{})
+	assert.NoError(t, err)
+	assert.NotNil(t, page.Rooms)
+	assert.Empty(t, page.Rooms)
+	assert.False(t, page.HasMore)
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	redisRepo "server/internal/repository/redis"
+	"server/internal/service"
+	"server/pkg/clock"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRedisTypingRepository(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+
+	ctx := context.Background()
+	repo := redisRepo.NewRedisTypingRepository(client)
+	roomID, userA, userB := uuid.NewString(), "a-"+uuid.NewString(), "b-"+uuid.NewString()
+	now := time.UnixMilli(1_700_000_000_000).UTC()
+	ttl := 6 * time.Second
+
+	assertTyping := func(wantTyping []string, wantChanged bool) func([]string, bool, error) {
+		return func(typing []string, changed bool, err error) {
+			assert.NoError(t, err)
+			assert.Equal(t, wantTyping, typing)
+			assert.Equal(t, wantChanged, changed)
+		}
+	}
+
+	// 이미 타이핑 중인 사용자의 갱신은 변경이 아님
+	assertTyping([]string{userA}, true)(repo.StartTyping(ctx, roomID, userA, now, now.Add(ttl)))
+	assertTyping([]string{userA}, false)(repo.StartTyping(ctx, roomID, userA, now, now.Add(ttl)))
+	assertTyping([]string{userA, userB}, true)(repo.StartTyping(ctx, roomID, userB, now, now.Add(ttl+time.Second)))
+
+	assertTyping([]string{userB}, true)(repo.StopTyping(ctx, roomID, userA, now))
+	assertTyping([]string{userB}, false)(repo.StopTyping(ctx, roomID, userA, now))
+
+	// 만료 시각이 지나지 않은 상태는 지우지 않음
+	assertTyping([]string{userB}, false)(repo.ExpireTyping(ctx, roomID, userB, now.Add(ttl)))
+	assertTyping([]string{}, true)(repo.ExpireTyping(ctx, roomID, userB, now.Add(ttl+time.Second)))
+	assertTyping([]string{}, false)(repo.ExpireTyping(ctx, roomID, userB, now.Add(ttl+time.Second)))
+}
+
+func sendTyping(t *testing.T, conn *websocket.Conn, roomID string, isTyping bool) {
+	err := conn.WriteJSON(map[string]interface{}{"type": "typing", "roomId": roomID, "isTyping": isTyping})
+	assert.NoError(t, err)
+}
+
+func TestTypingIsThrottledExpiredAndClearedByMessages(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	msgRepo := new(MessageRepositoryMock)
+	msgRepo.On("SaveMessage", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	roomRepo := new(RoomRepositoryMock)
+	roomRepo.On("IsUserInRoom", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+
+	fakeClock := clock.NewFake(time.Now())
+	config := service.DefaultChatConfig()
+	config.Clock = fakeClock
+	deps := chatDeps(msgRepo, roomRepo)
+	deps.DedupRepo = redisRepo.NewRedisMessageDedupRepository(client)
+	deps.TypingRepo = redisRepo.NewRedisTypingRepository(client)
+	chatService := service.NewChatService(deps, config)
+	server, wsURL := newChatServiceServer(chatService)
+	defer server.Close()
+
+	roomID, userA, userB := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	connA := dialChat(t, wsURL, roomID, userA)
+	defer connA.Close()
+	connB := dialChat(t, wsURL, roomID, userB)
+	defer connB.Close()
+	readFrame(t, connA, "userJoined")
+
+	sendTyping(t, connA, roomID, true)
+	event := readFrame(t, connB, "typing")
+	assert.Equal(t, userA, event["userId"])
+	assert.Equal(t, true, event["isTyping"])
+	assert.Equal(t, []interface{}{userA}, event["userIds"])
+
+	// 제한 시간 안에 다시 보낸 시작 프레임은 무시되므로 A의 만료 시각은 늘어나지 않음
+	fakeClock.Advance(time.Second)
+	sendTyping(t, connA, roomID, true)
+	sendTyping(t, connB, roomID, true)
+	event = readFrame(t, connA, "typing")
+	assert.Equal(t, userB, event["userId"])
+	assert.ElementsMatch(t, []interface{}{userA, userB}, event["userIds"])
+
+	// 처음 시작 프레임으로부터 TypingTTL이 지나면 A만 만료됨
+	fakeClock.Advance(config.TypingTTL - time.Second)
+	event = readFrame(t, connB, "typing")
+	assert.Equal(t, userA, event["userId"])
+	assert.Equal(t, false, event["isTyping"])
+	assert.Equal(t, []interface{}{userB}, event["userIds"])
+
+	// 메시지를 보내면 타이핑 상태가 지워짐
+	sendClientMessage(t, connB, roomID, "c-1", "hello")
+	event = readFrame(t, connA, "typing")
+	assert.Equal(t, userB, event["userId"])
+	assert.Equal(t, false, event["isTyping"])
+	assert.Equal(t, []interface{}{}, event["userIds"])
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"server/internal/handler/chatting"
+	"server/internal/service"
+	"server/pkg/tutils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newChatHandlerServer(chatService *ChatServiceMock) (*httptest.Server, string) {
+	handler := chatting.NewChatHandler(chatService)
+	server := httptest.NewServer(http.HandlerFunc(handler.HandleWebSocket))
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	return server, wsURL
+}
+
+func expectConnection(chatService *ChatServiceMock, roomID, userID string) chan struct{} {
+	connected := make(chan struct{})
+	chatService.On("HandleWebSocketConnection", mock.Anything, roomID, userID, uuid.Nil, mock.Anything).
+		Run(func(args mock.Arguments) { close(connected) }).
+		Return(nil)
+	return connected
+}
+
+func waitConnected(t *testing.T, connected chan struct{}) {
+	select {
+	case <-connected:
+	case <-time.After(2 * time.Second):
+		t.Fatal("HandleWebSocketConnection이 호출되지 않음")
+	}
+}
+
+func TestWebSocketHandshakeWithQueryToken(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	userID := uuid.New()
+	connected := expectConnection(chatService, "room-123", userID.String())
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+tutils.CreateToken(userID), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	// 클라이언트가 보낸 userId는 무시되고 토큰의 user_id가 사용되어야 함
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123", "userId": "someone-else"})
+	assert.NoError(t, err)
+
+	waitConnected(t, connected)
+	chatService.AssertExpectations(t)
+}
+
+func TestWebSocketHandshakeWithSubprotocolToken(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	userID := uuid.New()
+	connected := expectConnection(chatService, "room-123", userID.String())
+
+	dialer := websocket.Dialer{Subprotocols: []string{"bearer", tutils.CreateToken(userID)}}
+	conn, resp, err := dialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, "bearer", resp.Header.Get("Sec-WebSocket-Protocol"))
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123"})
+	assert.NoError(t, err)
+
+	waitConnected(t, connected)
+	chatService.AssertExpectations(t)
+}
+
+func TestWebSocketHandshakeWithAuthFrame(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	userID := uuid.New()
+	connected := expectConnection(chatService, "room-123", userID.String())
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123", "token": tutils.CreateToken(userID)})
+	assert.NoError(t, err)
+
+	waitConnected(t, connected)
+	chatService.AssertExpectations(t)
+}
+
+func readCloseCode(t *testing.T, conn *websocket.Conn) int {
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err := conn.ReadMessage()
+	closeErr, ok := err.(*websocket.CloseError)
+	if !assert.True(t, ok, "close 프레임을 기대했지만 %v 수신", err) {
+		return 0
+	}
+	return closeErr.Code
+}
+
+func TestWebSocketHandshakeRejectsInvalidToken(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token=not-a-token", nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123", "userId": uuid.NewString()})
+	assert.NoError(t, err)
+
+	assert.Equal(t, chatting.CloseInvalidToken, readCloseCode(t, conn))
+	chatService.AssertNotCalled(t, "HandleWebSocketConnection", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestWebSocketHandshakeRejectsMissingToken(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, chatting.CloseInvalidToken, readCloseCode(t, conn))
+}
+
+func TestWebSocketHandshakeRejectsExpiredToken(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	expiredToken := tutils.CreateExpiredToken(uuid.New())
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+expiredToken, nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, chatting.CloseTokenExpired, readCloseCode(t, conn))
+}
+
+func TestWebSocketHandshakeRejectsNonMember(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	userID := uuid.New()
+	chatService.On("HandleWebSocketConnection", mock.Anything, "room-123", userID.String(), uuid.Nil, mock.Anything).
+		Return(&service.NotRoomMemberError{RoomID: "room-123", UserID: userID.String()})
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+tutils.CreateToken(userID), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, chatting.CloseNotRoomMember, readCloseCode(t, conn))
+}
+
+func TestWebSocketHandshakePassesLastMessageID(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	userID := uuid.New()
+	lastMessageID, _ := uuid.NewV7()
+	connected := make(chan struct{})
+	chatService.On("HandleWebSocketConnection", mock.Anything, "room-123", userID.String(), lastMessageID, mock.Anything).
+		Run(func(args mock.Arguments) { close(connected) }).
+		Return(nil)
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+tutils.CreateToken(userID), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123", "lastMessageId": lastMessageID.String()})
+	assert.NoError(t, err)
+
+	waitConnected(t, connected)
+}
+
+func TestWebSocketHandshakeRejectsInvalidLastMessageID(t *testing.T) {
+	chatService := new(ChatServiceMock)
+	server, wsURL := newChatHandlerServer(chatService)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+tutils.CreateToken(uuid.New()), nil)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	err = conn.WriteJSON(map[string]string{"roomId": "room-123", "lastMessageId": "not-a-uuid"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, websocket.CloseUnsupportedData, readCloseCode(t, conn))
+	chatService.AssertNotCalled(t, "HandleWebSocketConnection", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
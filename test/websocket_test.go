@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"server/internal/models/message"
+	"server/internal/service"
 	"strings"
 	"testing"
 	"time"
@@ -21,23 +22,79 @@ type WebSocketChatServiceMock struct {
 	mock.Mock
 }
 
+// 이 파일의 테스트는 모의 객체를 직접 쓰지 않으므로 인터페이스가 바뀌면 컴파일 단계에서 알 수 있게 함
+var _ service.ChatService = (*WebSocketChatServiceMock)(nil)
+
 func (m *WebSocketChatServiceMock) SaveMessage(ctx context.Context, roomID string, msg message.Message) error {
 	args := m.Called(ctx, roomID, msg)
 	return args.Error(0)
 }
 
-func (m *WebSocketChatServiceMock) GetMessages(ctx context.Context, roomID string, lastMessageID int64) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *WebSocketChatServiceMock) GetMessages(ctx context.Context, roomID, userID string, lastMessageID int64) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageID)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) GetMessagesByUUID(ctx context.Context, roomID, userID string, lastMessageUUID uuid.UUID) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, lastMessageUUID)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) ListMessages(ctx context.Context, roomID, userID string, query message.PageQuery) (message.Page, error) {
+	args := m.Called(ctx, roomID, userID, query)
+	return args.Get(0).(message.Page), args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) HandleWebSocketConnection(ctx context.Context, roomID, userID string, lastMessageID uuid.UUID, conn interface{}) error {
+	args := m.Called(ctx, roomID, userID, lastMessageID, conn)
+	return args.Error(0)
+}
+
+func (m *WebSocketChatServiceMock) EditMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID, content string, mentions []message.Mention) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID, content, mentions)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) GetMessageRevisions(ctx context.Context, roomID, userID string, messageID uuid.UUID) ([]message.Revision, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Get(0).([]message.Revision), args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) DeleteMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) (message.Message, error) {
+	args := m.Called(ctx, roomID, userID, messageID)
+	msg, _ := args.Get(0).(message.Message)
+	return msg, args.Error(1)
+}
+
+func (m *WebSocketChatServiceMock) HideMessage(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *WebSocketChatServiceMock) GetThread(ctx context.Context, roomID, userID string, messageID uuid.UUID, query message.PageQuery) (message.Message, message.Page, error) {
+	args := m.Called(ctx, roomID, userID, messageID, query)
+	root, _ := args.Get(0).(message.Message)
+	return root, args.Get(1).(message.Page), args.Error(2)
+}
+
+func (m *WebSocketChatServiceMock) JoinThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
+}
+
+func (m *WebSocketChatServiceMock) LeaveThread(ctx context.Context, roomID, userID string, messageID uuid.UUID) error {
+	args := m.Called(ctx, roomID, userID, messageID)
+	return args.Error(0)
 }
 
-func (m *WebSocketChatServiceMock) GetMessagesByUUID(ctx context.Context, roomID string, lastMessageUUID uuid.UUID) ([]message.Message, error) {
-	args := m.Called(ctx, roomID, lastMessageUUID)
-	return args.Get(0).([]message.Message), args.Error(1)
+func (m *WebSocketChatServiceMock) ListMentions(ctx context.Context, userID string, query message.MentionQuery) (message.MentionPage, error) {
+	args := m.Called(ctx, userID, query)
+	return args.Get(0).(message.MentionPage), args.Error(1)
 }
 
-func (m *WebSocketChatServiceMock) HandleWebSocketConnection(ctx context.Context, roomID, userID string, conn interface{}) error {
-	args := m.Called(ctx, roomID, userID, conn)
+func (m *WebSocketChatServiceMock) ReadMentions(ctx context.Context, roomID, userID string) error {
+	args := m.Called(ctx, roomID, userID)
 	return args.Error(0)
 }
 